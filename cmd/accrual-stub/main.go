@@ -0,0 +1,32 @@
+package main
+
+import (
+	stub "github.com/Aleksei-D/go-loyalty-system/internal/accrual_stub"
+	"github.com/Aleksei-D/go-loyalty-system/internal/logger"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+func main() {
+	err := logger.Initialize("INFO")
+	if err != nil {
+		logger.Log.Fatal("cannot initialize zap", zap.Error(err))
+	}
+
+	configStub, err := stub.NewConfig()
+	if err != nil {
+		logger.Log.Fatal("cannot initialize config", zap.Error(err))
+	}
+
+	rules, err := stub.LoadRules(*configStub.RulesFile)
+	if err != nil {
+		logger.Log.Fatal("cannot load accrual rules", zap.Error(err))
+	}
+
+	accrualStub := stub.NewAccrualStub(rules, *configStub.RateLimit, *configStub.RetryAfter)
+	logger.Log.Info("accrual stub started", zap.String("address", *configStub.ServerAddr))
+	err = http.ListenAndServe(*configStub.ServerAddr, accrualStub.Router())
+	if err != nil {
+		logger.Log.Fatal("cannot start accrual stub", zap.Error(err))
+	}
+}
@@ -0,0 +1,52 @@
+package stub
+
+import (
+	"flag"
+	"github.com/caarlos0/env/v6"
+	"os"
+)
+
+const (
+	defaultServerAddr = "localhost:4444"
+	rulesFileDefault  = ""
+	rateLimitDefault  = 0
+	retryAfterDefault = 60
+)
+
+type Config struct {
+	ServerAddr *string `env:"RUN_ADDRESS"`
+	RulesFile  *string `env:"RULES_FILE"`
+	RateLimit  *uint   `env:"RATE_LIMIT"`
+	RetryAfter *uint   `env:"RETRY_AFTER"`
+}
+
+func NewConfig() (*Config, error) {
+	var newConfig Config
+	err := env.Parse(&newConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	stubFlagSet := flag.NewFlagSet("AccrualStub", flag.ExitOnError)
+	serverAddr := stubFlagSet.String("a", defaultServerAddr, "input endpoint")
+	rulesFile := stubFlagSet.String("f", rulesFileDefault, "accrual rules file")
+	rateLimit := stubFlagSet.Uint("l", rateLimitDefault, "requests per minute, 0 disables the limit")
+	retryAfter := stubFlagSet.Uint("t", retryAfterDefault, "retry after seconds for rate limited requests")
+	err = stubFlagSet.Parse(os.Args[1:])
+	if err != nil {
+		return nil, err
+	}
+	if newConfig.ServerAddr == nil {
+		newConfig.ServerAddr = serverAddr
+	}
+	if newConfig.RulesFile == nil {
+		newConfig.RulesFile = rulesFile
+	}
+	if newConfig.RateLimit == nil {
+		newConfig.RateLimit = rateLimit
+	}
+	if newConfig.RetryAfter == nil {
+		newConfig.RetryAfter = retryAfter
+	}
+	return &newConfig, nil
+}
@@ -0,0 +1,104 @@
+package stub
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/Aleksei-D/go-loyalty-system/internal/models"
+	"os"
+	"strings"
+	"time"
+)
+
+const defaultAccrual models.Money = 500 * 100
+
+type Rule struct {
+	Prefix        string        `json:"prefix"`
+	Status        string        `json:"status"`
+	Accrual       *models.Money `json:"accrual,omitempty"`
+	RegisteredFor uint          `json:"registered_for"`
+	ProcessingFor uint          `json:"processing_for"`
+}
+
+type Rules []Rule
+
+// DefaultRules processes orders starting with 1-7, rejects orders starting with 8
+// and leaves orders starting with 9 or 0 unregistered.
+func DefaultRules() Rules {
+	accrual := defaultAccrual
+	rules := make(Rules, 0, 8)
+	for _, prefix := range []string{"1", "2", "3", "4", "5", "6", "7"} {
+		rules = append(rules, Rule{
+			Prefix:        prefix,
+			Status:        string(models.OrderStatusProcessed),
+			Accrual:       &accrual,
+			RegisteredFor: 1,
+			ProcessingFor: 2,
+		})
+	}
+	return append(rules, Rule{Prefix: "8", Status: string(models.OrderStatusInvalid), RegisteredFor: 1, ProcessingFor: 2})
+}
+
+func LoadRules(path string) (Rules, error) {
+	if path == "" {
+		return DefaultRules(), nil
+	}
+
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var rules Rules
+	if err = json.Unmarshal(buf, &rules); err != nil {
+		return nil, err
+	}
+
+	for _, rule := range rules {
+		if err = rule.validate(); err != nil {
+			return nil, err
+		}
+	}
+	return rules, nil
+}
+
+func (r *Rule) validate() error {
+	switch r.Status {
+	case string(models.OrderStatusProcessed):
+		if r.Accrual == nil {
+			return fmt.Errorf("rule %q: accrual is required for %s", r.Prefix, r.Status)
+		}
+	case string(models.OrderStatusInvalid):
+		if r.Accrual != nil {
+			return fmt.Errorf("rule %q: accrual is not allowed for %s", r.Prefix, r.Status)
+		}
+	default:
+		return fmt.Errorf("rule %q: unsupported final status %q", r.Prefix, r.Status)
+	}
+	return nil
+}
+
+func (r Rules) Match(orderNumber string) *Rule {
+	var matched *Rule
+	for i := range r {
+		if !strings.HasPrefix(orderNumber, r[i].Prefix) {
+			continue
+		}
+		if matched == nil || len(r[i].Prefix) > len(matched.Prefix) {
+			matched = &r[i]
+		}
+	}
+	return matched
+}
+
+func (r *Rule) StatusAfter(elapsed time.Duration) *models.OrderStatusResponse {
+	registeredFor := time.Duration(r.RegisteredFor) * time.Second
+	processingFor := time.Duration(r.ProcessingFor) * time.Second
+	switch {
+	case elapsed < registeredFor:
+		return &models.OrderStatusResponse{Status: models.AccrualStatusRegistered}
+	case elapsed < registeredFor+processingFor:
+		return &models.OrderStatusResponse{Status: string(models.OrderStatusProcessing)}
+	default:
+		return &models.OrderStatusResponse{Status: r.Status, Accrual: r.Accrual}
+	}
+}
@@ -0,0 +1,110 @@
+package stub
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type AccrualStub struct {
+	rules     Rules
+	limiter   *rateLimiter
+	mu        sync.Mutex
+	firstSeen map[string]time.Time
+}
+
+func NewAccrualStub(rules Rules, rateLimit, retryAfter uint) *AccrualStub {
+	return &AccrualStub{
+		rules:     rules,
+		limiter:   newRateLimiter(rateLimit, retryAfter),
+		firstSeen: make(map[string]time.Time),
+	}
+}
+
+func (a *AccrualStub) Router() chi.Router {
+	r := chi.NewRouter()
+	r.Get("/api/orders/{number}", a.APIGetOrderHandler())
+	return r
+}
+
+func (a *AccrualStub) APIGetOrderHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if retryAfter, ok := a.limiter.allow(); !ok {
+			w.Header().Set("Content-Type", "text/plain")
+			w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Seconds())))
+			w.WriteHeader(http.StatusTooManyRequests)
+			fmt.Fprintf(w, "No more than %d requests per minute allowed", a.limiter.limit)
+			return
+		}
+
+		orderNumber := chi.URLParam(r, "number")
+		rule := a.rules.Match(orderNumber)
+		if rule == nil {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		order := rule.StatusAfter(a.elapsed(orderNumber))
+		order.Order = orderNumber
+		orderJSON, err := json.Marshal(order)
+		if err != nil {
+			http.Error(w, "invalid marshaling", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(orderJSON)
+	}
+}
+
+func (a *AccrualStub) elapsed(orderNumber string) time.Duration {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	seenAt, ok := a.firstSeen[orderNumber]
+	if !ok {
+		seenAt = time.Now()
+		a.firstSeen[orderNumber] = seenAt
+	}
+	return time.Since(seenAt)
+}
+
+type rateLimiter struct {
+	mu          sync.Mutex
+	limit       uint
+	retryAfter  time.Duration
+	windowStart time.Time
+	count       uint
+}
+
+func newRateLimiter(limit, retryAfter uint) *rateLimiter {
+	return &rateLimiter{
+		limit:      limit,
+		retryAfter: time.Duration(retryAfter) * time.Second,
+	}
+}
+
+func (l *rateLimiter) allow() (time.Duration, bool) {
+	if l.limit == 0 {
+		return 0, true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(l.windowStart) >= time.Minute {
+		l.windowStart = now
+		l.count = 0
+	}
+	if l.count >= l.limit {
+		return l.retryAfter, false
+	}
+	l.count++
+	return 0, true
+}
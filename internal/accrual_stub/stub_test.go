@@ -0,0 +1,68 @@
+package stub
+
+import (
+	"encoding/json"
+	"github.com/Aleksei-D/go-loyalty-system/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAccrualStub_APIGetOrderHandler(t *testing.T) {
+	accrual := models.Money(12345)
+	rules := Rules{
+		{Prefix: "1", Status: string(models.OrderStatusProcessed), Accrual: &accrual},
+		{Prefix: "12", Status: string(models.OrderStatusInvalid)},
+	}
+	server := httptest.NewServer(NewAccrualStub(rules, 3, 42).Router())
+	defer server.Close()
+
+	tests := []struct {
+		name        string
+		orderNumber string
+		wantCode    int
+		wantStatus  string
+		wantAccrual *models.Money
+	}{
+		{name: "processed", orderNumber: "17893729974", wantCode: http.StatusOK, wantStatus: "PROCESSED", wantAccrual: &accrual},
+		{name: "longest prefix wins", orderNumber: "12345678903", wantCode: http.StatusOK, wantStatus: "INVALID"},
+		{name: "unknown order", orderNumber: "9278923470", wantCode: http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Get(server.URL + "/api/orders/" + tt.orderNumber)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			require.Equal(t, tt.wantCode, resp.StatusCode)
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+
+			var order models.OrderStatusResponse
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&order))
+			assert.Equal(t, tt.orderNumber, order.Order)
+			assert.Equal(t, tt.wantStatus, order.Status)
+			assert.Equal(t, tt.wantAccrual, order.Accrual)
+		})
+	}
+
+	resp, err := http.Get(server.URL + "/api/orders/17893729974")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, "42", resp.Header.Get("Retry-After"))
+	assert.Equal(t, "text/plain", resp.Header.Get("Content-Type"))
+}
+
+func TestDefaultRules(t *testing.T) {
+	rules := DefaultRules()
+
+	assert.Equal(t, string(models.OrderStatusProcessed), rules.Match("12345678903").Status)
+	assert.Equal(t, string(models.OrderStatusInvalid), rules.Match("8000000006").Status)
+	assert.Nil(t, rules.Match("9278923470"))
+	assert.Nil(t, rules.Match("0000000000"))
+}
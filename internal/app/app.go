@@ -4,9 +4,11 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"github.com/Aleksei-D/go-loyalty-system/internal/config"
 	"github.com/Aleksei-D/go-loyalty-system/internal/datasource"
 	"github.com/Aleksei-D/go-loyalty-system/internal/domain/infrastructure"
+	"github.com/Aleksei-D/go-loyalty-system/internal/domain/memory"
 	"github.com/Aleksei-D/go-loyalty-system/internal/logger"
 	agent "github.com/Aleksei-D/go-loyalty-system/internal/orders_agent"
 	"github.com/Aleksei-D/go-loyalty-system/internal/router"
@@ -21,30 +23,63 @@ import (
 )
 
 type App struct {
-	db  *sql.DB
-	cfg *config.Config
+	db      *sql.DB
+	cfg     *config.Config
+	service *service.Service
 }
 
 func NewApp(cfg *config.Config) (*App, error) {
-	db, err := datasource.NewDatabase(*cfg.DatabaseURI)
+	app := &App{cfg: cfg}
+	var repos *service.Repositories
+	switch *cfg.Storage {
+	case config.StorageMemory:
+		storage := memory.NewStorage()
+		repos = &service.Repositories{
+			Balance:       memory.NewBalanceRepository(storage),
+			Order:         memory.NewOrderRepository(storage),
+			User:          memory.NewUserRepository(storage),
+			Withdrawal:    memory.NewWithdrawalRepository(storage),
+			Ledger:        memory.NewLedgerRepository(storage),
+			Token:         memory.NewTokenRepository(storage),
+			PasswordReset: memory.NewPasswordResetRepository(storage),
+			Hold:          memory.NewHoldRepository(storage),
+			Referral:      memory.NewReferralRepository(storage),
+			Outbox:        memory.NewOutboxRepository(storage),
+			Webhook:       memory.NewWebhookRepository(storage),
+		}
+	case config.StoragePostgres:
+		db, err := datasource.NewDatabase(*cfg.DatabaseURI)
+		if err != nil {
+			return nil, err
+		}
+		app.db = db
+		repos = &service.Repositories{
+			Balance:       infrastructure.NewPostgresBalanceRepository(db),
+			Order:         infrastructure.NewPostgresOrderRepository(db),
+			User:          infrastructure.NewPostgresUserRepository(db),
+			Withdrawal:    infrastructure.NewPostgresWithdrawalRepository(db),
+			Ledger:        infrastructure.NewPostgresLedgerRepository(db),
+			Token:         infrastructure.NewPostgresTokenRepository(db),
+			PasswordReset: infrastructure.NewPostgresPasswordResetRepository(db),
+			Hold:          infrastructure.NewPostgresHoldRepository(db),
+			Referral:      infrastructure.NewPostgresReferralRepository(db),
+			Outbox:        infrastructure.NewPostgresOutboxRepository(db),
+			Webhook:       infrastructure.NewPostgresWebhookRepository(db),
+		}
+	default:
+		return nil, fmt.Errorf("unknown storage type: %s", *cfg.Storage)
+	}
+
+	serviceApp, err := service.NewService(repos, cfg)
 	if err != nil {
 		return nil, err
 	}
-	return &App{
-		db:  db,
-		cfg: cfg,
-	}, nil
+	app.service = serviceApp
+	return app, nil
 }
 
 func (app *App) Run() error {
-	serviceApp := service.NewService(
-		infrastructure.NewPostgresBalanceRepository(app.db),
-		infrastructure.NewPostgresOrderRepository(app.db),
-		infrastructure.NewPostgresUserRepository(app.db),
-		infrastructure.NewPostgresWithdrawalRepository(app.db),
-	)
-
-	r := router.NewRouter(serviceApp, *app.cfg.SecretKey)
+	r := router.NewRouter(app.service)
 
 	server := &http.Server{Addr: *app.cfg.ServerAddr, Handler: r}
 	serverCtx, serverStopCtx := context.WithCancel(context.Background())
@@ -72,9 +107,23 @@ func (app *App) Run() error {
 		serverStopCtx()
 	}()
 
-	orderAgent := agent.NewOrdersAgent(service.NewOrderService(infrastructure.NewPostgresOrderRepository(app.db)), app.cfg)
+	orderAgent := agent.NewOrdersAgent(app.service.OrderService, app.cfg)
 	go orderAgent.Run(serverCtx)
 
+	holdsAgent := agent.NewHoldsAgent(app.service.WithdrawalService, time.Duration(*app.cfg.HoldExpiryInterval)*time.Second)
+	go holdsAgent.Run(serverCtx)
+
+	eventsAgent := agent.NewEventsAgent(app.service.EventService, time.Duration(*app.cfg.EventInterval)*time.Second)
+	go eventsAgent.Run(serverCtx)
+
+	webhooksAgent := agent.NewWebhooksAgent(app.service.WebhookService, time.Duration(*app.cfg.WebhookInterval)*time.Second)
+	go webhooksAgent.Run(serverCtx)
+
+	if *app.cfg.PointsExpiryDays > 0 {
+		pointsAgent := agent.NewPointsAgent(app.service.BalanceService, time.Duration(*app.cfg.PointsExpiryInterval)*time.Second)
+		go pointsAgent.Run(serverCtx)
+	}
+
 	err := server.ListenAndServe()
 	if err != nil && !errors.Is(err, http.ErrServerClosed) {
 		logger.Log.Fatal("cannot start server", zap.Error(err))
@@ -16,6 +16,42 @@ const (
 	RateLimitDefault            = 3
 	waitDefault                 = 15
 	updateTimeoutDefault        = 10
+	storageDefault              = StoragePostgres
+	accessTokenTTLDefault       = 3600
+	refreshTokenTTLDefault      = 30 * 24 * 3600
+	jwtSigningKeyDefault        = ""
+	jwtVerificationKeysDefault  = ""
+	jwtAcceptHMACDefault        = true
+	loginPatternDefault         = `^[A-Za-z0-9._@-]{3,64}$`
+	passwordMinLengthDefault    = 8
+	maxFailedLoginsDefault      = 5
+	lockoutDurationDefault      = 900
+	passwordResetTTLDefault     = 3600
+	notifierFileDefault         = ""
+	adminLoginsDefault          = ""
+	holdTTLDefault              = 900
+	holdExpiryIntervalDefault   = 30
+	pointsExpiryDaysDefault     = 0
+	pointsExpiryIntervalDefault = 3600
+	tiersDefault                = "bronze:0,silver:1000,gold:5000"
+	tierWindowDaysDefault       = 365
+	bonusRulesFileDefault       = ""
+	referrerRewardDefault       = "100"
+	refereeRewardDefault        = "50"
+	eventSinksDefault           = "log"
+	eventIntervalDefault        = 1
+	webhookMaxAttemptsDefault   = 8
+	webhookIntervalDefault      = 1
+	accrualCallbackKeyDefault   = ""
+	reconcileTimeoutDefault     = 300
+	orderLeaseDefault           = 60
+	orderMaxAttemptsDefault     = 10
+	workersDefault              = 3
+)
+
+const (
+	StoragePostgres = "postgres"
+	StorageMemory   = "memory"
 )
 
 func InitConfig() (*Config, error) {
@@ -39,9 +75,52 @@ func NewServerConfig() (*Config, error) {
 	accrualSystemAddress := serverFlagSet.String("r", accrualSystemAddressDefault, "accrual system address")
 	secretKey := serverFlagSet.String("s", secretKeyDefault, "secret key")
 	pollInterval := serverFlagSet.Uint("p", pollIntervalDefault, "poll interval")
-	rateLimit := serverFlagSet.Uint("l", RateLimitDefault, "accrual system address")
+	rateLimit := serverFlagSet.Uint("l", RateLimitDefault, "accrual system requests per second, 0 disables the limit")
 	wait := serverFlagSet.Uint("w", waitDefault, "secret key")
 	updateTimeout := serverFlagSet.Uint("u", updateTimeoutDefault, "secret key")
+	storage := serverFlagSet.String("storage", storageDefault, "storage type: postgres or memory")
+	accessTokenTTL := serverFlagSet.Uint("access-ttl", accessTokenTTLDefault, "access token ttl in seconds")
+	refreshTokenTTL := serverFlagSet.Uint("refresh-ttl", refreshTokenTTLDefault, "refresh token ttl in seconds")
+	jwtSigningKey := serverFlagSet.String("jwt-key", jwtSigningKeyDefault, "PEM private key file for RS256/EdDSA token signing")
+	jwtVerificationKeys := serverFlagSet.String("jwt-verify-keys", jwtVerificationKeysDefault, "comma separated PEM key files still accepted for token verification")
+	jwtAcceptHMAC := serverFlagSet.Bool(
+		"jwt-accept-hmac",
+		jwtAcceptHMACDefault,
+		"keep accepting HS256 tokens signed with -s after switching to -jwt-key; disable once access-ttl has passed",
+	)
+	loginPattern := serverFlagSet.String("login-pattern", loginPatternDefault, "regular expression a login must match")
+	passwordMinLength := serverFlagSet.Uint("password-min-length", passwordMinLengthDefault, "minimal password length")
+	maxFailedLogins := serverFlagSet.Uint("max-failed-logins", maxFailedLoginsDefault, "failed login attempts before lockout, 0 disables lockout")
+	lockoutDuration := serverFlagSet.Uint("lockout-duration", lockoutDurationDefault, "account lockout duration in seconds")
+	passwordResetTTL := serverFlagSet.Uint("password-reset-ttl", passwordResetTTLDefault, "password reset token ttl in seconds")
+	notifierFile := serverFlagSet.String("notifier-file", notifierFileDefault, "file for outgoing notifications, log is used when empty")
+	adminLogins := serverFlagSet.String("admins", adminLoginsDefault, "comma separated logins granted the admin role on startup")
+	holdTTL := serverFlagSet.Uint("hold-ttl", holdTTLDefault, "seconds a withdrawal hold stays active before it expires")
+	holdExpiryInterval := serverFlagSet.Uint("hold-expiry-interval", holdExpiryIntervalDefault, "seconds between releases of expired holds")
+	pointsExpiryDays := serverFlagSet.Uint("points-expiry-days", pointsExpiryDaysDefault, "days accrued points stay spendable, 0 disables expiration")
+	pointsExpiryInterval := serverFlagSet.Uint("points-expiry-interval", pointsExpiryIntervalDefault, "seconds between points expiration runs")
+	tiers := serverFlagSet.String("tiers", tiersDefault, "comma separated tier:threshold pairs over rolling accrued totals")
+	tierWindowDays := serverFlagSet.Uint("tier-window-days", tierWindowDaysDefault, "days of accrual history counted towards the tier")
+	bonusRulesFile := serverFlagSet.String("bonus-rules", bonusRulesFileDefault, "JSON file with the bonus rules, rules are kept in memory when empty")
+	referrerReward := serverFlagSet.String("referrer-reward", referrerRewardDefault, "points paid to the referrer for a referee's first processed order")
+	refereeReward := serverFlagSet.String("referee-reward", refereeRewardDefault, "points paid to the referee for their first processed order")
+	eventSinks := serverFlagSet.String("event-sinks", eventSinksDefault, "comma separated event sinks: log, file:<path> or http(s) URLs")
+	eventInterval := serverFlagSet.Uint("event-interval", eventIntervalDefault, "seconds between outbox dispatch runs")
+	webhookMaxAttempts := serverFlagSet.Uint("webhook-max-attempts", webhookMaxAttemptsDefault, "webhook delivery attempts before it is dead-lettered")
+	webhookInterval := serverFlagSet.Uint("webhook-interval", webhookIntervalDefault, "seconds between webhook delivery runs")
+	accrualCallbackKey := serverFlagSet.String("accrual-callback-key", accrualCallbackKeyDefault, "shared key signing accrual callbacks, callbacks are disabled when empty")
+	reconcileTimeout := serverFlagSet.Uint(
+		"reconcile-timeout",
+		reconcileTimeoutDefault,
+		"seconds without a callback before an order is polled again, used instead of -u when callbacks are enabled",
+	)
+	orderLease := serverFlagSet.Uint("order-lease", orderLeaseDefault, "seconds an order stays claimed by a worker before another may retry it")
+	orderMaxAttempts := serverFlagSet.Uint(
+		"order-max-attempts",
+		orderMaxAttemptsDefault,
+		"status checks without an answer before an order is parked for attention, 0 retries forever",
+	)
+	workers := serverFlagSet.Uint("workers", workersDefault, "concurrent accrual system requests")
 	err = serverFlagSet.Parse(os.Args[1:])
 	if err != nil {
 		return nil, err
@@ -70,6 +149,99 @@ func NewServerConfig() (*Config, error) {
 	if newConfig.UpdateTimeout == nil {
 		newConfig.UpdateTimeout = updateTimeout
 	}
+	if newConfig.Storage == nil {
+		newConfig.Storage = storage
+	}
+	if newConfig.AccessTokenTTL == nil {
+		newConfig.AccessTokenTTL = accessTokenTTL
+	}
+	if newConfig.RefreshTokenTTL == nil {
+		newConfig.RefreshTokenTTL = refreshTokenTTL
+	}
+	if newConfig.JWTSigningKey == nil {
+		newConfig.JWTSigningKey = jwtSigningKey
+	}
+	if newConfig.JWTVerificationKeys == nil {
+		newConfig.JWTVerificationKeys = jwtVerificationKeys
+	}
+	if newConfig.JWTAcceptHMAC == nil {
+		newConfig.JWTAcceptHMAC = jwtAcceptHMAC
+	}
+	if newConfig.LoginPattern == nil {
+		newConfig.LoginPattern = loginPattern
+	}
+	if newConfig.PasswordMinLength == nil {
+		newConfig.PasswordMinLength = passwordMinLength
+	}
+	if newConfig.MaxFailedLogins == nil {
+		newConfig.MaxFailedLogins = maxFailedLogins
+	}
+	if newConfig.LockoutDuration == nil {
+		newConfig.LockoutDuration = lockoutDuration
+	}
+	if newConfig.PasswordResetTTL == nil {
+		newConfig.PasswordResetTTL = passwordResetTTL
+	}
+	if newConfig.NotifierFile == nil {
+		newConfig.NotifierFile = notifierFile
+	}
+	if newConfig.AdminLogins == nil {
+		newConfig.AdminLogins = adminLogins
+	}
+	if newConfig.HoldTTL == nil {
+		newConfig.HoldTTL = holdTTL
+	}
+	if newConfig.HoldExpiryInterval == nil {
+		newConfig.HoldExpiryInterval = holdExpiryInterval
+	}
+	if newConfig.PointsExpiryDays == nil {
+		newConfig.PointsExpiryDays = pointsExpiryDays
+	}
+	if newConfig.PointsExpiryInterval == nil {
+		newConfig.PointsExpiryInterval = pointsExpiryInterval
+	}
+	if newConfig.Tiers == nil {
+		newConfig.Tiers = tiers
+	}
+	if newConfig.TierWindowDays == nil {
+		newConfig.TierWindowDays = tierWindowDays
+	}
+	if newConfig.BonusRulesFile == nil {
+		newConfig.BonusRulesFile = bonusRulesFile
+	}
+	if newConfig.ReferrerReward == nil {
+		newConfig.ReferrerReward = referrerReward
+	}
+	if newConfig.RefereeReward == nil {
+		newConfig.RefereeReward = refereeReward
+	}
+	if newConfig.EventSinks == nil {
+		newConfig.EventSinks = eventSinks
+	}
+	if newConfig.EventInterval == nil {
+		newConfig.EventInterval = eventInterval
+	}
+	if newConfig.WebhookMaxAttempts == nil {
+		newConfig.WebhookMaxAttempts = webhookMaxAttempts
+	}
+	if newConfig.WebhookInterval == nil {
+		newConfig.WebhookInterval = webhookInterval
+	}
+	if newConfig.AccrualCallbackKey == nil {
+		newConfig.AccrualCallbackKey = accrualCallbackKey
+	}
+	if newConfig.ReconcileTimeout == nil {
+		newConfig.ReconcileTimeout = reconcileTimeout
+	}
+	if newConfig.OrderLease == nil {
+		newConfig.OrderLease = orderLease
+	}
+	if newConfig.OrderMaxAttempts == nil {
+		newConfig.OrderMaxAttempts = orderMaxAttempts
+	}
+	if newConfig.Workers == nil {
+		newConfig.Workers = workers
+	}
 	return newConfig, nil
 }
 
@@ -82,6 +254,37 @@ type Config struct {
 	RateLimit            *uint   `env:"RATE_LIMIT"`
 	Wait                 *uint   `env:"WAIT"`
 	UpdateTimeout        *uint   `env:"UPDATE_TIMEOUT"`
+	Storage              *string `env:"STORAGE"`
+	AccessTokenTTL       *uint   `env:"ACCESS_TOKEN_TTL"`
+	RefreshTokenTTL      *uint   `env:"REFRESH_TOKEN_TTL"`
+	JWTSigningKey        *string `env:"JWT_SIGNING_KEY"`
+	JWTVerificationKeys  *string `env:"JWT_VERIFICATION_KEYS"`
+	JWTAcceptHMAC        *bool   `env:"JWT_ACCEPT_HMAC"`
+	LoginPattern         *string `env:"LOGIN_PATTERN"`
+	PasswordMinLength    *uint   `env:"PASSWORD_MIN_LENGTH"`
+	MaxFailedLogins      *uint   `env:"MAX_FAILED_LOGINS"`
+	LockoutDuration      *uint   `env:"LOCKOUT_DURATION"`
+	PasswordResetTTL     *uint   `env:"PASSWORD_RESET_TTL"`
+	NotifierFile         *string `env:"NOTIFIER_FILE"`
+	AdminLogins          *string `env:"ADMIN_LOGINS"`
+	HoldTTL              *uint   `env:"HOLD_TTL"`
+	HoldExpiryInterval   *uint   `env:"HOLD_EXPIRY_INTERVAL"`
+	PointsExpiryDays     *uint   `env:"POINTS_EXPIRY_DAYS"`
+	PointsExpiryInterval *uint   `env:"POINTS_EXPIRY_INTERVAL"`
+	Tiers                *string `env:"TIERS"`
+	TierWindowDays       *uint   `env:"TIER_WINDOW_DAYS"`
+	BonusRulesFile       *string `env:"BONUS_RULES_FILE"`
+	ReferrerReward       *string `env:"REFERRER_REWARD"`
+	RefereeReward        *string `env:"REFEREE_REWARD"`
+	EventSinks           *string `env:"EVENT_SINKS"`
+	EventInterval        *uint   `env:"EVENT_INTERVAL"`
+	WebhookMaxAttempts   *uint   `env:"WEBHOOK_MAX_ATTEMPTS"`
+	WebhookInterval      *uint   `env:"WEBHOOK_INTERVAL"`
+	AccrualCallbackKey   *string `env:"ACCRUAL_CALLBACK_KEY"`
+	ReconcileTimeout     *uint   `env:"RECONCILE_TIMEOUT"`
+	OrderLease           *uint   `env:"ORDER_LEASE"`
+	OrderMaxAttempts     *uint   `env:"ORDER_MAX_ATTEMPTS"`
+	Workers              *uint   `env:"WORKERS"`
 }
 
 func InitDefaultEnv() error {
@@ -94,6 +297,37 @@ func InitDefaultEnv() error {
 		"RATE_LIMIT":             strconv.Itoa(RateLimitDefault),
 		"WAIT":                   strconv.Itoa(waitDefault),
 		"UPDATE_TIMEOUT":         strconv.Itoa(updateTimeoutDefault),
+		"STORAGE":                storageDefault,
+		"ACCESS_TOKEN_TTL":       strconv.Itoa(accessTokenTTLDefault),
+		"REFRESH_TOKEN_TTL":      strconv.Itoa(refreshTokenTTLDefault),
+		"JWT_SIGNING_KEY":        jwtSigningKeyDefault,
+		"JWT_VERIFICATION_KEYS":  jwtVerificationKeysDefault,
+		"JWT_ACCEPT_HMAC":        strconv.FormatBool(jwtAcceptHMACDefault),
+		"LOGIN_PATTERN":          loginPatternDefault,
+		"PASSWORD_MIN_LENGTH":    strconv.Itoa(passwordMinLengthDefault),
+		"MAX_FAILED_LOGINS":      strconv.Itoa(maxFailedLoginsDefault),
+		"LOCKOUT_DURATION":       strconv.Itoa(lockoutDurationDefault),
+		"PASSWORD_RESET_TTL":     strconv.Itoa(passwordResetTTLDefault),
+		"NOTIFIER_FILE":          notifierFileDefault,
+		"ADMIN_LOGINS":           adminLoginsDefault,
+		"HOLD_TTL":               strconv.Itoa(holdTTLDefault),
+		"HOLD_EXPIRY_INTERVAL":   strconv.Itoa(holdExpiryIntervalDefault),
+		"POINTS_EXPIRY_DAYS":     strconv.Itoa(pointsExpiryDaysDefault),
+		"POINTS_EXPIRY_INTERVAL": strconv.Itoa(pointsExpiryIntervalDefault),
+		"TIERS":                  tiersDefault,
+		"TIER_WINDOW_DAYS":       strconv.Itoa(tierWindowDaysDefault),
+		"BONUS_RULES_FILE":       bonusRulesFileDefault,
+		"REFERRER_REWARD":        referrerRewardDefault,
+		"REFEREE_REWARD":         refereeRewardDefault,
+		"EVENT_SINKS":            eventSinksDefault,
+		"EVENT_INTERVAL":         strconv.Itoa(eventIntervalDefault),
+		"WEBHOOK_MAX_ATTEMPTS":   strconv.Itoa(webhookMaxAttemptsDefault),
+		"WEBHOOK_INTERVAL":       strconv.Itoa(webhookIntervalDefault),
+		"ACCRUAL_CALLBACK_KEY":   accrualCallbackKeyDefault,
+		"RECONCILE_TIMEOUT":      strconv.Itoa(reconcileTimeoutDefault),
+		"ORDER_LEASE":            strconv.Itoa(orderLeaseDefault),
+		"ORDER_MAX_ATTEMPTS":     strconv.Itoa(orderMaxAttemptsDefault),
+		"WORKERS":                strconv.Itoa(workersDefault),
 	}
 	for k, v := range envDefaults {
 		err := os.Setenv(k, v)
@@ -0,0 +1,45 @@
+-- +goose Up
+
+CREATE TABLE IF NOT EXISTS ledger_transactions (
+    id bigserial PRIMARY KEY,
+    kind text NOT NULL,
+    reference text NOT NULL,
+    created_at timestamptz DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS ledger_postings (
+    id bigserial PRIMARY KEY,
+    transaction_id bigint NOT NULL REFERENCES ledger_transactions (id),
+    account text NOT NULL,
+    amount DECIMAL(10, 2) NOT NULL
+);
+CREATE INDEX IF NOT EXISTS ledger_postings_account_idx ON ledger_postings (account);
+
+-- +goose StatementBegin
+DO $$
+DECLARE
+    opening record;
+    transaction_id bigint;
+BEGIN
+    FOR opening IN SELECT login, current FROM balance WHERE current <> 0 LOOP
+        INSERT INTO ledger_transactions (kind, reference) VALUES ('ADJUSTMENT', 'opening balance')
+            RETURNING id INTO transaction_id;
+        INSERT INTO ledger_postings (transaction_id, account, amount) VALUES
+            (transaction_id, 'system:adjustment', -opening.current),
+            (transaction_id, 'user:' || opening.login, opening.current);
+    END LOOP;
+END $$;
+-- +goose StatementEnd
+
+ALTER TABLE balance DROP COLUMN IF EXISTS current;
+
+-- +goose Down
+ALTER TABLE balance ADD COLUMN IF NOT EXISTS current DECIMAL(10, 2) DEFAULT 0.00;
+UPDATE balance SET current = COALESCE(
+    (SELECT SUM(amount) FROM ledger_postings WHERE account = 'user:' || balance.login),
+    0.00
+);
+DROP TABLE IF EXISTS ledger_postings;
+DROP TABLE IF EXISTS ledger_transactions;
+-- +goose StatementBegin
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+
+CREATE UNIQUE INDEX IF NOT EXISTS ledger_transactions_accrual_reference_idx
+    ON ledger_transactions (reference) WHERE kind = 'ACCRUAL';
+-- +goose StatementBegin
+
+-- +goose StatementEnd
+
+-- +goose Down
+DROP INDEX IF EXISTS ledger_transactions_accrual_reference_idx;
+-- +goose StatementBegin
+-- +goose StatementEnd
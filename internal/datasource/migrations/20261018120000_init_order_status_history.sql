@@ -0,0 +1,24 @@
+-- +goose Up
+
+CREATE TABLE IF NOT EXISTS order_status_history (
+    id bigserial PRIMARY KEY,
+    order_number text NOT NULL REFERENCES orders (number),
+    from_status status_type,
+    to_status status_type NOT NULL,
+    changed_at timestamptz DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS order_status_history_order_number_idx ON order_status_history (order_number);
+
+INSERT INTO order_status_history (order_number, to_status, changed_at)
+SELECT number, 'NEW', uploaded_at FROM orders;
+
+INSERT INTO order_status_history (order_number, from_status, to_status, changed_at)
+SELECT number, 'NEW', status, COALESCE(update_at, uploaded_at) FROM orders WHERE status <> 'NEW';
+-- +goose StatementBegin
+
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TABLE IF EXISTS order_status_history;
+-- +goose StatementBegin
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+
+CREATE INDEX IF NOT EXISTS orders_login_uploaded_at_idx ON orders (login, uploaded_at DESC, number DESC);
+CREATE INDEX IF NOT EXISTS withdrawals_login_processed_at_idx ON withdrawals (login, processed_at DESC, order_number DESC);
+-- +goose StatementBegin
+
+-- +goose StatementEnd
+
+-- +goose Down
+DROP INDEX IF EXISTS withdrawals_login_processed_at_idx;
+DROP INDEX IF EXISTS orders_login_uploaded_at_idx;
+-- +goose StatementBegin
+-- +goose StatementEnd
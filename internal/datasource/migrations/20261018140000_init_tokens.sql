@@ -0,0 +1,26 @@
+-- +goose Up
+
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    token_hash text PRIMARY KEY NOT NULL,
+    login text NOT NULL REFERENCES users (login),
+    access_id text NOT NULL,
+    expires_at timestamptz NOT NULL,
+    revoked_at timestamptz,
+    created_at timestamptz DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS refresh_tokens_login_idx ON refresh_tokens (login);
+CREATE INDEX IF NOT EXISTS refresh_tokens_access_id_idx ON refresh_tokens (access_id);
+
+CREATE TABLE IF NOT EXISTS revoked_tokens (
+    access_id text PRIMARY KEY NOT NULL,
+    expires_at timestamptz NOT NULL
+);
+-- +goose StatementBegin
+
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TABLE IF EXISTS revoked_tokens;
+DROP TABLE IF EXISTS refresh_tokens;
+-- +goose StatementBegin
+-- +goose StatementEnd
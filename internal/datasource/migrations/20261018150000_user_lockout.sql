@@ -0,0 +1,13 @@
+-- +goose Up
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS failed_logins integer NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS locked_until timestamptz;
+-- +goose StatementBegin
+
+-- +goose StatementEnd
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN IF EXISTS locked_until;
+ALTER TABLE users DROP COLUMN IF EXISTS failed_logins;
+-- +goose StatementBegin
+-- +goose StatementEnd
@@ -0,0 +1,34 @@
+-- +goose Up
+
+-- Withdrawals made before the ledger existed are already netted into the opening balance,
+-- so each one is re-credited and withdrawn again to keep the user balance unchanged.
+-- +goose StatementBegin
+DO $$
+DECLARE
+    legacy record;
+    transaction_id bigint;
+BEGIN
+    FOR legacy IN SELECT w.login, w.order_number, w.sum FROM withdrawals w
+        WHERE NOT EXISTS (
+            SELECT 1 FROM ledger_transactions t
+            JOIN ledger_postings p ON p.transaction_id = t.id
+            WHERE t.kind = 'WITHDRAWAL' AND t.reference = w.order_number AND p.account = 'user:' || w.login
+        ) LOOP
+        INSERT INTO ledger_transactions (kind, reference) VALUES ('ADJUSTMENT', 'opening withdrawal')
+            RETURNING id INTO transaction_id;
+        INSERT INTO ledger_postings (transaction_id, account, amount) VALUES
+            (transaction_id, 'system:adjustment', -legacy.sum),
+            (transaction_id, 'user:' || legacy.login, legacy.sum);
+
+        INSERT INTO ledger_transactions (kind, reference) VALUES ('WITHDRAWAL', legacy.order_number)
+            RETURNING id INTO transaction_id;
+        INSERT INTO ledger_postings (transaction_id, account, amount) VALUES
+            (transaction_id, 'user:' || legacy.login, -legacy.sum),
+            (transaction_id, 'system:withdrawal', legacy.sum);
+    END LOOP;
+END $$;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+
+CREATE TABLE IF NOT EXISTS password_reset_tokens (
+    token_hash text PRIMARY KEY NOT NULL,
+    login text NOT NULL REFERENCES users (login),
+    expires_at timestamptz NOT NULL,
+    used_at timestamptz,
+    created_at timestamptz DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS password_reset_tokens_login_idx ON password_reset_tokens (login);
+-- +goose StatementBegin
+
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TABLE IF EXISTS password_reset_tokens;
+-- +goose StatementBegin
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS roles text[] NOT NULL DEFAULT '{user}';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS blocked_at timestamptz;
+-- +goose StatementBegin
+
+-- +goose StatementEnd
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN IF EXISTS blocked_at;
+ALTER TABLE users DROP COLUMN IF EXISTS roles;
+-- +goose StatementBegin
+-- +goose StatementEnd
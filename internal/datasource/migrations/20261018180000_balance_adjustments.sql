@@ -0,0 +1,22 @@
+-- +goose Up
+
+ALTER TABLE ledger_transactions ADD COLUMN IF NOT EXISTS comment text;
+
+CREATE TABLE IF NOT EXISTS balance_adjustments (
+    id bigserial PRIMARY KEY,
+    login text NOT NULL REFERENCES users (login),
+    amount DECIMAL(10, 2) NOT NULL,
+    reason text NOT NULL,
+    operator text NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS balance_adjustments_login_idx ON balance_adjustments (login);
+-- +goose StatementBegin
+
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TABLE IF EXISTS balance_adjustments;
+ALTER TABLE ledger_transactions DROP COLUMN IF EXISTS comment;
+-- +goose StatementBegin
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+
+ALTER TABLE withdrawals ADD COLUMN IF NOT EXISTS status text NOT NULL DEFAULT 'COMPLETED';
+ALTER TABLE withdrawals ADD COLUMN IF NOT EXISTS refunded_at timestamptz;
+-- +goose StatementBegin
+
+-- +goose StatementEnd
+
+-- +goose Down
+ALTER TABLE withdrawals DROP COLUMN IF EXISTS refunded_at;
+ALTER TABLE withdrawals DROP COLUMN IF EXISTS status;
+-- +goose StatementBegin
+-- +goose StatementEnd
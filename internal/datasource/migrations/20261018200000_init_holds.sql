@@ -0,0 +1,22 @@
+-- +goose Up
+
+CREATE TABLE IF NOT EXISTS holds (
+    id text PRIMARY KEY,
+    login text NOT NULL REFERENCES users (login),
+    order_number text NOT NULL,
+    sum DECIMAL(10, 2) NOT NULL,
+    status text NOT NULL DEFAULT 'HELD',
+    created_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at timestamptz NOT NULL
+);
+CREATE INDEX IF NOT EXISTS holds_login_idx ON holds (login, created_at DESC);
+CREATE INDEX IF NOT EXISTS holds_expires_at_idx ON holds (expires_at) WHERE status = 'HELD';
+CREATE UNIQUE INDEX IF NOT EXISTS holds_active_order_idx ON holds (order_number) WHERE status = 'HELD';
+-- +goose StatementBegin
+
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TABLE IF EXISTS holds;
+-- +goose StatementBegin
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS tier text;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS tier_updated_at timestamptz;
+CREATE INDEX IF NOT EXISTS order_status_history_to_status_idx ON order_status_history (to_status, changed_at);
+-- +goose StatementBegin
+
+-- +goose StatementEnd
+
+-- +goose Down
+DROP INDEX IF EXISTS order_status_history_to_status_idx;
+ALTER TABLE users DROP COLUMN IF EXISTS tier_updated_at;
+ALTER TABLE users DROP COLUMN IF EXISTS tier;
+-- +goose StatementBegin
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS referral_code text;
+UPDATE users SET referral_code = upper(substr(md5(random()::text || login), 1, 8)) WHERE referral_code IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS users_referral_code_idx ON users (referral_code);
+
+CREATE TABLE IF NOT EXISTS referrals (
+    referee text PRIMARY KEY REFERENCES users (login),
+    referrer text NOT NULL REFERENCES users (login),
+    created_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    rewarded_at timestamptz,
+    order_number text,
+    referrer_reward DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    referee_reward DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    CHECK (referee <> referrer)
+);
+CREATE INDEX IF NOT EXISTS referrals_referrer_idx ON referrals (referrer, created_at DESC);
+-- +goose StatementBegin
+
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TABLE IF EXISTS referrals;
+DROP INDEX IF EXISTS users_referral_code_idx;
+ALTER TABLE users DROP COLUMN IF EXISTS referral_code;
+-- +goose StatementBegin
+-- +goose StatementEnd
@@ -0,0 +1,36 @@
+-- +goose Up
+
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    id text PRIMARY KEY,
+    url text NOT NULL,
+    secret text NOT NULL,
+    event_types text[] NOT NULL DEFAULT '{}',
+    created_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id bigserial PRIMARY KEY,
+    subscription_id text NOT NULL REFERENCES webhook_subscriptions (id) ON DELETE CASCADE,
+    event_id bigint NOT NULL,
+    event_type text NOT NULL,
+    payload jsonb NOT NULL,
+    status text NOT NULL DEFAULT 'PENDING',
+    attempts integer NOT NULL DEFAULT 0,
+    last_status_code integer,
+    last_error text,
+    created_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    next_attempt_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    locked_until timestamptz,
+    delivered_at timestamptz,
+    UNIQUE (subscription_id, event_id)
+);
+CREATE INDEX IF NOT EXISTS webhook_deliveries_pending_idx ON webhook_deliveries (next_attempt_at, id) WHERE status = 'PENDING';
+-- +goose StatementBegin
+
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhook_subscriptions;
+-- +goose StatementBegin
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS claimed_by text;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS lease_expires_at timestamptz;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS claim_attempts integer NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS parked_at timestamptz;
+-- Orders stuck with the old claim flag are picked up again through the lease.
+ALTER TABLE orders DROP COLUMN IF EXISTS in_update;
+CREATE INDEX IF NOT EXISTS orders_pending_idx ON orders (update_at) WHERE status IN ('NEW', 'PROCESSING') AND parked_at IS NULL;
+-- +goose StatementBegin
+
+-- +goose StatementEnd
+
+-- +goose Down
+DROP INDEX IF EXISTS orders_pending_idx;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS in_update bool DEFAULT false;
+ALTER TABLE orders DROP COLUMN IF EXISTS parked_at;
+ALTER TABLE orders DROP COLUMN IF EXISTS claim_attempts;
+ALTER TABLE orders DROP COLUMN IF EXISTS lease_expires_at;
+ALTER TABLE orders DROP COLUMN IF EXISTS claimed_by;
+-- +goose StatementBegin
+-- +goose StatementEnd
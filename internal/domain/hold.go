@@ -0,0 +1,14 @@
+package domain
+
+import (
+	"context"
+	"github.com/Aleksei-D/go-loyalty-system/internal/models"
+)
+
+type HoldRepository interface {
+	Create(ctx context.Context, hold *models.Hold) error
+	GetAllByLogin(ctx context.Context, login string) ([]*models.Hold, error)
+	Capture(ctx context.Context, login, id string) (*models.Hold, error)
+	Release(ctx context.Context, login, id string) (*models.Hold, error)
+	ExpireHolds(ctx context.Context, limit uint) (uint, error)
+}
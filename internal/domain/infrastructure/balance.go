@@ -20,14 +20,14 @@ func NewPostgresBalanceRepository(db *sql.DB) *PostgresBalanceRepository {
 
 func (p *PostgresBalanceRepository) Get(ctx context.Context, login string) (*models.Balance, error) {
 	var balance models.Balance
-	var current float64
-	var withdrawn sql.NullFloat64
-	tx, err := p.db.Begin()
+	var loginFromDB string
+	tx, err := p.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
 	if err != nil {
 		return nil, err
 	}
+	defer tx.Rollback()
 
-	err = tx.QueryRowContext(ctx, "SELECT current FROM balance WHERE login = $1", login).Scan(&current)
+	err = tx.QueryRowContext(ctx, "SELECT login FROM balance WHERE login = $1", login).Scan(&loginFromDB)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			logger.Log.Info(fmt.Sprintf("balance for user - %s not found", login), zap.Error(err))
@@ -36,21 +36,24 @@ func (p *PostgresBalanceRepository) Get(ctx context.Context, login string) (*mod
 		return &balance, err
 	}
 
-	balance.Login = login
-	balance.Current = current
+	current, err := accountBalance(ctx, tx, models.UserAccount(login))
+	if err != nil {
+		return &balance, err
+	}
 
-	err = tx.QueryRowContext(ctx, "SELECT SUM(sum) as withdrawns FROM withdrawals WHERE login = $1", login).Scan(&withdrawn)
+	withdrawn, err := accountWithdrawn(ctx, tx, models.UserAccount(login), models.HoldAccount(login))
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			logger.Log.Info(fmt.Sprintf("balance for user - %s not found", login), zap.Error(err))
-			return &balance, nil
-		}
 		return &balance, err
 	}
 
-	if withdrawn.Valid {
-		balance.Withdrawn = withdrawn.Float64
+	held, err := accountBalance(ctx, tx, models.HoldAccount(login))
+	if err != nil {
+		return &balance, err
 	}
 
+	balance.Login = login
+	balance.Current = current
+	balance.Withdrawn = withdrawn
+	balance.Held = held
 	return &balance, nil
 }
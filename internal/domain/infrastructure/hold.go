@@ -0,0 +1,269 @@
+package infrastructure
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/Aleksei-D/go-loyalty-system/internal/models"
+	"github.com/Aleksei-D/go-loyalty-system/internal/utils/common"
+	"time"
+)
+
+type PostgresHoldRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresHoldRepository(db *sql.DB) *PostgresHoldRepository {
+	return &PostgresHoldRepository{db: db}
+}
+
+func (p *PostgresHoldRepository) Create(ctx context.Context, hold *models.Hold) error {
+	tx, err := p.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err = lockUserAccount(ctx, tx, hold.Login); err != nil {
+		return err
+	}
+
+	currentBalance, err := accountBalance(ctx, tx, models.UserAccount(hold.Login))
+	if err != nil {
+		return err
+	}
+	if currentBalance < hold.Sum {
+		return common.ErrPaymentInsufficient
+	}
+
+	var used bool
+	err = tx.QueryRowContext(
+		ctx,
+		`SELECT EXISTS (SELECT 1 FROM withdrawals WHERE order_number = $1)
+             OR EXISTS (SELECT 1 FROM holds WHERE order_number = $1 AND status = $2)`,
+		hold.OrderNumber,
+		models.HoldStatusHeld,
+	).Scan(&used)
+	if err != nil {
+		return err
+	}
+	if used {
+		return common.ErrOrderAlreadyAdded
+	}
+
+	var createdAt time.Time
+	err = tx.QueryRowContext(
+		ctx,
+		`INSERT INTO holds (id, login, order_number, sum, status, expires_at) VALUES ($1, $2, $3, $4, $5, $6)
+         RETURNING created_at`,
+		hold.ID,
+		hold.Login,
+		hold.OrderNumber,
+		hold.Sum,
+		models.HoldStatusHeld,
+		hold.ExpiresAt.Time,
+	).Scan(&createdAt)
+	if err != nil {
+		return err
+	}
+
+	if err = postLedgerTransaction(ctx, tx, hold.HoldTransaction()); err != nil {
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	hold.Status = models.HoldStatusHeld
+	hold.CreatedAt = models.CustomTime{Time: createdAt}
+	return nil
+}
+
+func (p *PostgresHoldRepository) GetAllByLogin(ctx context.Context, login string) ([]*models.Hold, error) {
+	holds := make([]*models.Hold, 0)
+	rows, err := p.db.QueryContext(
+		ctx,
+		`SELECT id, order_number, sum, status, created_at, expires_at FROM holds
+         WHERE login = $1 ORDER BY created_at DESC`,
+		login,
+	)
+	if err != nil {
+		return holds, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		hold, err := scanHold(rows, login)
+		if err != nil {
+			return holds, err
+		}
+		holds = append(holds, hold)
+	}
+	return holds, rows.Err()
+}
+
+func (p *PostgresHoldRepository) Capture(ctx context.Context, login, id string) (*models.Hold, error) {
+	tx, err := p.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	hold, err := lockActiveHold(ctx, tx, login, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var withdrawn bool
+	err = tx.QueryRowContext(
+		ctx,
+		"SELECT EXISTS (SELECT 1 FROM withdrawals WHERE order_number = $1)",
+		hold.OrderNumber,
+	).Scan(&withdrawn)
+	if err != nil {
+		return nil, err
+	}
+	if withdrawn {
+		return nil, common.ErrOrderAlreadyAdded
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		"INSERT INTO withdrawals (login, order_number, sum, status) VALUES ($1, $2, $3, $4)",
+		login,
+		hold.OrderNumber,
+		hold.Sum,
+		models.WithdrawalStatusCompleted,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = postLedgerTransaction(ctx, tx, hold.CaptureTransaction()); err != nil {
+		return nil, err
+	}
+	if err = setHoldStatus(ctx, tx, hold, models.HoldStatusCaptured); err != nil {
+		return nil, err
+	}
+	return hold, tx.Commit()
+}
+
+func (p *PostgresHoldRepository) Release(ctx context.Context, login, id string) (*models.Hold, error) {
+	tx, err := p.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	hold, err := lockActiveHold(ctx, tx, login, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = postLedgerTransaction(ctx, tx, hold.ReleaseTransaction()); err != nil {
+		return nil, err
+	}
+	if err = setHoldStatus(ctx, tx, hold, models.HoldStatusReleased); err != nil {
+		return nil, err
+	}
+	return hold, tx.Commit()
+}
+
+func (p *PostgresHoldRepository) ExpireHolds(ctx context.Context, limit uint) (uint, error) {
+	tx, err := p.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(
+		ctx,
+		`SELECT id, order_number, sum, status, created_at, expires_at, login FROM holds
+         WHERE status = $1 AND expires_at <= CURRENT_TIMESTAMP
+         ORDER BY expires_at LIMIT $2 FOR UPDATE SKIP LOCKED`,
+		models.HoldStatusHeld,
+		limit,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	holds := make([]*models.Hold, 0)
+	for rows.Next() {
+		var login string
+		var hold models.Hold
+		var createdAt, expiresAt time.Time
+		err = rows.Scan(&hold.ID, &hold.OrderNumber, &hold.Sum, &hold.Status, &createdAt, &expiresAt, &login)
+		if err != nil {
+			rows.Close()
+			return 0, err
+		}
+		hold.Login = login
+		hold.CreatedAt = models.CustomTime{Time: createdAt}
+		hold.ExpiresAt = models.CustomTime{Time: expiresAt}
+		holds = append(holds, &hold)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, hold := range holds {
+		if err = postLedgerTransaction(ctx, tx, hold.ReleaseTransaction()); err != nil {
+			return 0, err
+		}
+		if err = setHoldStatus(ctx, tx, hold, models.HoldStatusExpired); err != nil {
+			return 0, err
+		}
+	}
+	return uint(len(holds)), tx.Commit()
+}
+
+func lockActiveHold(ctx context.Context, tx *sql.Tx, login, id string) (*models.Hold, error) {
+	row := tx.QueryRowContext(
+		ctx,
+		`SELECT id, order_number, sum, status, created_at, expires_at FROM holds
+         WHERE id = $1 AND login = $2 FOR UPDATE`,
+		id,
+		login,
+	)
+	hold, err := scanHold(row, login)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, common.ErrHoldNotFound
+		}
+		return nil, err
+	}
+	if !hold.IsActive(time.Now()) {
+		return nil, common.ErrHoldNotActive
+	}
+	return hold, nil
+}
+
+func setHoldStatus(ctx context.Context, tx *sql.Tx, hold *models.Hold, status models.HoldStatus) error {
+	_, err := tx.ExecContext(ctx, "UPDATE holds SET status = $1 WHERE id = $2", status, hold.ID)
+	if err != nil {
+		return err
+	}
+	hold.Status = status
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanHold(row rowScanner, login string) (*models.Hold, error) {
+	var hold models.Hold
+	var status string
+	var createdAt, expiresAt time.Time
+	if err := row.Scan(&hold.ID, &hold.OrderNumber, &hold.Sum, &status, &createdAt, &expiresAt); err != nil {
+		return nil, err
+	}
+
+	hold.Login = login
+	hold.Status = models.HoldStatus(status)
+	hold.CreatedAt = models.CustomTime{Time: createdAt}
+	hold.ExpiresAt = models.CustomTime{Time: expiresAt}
+	return &hold, nil
+}
@@ -0,0 +1,273 @@
+package infrastructure
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/Aleksei-D/go-loyalty-system/internal/models"
+	"github.com/Aleksei-D/go-loyalty-system/internal/utils/common"
+	"github.com/lib/pq"
+	"time"
+)
+
+type PostgresLedgerRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresLedgerRepository(db *sql.DB) *PostgresLedgerRepository {
+	return &PostgresLedgerRepository{db: db}
+}
+
+func (p *PostgresLedgerRepository) GetEntriesByLogin(ctx context.Context, login string) ([]*models.LedgerEntry, error) {
+	return accountEntries(ctx, p.db, models.UserAccount(login))
+}
+
+func (p *PostgresLedgerRepository) Adjust(ctx context.Context, adjustment *models.Adjustment) error {
+	tx, err := p.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err = lockUserAccount(ctx, tx, adjustment.Login); err != nil {
+		return err
+	}
+
+	currentBalance, err := accountBalance(ctx, tx, models.UserAccount(adjustment.Login))
+	if err != nil {
+		return err
+	}
+	if currentBalance+adjustment.Amount < 0 {
+		return common.ErrNegativeBalance
+	}
+
+	var createdAt time.Time
+	err = tx.QueryRowContext(
+		ctx,
+		`INSERT INTO balance_adjustments (login, amount, reason, operator) VALUES ($1, $2, $3, $4)
+         RETURNING id, created_at`,
+		adjustment.Login,
+		adjustment.Amount,
+		adjustment.Reason,
+		adjustment.Operator,
+	).Scan(&adjustment.ID, &createdAt)
+	if err != nil {
+		return err
+	}
+	adjustment.CreatedAt = models.CustomTime{Time: createdAt}
+
+	if err = postLedgerTransaction(ctx, tx, adjustment.Transaction()); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (p *PostgresLedgerRepository) GetExpiryCandidates(ctx context.Context, earnedBefore time.Time) ([]string, error) {
+	logins := make([]string, 0)
+	query := `SELECT DISTINCT b.login FROM balance b
+              JOIN ledger_postings p ON p.account = 'user:' || b.login
+              JOIN ledger_transactions t ON t.id = p.transaction_id
+              WHERE t.kind = ANY($1) AND t.created_at <= $2 AND p.amount > 0`
+	rows, err := p.db.QueryContext(
+		ctx,
+		query,
+		pq.Array([]string{models.PostingKindAccrual, models.PostingKindBonus}),
+		earnedBefore,
+	)
+	if err != nil {
+		return logins, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var login string
+		if err = rows.Scan(&login); err != nil {
+			return logins, err
+		}
+		logins = append(logins, login)
+	}
+	return logins, rows.Err()
+}
+
+func (p *PostgresLedgerRepository) ExpirePoints(ctx context.Context, login string, ttl time.Duration) (models.Money, error) {
+	tx, err := p.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if err = lockUserAccount(ctx, tx, login); err != nil {
+		return 0, err
+	}
+
+	entries, err := accountEntries(ctx, tx, models.UserAccount(login))
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	expired := models.ExpiredPoints(models.PointLots(entries, ttl), now)
+	if expired == 0 {
+		return 0, nil
+	}
+
+	err = postLedgerTransaction(ctx, tx, models.NewTransfer(
+		models.PostingKindExpiry,
+		now.Format(time.DateOnly),
+		models.UserAccount(login),
+		models.AccountExpiry,
+		expired,
+	))
+	if err != nil {
+		return 0, err
+	}
+	return expired, tx.Commit()
+}
+
+// AwardBonus posts the order bonus once, trimmed to what is left of the daily cap.
+func (p *PostgresLedgerRepository) AwardBonus(ctx context.Context, award *models.BonusAward, dailyCap models.Money) error {
+	tx, err := p.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err = lockUserAccount(ctx, tx, award.Login); err != nil {
+		return err
+	}
+
+	var awarded bool
+	err = tx.QueryRowContext(
+		ctx,
+		"SELECT EXISTS (SELECT 1 FROM ledger_transactions WHERE kind = $1 AND reference = $2)",
+		models.PostingKindBonus,
+		award.OrderNumber,
+	).Scan(&awarded)
+	if err != nil {
+		return err
+	}
+	if awarded {
+		return common.ErrBonusAwarded
+	}
+
+	var awardedToday models.Money
+	query := `SELECT COALESCE(SUM(p.amount), 0) FROM ledger_postings p
+              JOIN ledger_transactions t ON t.id = p.transaction_id
+              WHERE p.account = $1 AND t.kind = $2 AND t.created_at >= $3`
+	err = tx.QueryRowContext(
+		ctx,
+		query,
+		models.UserAccount(award.Login),
+		models.PostingKindBonus,
+		models.BonusDay(time.Now()),
+	).Scan(&awardedToday)
+	if err != nil {
+		return err
+	}
+
+	award.Cap(dailyCap, awardedToday)
+	if award.Amount == 0 {
+		return nil
+	}
+	if err = postLedgerTransaction(ctx, tx, award.Transaction()); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func postLedgerTransaction(ctx context.Context, tx *sql.Tx, transaction *models.LedgerTransaction) error {
+	if !transaction.IsBalanced() {
+		return fmt.Errorf("ledger transaction %s %s is not balanced", transaction.Kind, transaction.Reference)
+	}
+
+	var transactionID int64
+	err := tx.QueryRowContext(
+		ctx,
+		"INSERT INTO ledger_transactions (kind, reference, comment) VALUES ($1, $2, NULLIF($3, '')) RETURNING id",
+		transaction.Kind,
+		transaction.Reference,
+		transaction.Comment,
+	).Scan(&transactionID)
+	if err != nil {
+		return err
+	}
+
+	for _, posting := range transaction.Postings {
+		_, err = tx.ExecContext(
+			ctx,
+			"INSERT INTO ledger_postings (transaction_id, account, amount) VALUES ($1, $2, $3)",
+			transactionID,
+			posting.Account,
+			posting.Amount,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func lockUserAccount(ctx context.Context, tx *sql.Tx, login string) error {
+	var loginFromDB string
+	err := tx.QueryRowContext(ctx, "SELECT login FROM balance WHERE login = $1 FOR UPDATE", login).Scan(&loginFromDB)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("user balance - %s not found: %w", login, err)
+	}
+	return err
+}
+
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+func accountEntries(ctx context.Context, q queryer, account string) ([]*models.LedgerEntry, error) {
+	entries := make([]*models.LedgerEntry, 0)
+	query := `SELECT t.id, t.kind, t.reference, p.amount, COALESCE(t.comment, ''), t.created_at FROM ledger_postings p
+              JOIN ledger_transactions t ON t.id = p.transaction_id
+              WHERE p.account = $1 ORDER BY t.id DESC`
+	rows, err := q.QueryContext(ctx, query, account)
+	if err != nil {
+		return entries, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var entry models.LedgerEntry
+		var createdAt time.Time
+		err := rows.Scan(&entry.TransactionID, &entry.Kind, &entry.Reference, &entry.Amount, &entry.Comment, &createdAt)
+		if err != nil {
+			return entries, err
+		}
+
+		entry.CreatedAt = models.CustomTime{Time: createdAt}
+		entries = append(entries, &entry)
+	}
+	return entries, rows.Err()
+}
+
+type queryRower interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func accountBalance(ctx context.Context, q queryRower, account string) (models.Money, error) {
+	var balance models.Money
+	err := q.QueryRowContext(
+		ctx,
+		"SELECT COALESCE(SUM(amount), 0) FROM ledger_postings WHERE account = $1",
+		account,
+	).Scan(&balance)
+	return balance, err
+}
+
+// accountWithdrawn nets everything the accounts have sent to the withdrawal account,
+// so reversed withdrawals are no longer counted.
+func accountWithdrawn(ctx context.Context, q queryRower, accounts ...string) (models.Money, error) {
+	var withdrawn models.Money
+	query := `SELECT COALESCE(SUM(w.amount), 0) FROM ledger_postings w
+              WHERE w.account = $2 AND w.transaction_id IN (
+                  SELECT transaction_id FROM ledger_postings WHERE account = ANY($1)
+              )`
+	err := q.QueryRowContext(ctx, query, pq.Array(accounts), models.AccountWithdrawal).Scan(&withdrawn)
+	return withdrawn, err
+}
@@ -0,0 +1,40 @@
+package infrastructure
+
+import (
+	"fmt"
+	"github.com/Aleksei-D/go-loyalty-system/internal/models"
+	"strings"
+)
+
+func listQuery(selectQuery, timeColumn, keyColumn string, filter *models.ListFilter, args []any) (string, []any) {
+	conditions := make([]string, 0)
+	addCondition := func(condition string, values ...any) {
+		placeholders := make([]any, len(values))
+		for i, value := range values {
+			args = append(args, value)
+			placeholders[i] = len(args)
+		}
+		conditions = append(conditions, fmt.Sprintf(condition, placeholders...))
+	}
+
+	if filter.From != nil {
+		addCondition(timeColumn+" >= $%d", *filter.From)
+	}
+	if filter.To != nil {
+		addCondition(timeColumn+" < $%d", *filter.To)
+	}
+	if filter.Cursor != nil {
+		addCondition("("+timeColumn+", "+keyColumn+") < ($%d, $%d)", filter.Cursor.Time, filter.Cursor.Key)
+	}
+
+	query := selectQuery
+	if len(conditions) > 0 {
+		query += " AND " + strings.Join(conditions, " AND ")
+	}
+	query += fmt.Sprintf(" ORDER BY %s DESC, %s DESC", timeColumn, keyColumn)
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+	return query, args
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"github.com/Aleksei-D/go-loyalty-system/internal/logger"
 	"github.com/Aleksei-D/go-loyalty-system/internal/models"
+	"github.com/Aleksei-D/go-loyalty-system/internal/utils/common"
 	"github.com/lib/pq"
 	"go.uber.org/zap"
 	"time"
@@ -22,61 +23,74 @@ func NewPostgresOrderRepository(db *sql.DB) *PostgresOrderRepository {
 
 func (p *PostgresOrderRepository) Add(ctx context.Context, login, orderNumber string) (*models.Order, error) {
 	var order models.Order
-	var status string
-	var accrual sql.NullFloat64
+	var status models.OrderStatus
+	var accrual sql.Null[models.Money]
 	var loginFromDB string
 	var orderNumberFromDB string
 	var uploadedAt time.Time
 
-	row := p.db.QueryRowContext(
-		ctx,
-		"INSERT INTO orders (number, login) VALUES ($1, $2) RETURNING login, number, status, accrual, uploaded_at",
-		orderNumber,
-		login,
-	)
-	if err := row.Err(); err != nil {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return &order, err
+	}
+	defer tx.Rollback()
+
+	query := `WITH added AS (
+                  INSERT INTO orders (number, login) VALUES ($1, $2) RETURNING login, number, status, accrual, uploaded_at
+              ), history AS (
+                  INSERT INTO order_status_history (order_number, to_status, changed_at)
+                  SELECT number, status, uploaded_at FROM added
+              )
+              SELECT login, number, status, accrual, uploaded_at FROM added`
+	row := tx.QueryRowContext(ctx, query, orderNumber, login)
+	if err = row.Err(); err != nil {
 		logger.Log.Info(err.Error(), zap.Error(err))
 		return &order, err
 	}
 
-	err := row.Scan(&loginFromDB, &orderNumberFromDB, &status, &accrual, &uploadedAt)
+	err = row.Scan(&loginFromDB, &orderNumberFromDB, &status, &accrual, &uploadedAt)
 	if err != nil {
 		logger.Log.Info(err.Error(), zap.Error(err))
 		return &order, err
 	}
 
 	if accrual.Valid {
-		order.Accrual = &accrual.Float64
+		order.Accrual = &accrual.V
 	}
 	order.Login = loginFromDB
 	order.Status = status
 	order.Number = orderNumberFromDB
 	order.UploadedAt = models.CustomTime{Time: uploadedAt}
-	return &order, nil
+
+	event, err := models.OrderAcceptedEvent(&order)
+	if err != nil {
+		return &order, err
+	}
+	if err = insertOutboxEvents(ctx, tx, event); err != nil {
+		return &order, err
+	}
+	return &order, tx.Commit()
 }
 
-func (p *PostgresOrderRepository) GetAllByLogin(ctx context.Context, login string) ([]*models.Order, error) {
+func (p *PostgresOrderRepository) GetAllByLogin(ctx context.Context, login string, filter *models.ListFilter) ([]*models.Order, error) {
 	orders := make([]*models.Order, 0)
-	rows, err := p.db.QueryContext(
-		ctx,
-		"SELECT status, number, accrual, uploaded_at FROM orders WHERE login = $1 ORDER BY uploaded_at DESC",
-		login,
-	)
-	if rows.Err() != nil {
-		return orders, err
+	args := []any{login}
+	selectQuery := "SELECT status, number, accrual, uploaded_at FROM orders WHERE login = $1"
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		selectQuery += " AND status = $2"
 	}
+	query, args := listQuery(selectQuery, "uploaded_at", "number", filter, args)
+
+	rows, err := p.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			logger.Log.Info(err.Error(), zap.Error(err))
-			return orders, nil
-		}
 		return orders, err
 	}
 	defer rows.Close()
 
 	for rows.Next() {
-		var status string
-		var accrual sql.NullFloat64
+		var status models.OrderStatus
+		var accrual sql.Null[models.Money]
 		var uploadedAt time.Time
 		var number string
 		var order models.Order
@@ -86,7 +100,7 @@ func (p *PostgresOrderRepository) GetAllByLogin(ctx context.Context, login strin
 		}
 
 		if accrual.Valid {
-			order.Accrual = &accrual.Float64
+			order.Accrual = &accrual.V
 		}
 		order.Number = number
 		order.UploadedAt = models.CustomTime{Time: uploadedAt}
@@ -94,31 +108,35 @@ func (p *PostgresOrderRepository) GetAllByLogin(ctx context.Context, login strin
 		order.Login = login
 		orders = append(orders, &order)
 	}
-	return orders, nil
+	return orders, rows.Err()
 }
 
 func (p *PostgresOrderRepository) GetOrderByNumber(ctx context.Context, orderNumber string) (*models.Order, error) {
 	var order models.Order
-	var status string
-	var accrual sql.NullFloat64
+	var status models.OrderStatus
+	var accrual sql.Null[models.Money]
 	var login string
 	var orderFromDB string
 	var uploadedAt time.Time
+	var checkedAt sql.NullTime
 	err := p.db.QueryRowContext(
 		ctx,
-		"SELECT login, number, status, accrual, uploaded_at FROM orders WHERE number = $1",
+		"SELECT login, number, status, accrual, uploaded_at, update_at FROM orders WHERE number = $1",
 		orderNumber,
-	).Scan(&login, &orderFromDB, &status, &accrual, &uploadedAt)
+	).Scan(&login, &orderFromDB, &status, &accrual, &uploadedAt, &checkedAt)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			logger.Log.Info(fmt.Sprintf("order - %s not found", orderNumber), zap.Error(err))
-			return nil, err
+			return nil, common.ErrOrderNotFound
 		}
 		return &order, err
 	}
 
 	if accrual.Valid {
-		order.Accrual = &accrual.Float64
+		order.Accrual = &accrual.V
+	}
+	if checkedAt.Valid {
+		order.CheckedAt = &models.CustomTime{Time: checkedAt.Time}
 	}
 	order.Number = orderFromDB
 	order.UploadedAt = models.CustomTime{Time: uploadedAt}
@@ -127,72 +145,159 @@ func (p *PostgresOrderRepository) GetOrderByNumber(ctx context.Context, orderNum
 	return &order, nil
 }
 
-func (p *PostgresOrderRepository) GetNotAcceptedOrderNumbers(ctx context.Context, limit, updateTimeout uint) ([]*models.Order, error) {
+// ClaimOrders leases due orders to the worker. Rows locked by another worker are skipped, and an order whose
+// lease expired without a status report is claimed again, so a failed request or a dead worker never strands it.
+func (p *PostgresOrderRepository) ClaimOrders(ctx context.Context, claim *models.OrderClaim) ([]*models.Order, error) {
 	orders := make([]*models.Order, 0)
 	tx, err := p.db.BeginTx(ctx, nil)
-	defer tx.Rollback()
 	if err != nil {
 		return orders, err
 	}
-	query := `SELECT number FROM orders 
-              WHERE status = ANY($1) and (update_at + $2::interval < CURRENT_TIMESTAMP or update_at is null ) and in_update is false 
-              limit $3`
+	defer tx.Rollback()
+
+	query := `SELECT login, number, status, claim_attempts FROM orders
+              WHERE status = ANY($1) AND parked_at IS NULL
+                AND (lease_expires_at IS NULL OR lease_expires_at < CURRENT_TIMESTAMP)
+                AND (update_at IS NULL OR update_at + make_interval(secs => $2) < CURRENT_TIMESTAMP)
+              ORDER BY update_at NULLS FIRST, number
+              LIMIT $3
+              FOR UPDATE SKIP LOCKED`
 	rows, err := tx.QueryContext(
 		ctx,
 		query,
-		pq.Array([]string{models.OrderStatusNew, models.OrderStatusProcessing}),
-		fmt.Sprintf("%d second", updateTimeout),
-		limit,
+		pq.Array([]string{string(models.OrderStatusNew), string(models.OrderStatusProcessing)}),
+		claim.UpdateTimeout.Seconds(),
+		claim.Limit,
 	)
-	if rows.Err() != nil {
-		return orders, err
-	}
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			logger.Log.Info(err.Error(), zap.Error(err))
-			return orders, nil
-		}
 		return orders, err
 	}
-	defer rows.Close()
 
-	orderNumbers := make([]string, len(orders))
+	claimedNumbers := make([]string, 0)
+	parked := make([]*models.ParkedOrder, 0)
 	for rows.Next() {
-		var number string
-		err := rows.Scan(&number)
-		if err != nil {
+		var order models.ParkedOrder
+		if err = rows.Scan(&order.Login, &order.Number, &order.Status, &order.Attempts); err != nil {
+			rows.Close()
 			return orders, err
 		}
-
-		orders = append(orders, &models.Order{Number: number})
-		orderNumbers = append(orderNumbers, number)
+		if claim.Exhausted(order.Attempts) {
+			parked = append(parked, &order)
+			continue
+		}
+		claimedNumbers = append(claimedNumbers, order.Number)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return orders, err
 	}
 
 	_, err = tx.ExecContext(
 		ctx,
-		"UPDATE orders SET update_at = CURRENT_TIMESTAMP, in_update = true WHERE number = ANY($1)",
-		pq.Array(orderNumbers),
+		`UPDATE orders SET claimed_by = $1, lease_expires_at = CURRENT_TIMESTAMP + make_interval(secs => $2),
+             claim_attempts = claim_attempts + 1, update_at = CURRENT_TIMESTAMP
+         WHERE number = ANY($3)`,
+		claim.Worker,
+		claim.Lease.Seconds(),
+		pq.Array(claimedNumbers),
 	)
 	if err != nil {
 		return orders, err
 	}
-	if err := tx.Commit(); err != nil {
+
+	if err = parkOrders(ctx, tx, parked); err != nil {
+		return orders, err
+	}
+	if err = tx.Commit(); err != nil {
 		return orders, err
 	}
 
+	for _, number := range claimedNumbers {
+		orders = append(orders, &models.Order{Number: number})
+	}
 	return orders, nil
 }
 
-func (p *PostgresOrderRepository) UpdateStatus(ctx context.Context, order *models.Order) error {
-	var loginFromDB string
-	var inUpdateStatus bool
-	switch order.Status {
-	case models.OrderStatusInvalid, models.OrderStatusProcessed:
-		inUpdateStatus = true
-	default:
-		inUpdateStatus = false
+func parkOrders(ctx context.Context, tx *sql.Tx, parked []*models.ParkedOrder) error {
+	for _, order := range parked {
+		_, err := tx.ExecContext(
+			ctx,
+			"UPDATE orders SET parked_at = CURRENT_TIMESTAMP, claimed_by = NULL, lease_expires_at = NULL WHERE number = $1",
+			order.Number,
+		)
+		if err != nil {
+			return err
+		}
+
+		event, err := models.OrderParkedEvent(order)
+		if err != nil {
+			return err
+		}
+		if err = insertOutboxEvents(ctx, tx, event); err != nil {
+			return err
+		}
+		logger.Log.Warn("order parked after failed status checks", zap.String("order", order.Number), zap.Uint("attempts", order.Attempts))
+	}
+	return nil
+}
+
+func (p *PostgresOrderRepository) GetParkedOrders(ctx context.Context) ([]*models.ParkedOrder, error) {
+	orders := make([]*models.ParkedOrder, 0)
+	rows, err := p.db.QueryContext(
+		ctx,
+		"SELECT login, number, status, claim_attempts, parked_at FROM orders WHERE parked_at IS NOT NULL ORDER BY parked_at, number",
+	)
+	if err != nil {
+		return orders, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var order models.ParkedOrder
+		var parkedAt time.Time
+		if err = rows.Scan(&order.Login, &order.Number, &order.Status, &order.Attempts, &parkedAt); err != nil {
+			return orders, err
+		}
+		order.ParkedAt = models.CustomTime{Time: parkedAt}
+		orders = append(orders, &order)
 	}
+	return orders, rows.Err()
+}
+
+// UnparkOrder returns a parked order to polling with a fresh set of attempts.
+func (p *PostgresOrderRepository) UnparkOrder(ctx context.Context, orderNumber string) error {
+	result, err := p.db.ExecContext(
+		ctx,
+		"UPDATE orders SET parked_at = NULL, claim_attempts = 0, update_at = NULL WHERE number = $1 AND parked_at IS NOT NULL",
+		orderNumber,
+	)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected > 0 {
+		return nil
+	}
+
+	exists, err := p.IsExist(ctx, orderNumber)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return common.ErrOrderNotFound
+	}
+	return common.ErrOrderNotParked
+}
+
+// releaseClaim ends the lease once the accrual system reported a status, pushed or polled.
+const releaseClaim = "claimed_by = NULL, lease_expires_at = NULL, claim_attempts = 0, parked_at = NULL, update_at = CURRENT_TIMESTAMP"
 
+func (p *PostgresOrderRepository) UpdateStatus(ctx context.Context, order *models.Order) error {
+	var loginFromDB string
+	var currentStatus models.OrderStatus
 	tx, err := p.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
@@ -201,38 +306,113 @@ func (p *PostgresOrderRepository) UpdateStatus(ctx context.Context, order *model
 
 	err = tx.QueryRowContext(
 		ctx,
-		"UPDATE orders SET status = $1, accrual = $2, in_update = $3 WHERE number = $4 RETURNING login",
+		"SELECT login, status FROM orders WHERE number = $1 FOR UPDATE",
+		order.Number,
+	).Scan(&loginFromDB, &currentStatus)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return common.ErrOrderNotFound
+		}
+		return err
+	}
+
+	changed, err := currentStatus.CheckTransition(order.Status)
+	if err != nil {
+		return err
+	}
+	if !changed {
+		if currentStatus.IsFinal() {
+			logger.Log.Info(fmt.Sprintf("order - %s already has final status, update skipped", order.Number))
+			return nil
+		}
+		_, err = tx.ExecContext(ctx, "UPDATE orders SET "+releaseClaim+" WHERE number = $1", order.Number)
+		if err != nil {
+			return err
+		}
+		return tx.Commit()
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		"UPDATE orders SET status = $1, accrual = $2, "+releaseClaim+" WHERE number = $3",
 		order.Status,
 		order.Accrual,
-		inUpdateStatus,
 		order.Number,
-	).Scan(&loginFromDB)
-
+	)
 	if err != nil {
 		return err
 	}
 
-	if order.Accrual != nil {
-		_, err = tx.ExecContext(
-			ctx,
-			"UPDATE balance SET current = current + $1 WHERE login = $2",
-			order.Accrual,
-			loginFromDB,
-		)
+	_, err = tx.ExecContext(
+		ctx,
+		"INSERT INTO order_status_history (order_number, from_status, to_status) VALUES ($1, $2, $3)",
+		order.Number,
+		currentStatus,
+		order.Status,
+	)
+	if err != nil {
+		return err
+	}
 
+	if order.Status == models.OrderStatusProcessed && order.Accrual != nil {
+		err = postLedgerTransaction(ctx, tx, models.NewTransfer(
+			models.PostingKindAccrual,
+			order.Number,
+			models.AccountAccrual,
+			models.UserAccount(loginFromDB),
+			*order.Accrual,
+		))
 		if err != nil {
 			return err
 		}
 	}
 
+	events, err := models.OrderUpdateEvents(loginFromDB, currentStatus, order)
+	if err != nil {
+		return err
+	}
+	if err = insertOutboxEvents(ctx, tx, events...); err != nil {
+		return err
+	}
+
 	if err := tx.Commit(); err != nil {
 		return err
 	}
 	return nil
 }
 
+func (p *PostgresOrderRepository) GetStatusHistory(ctx context.Context, orderNumber string) ([]*models.OrderStatusChange, error) {
+	history := make([]*models.OrderStatusChange, 0)
+	rows, err := p.db.QueryContext(
+		ctx,
+		"SELECT from_status, to_status, changed_at FROM order_status_history WHERE order_number = $1 ORDER BY id",
+		orderNumber,
+	)
+	if err != nil {
+		return history, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var previousStatus sql.Null[models.OrderStatus]
+		var changedAt time.Time
+		var change models.OrderStatusChange
+		err := rows.Scan(&previousStatus, &change.Status, &changedAt)
+		if err != nil {
+			return history, err
+		}
+
+		if previousStatus.Valid {
+			change.PreviousStatus = &previousStatus.V
+		}
+		change.ChangedAt = models.CustomTime{Time: changedAt}
+		history = append(history, &change)
+	}
+	return history, rows.Err()
+}
+
 func (p *PostgresOrderRepository) IsExist(ctx context.Context, orderNumber string) (bool, error) {
-	var status string
+	var status models.OrderStatus
 	err := p.db.QueryRowContext(ctx, "SELECT number FROM orders WHERE number = $1", orderNumber).Scan(&status)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -243,3 +423,13 @@ func (p *PostgresOrderRepository) IsExist(ctx context.Context, orderNumber strin
 	}
 	return true, nil
 }
+
+// GetAccruedTotal sums the accruals of the user's orders processed since the given time.
+func (p *PostgresOrderRepository) GetAccruedTotal(ctx context.Context, login string, since time.Time) (models.Money, error) {
+	var total models.Money
+	query := `SELECT COALESCE(SUM(o.accrual), 0) FROM orders o
+              JOIN order_status_history h ON h.order_number = o.number AND h.to_status = 'PROCESSED'
+              WHERE o.login = $1 AND o.status = 'PROCESSED' AND h.changed_at >= $2`
+	err := p.db.QueryRowContext(ctx, query, login, since).Scan(&total)
+	return total, err
+}
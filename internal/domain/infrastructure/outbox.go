@@ -0,0 +1,93 @@
+package infrastructure
+
+import (
+	"context"
+	"database/sql"
+	"github.com/Aleksei-D/go-loyalty-system/internal/models"
+	"sort"
+	"time"
+)
+
+type PostgresOutboxRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresOutboxRepository(db *sql.DB) *PostgresOutboxRepository {
+	return &PostgresOutboxRepository{db: db}
+}
+
+// Claim locks a batch of due events for lease, so concurrent dispatchers never deliver the same batch twice.
+func (p *PostgresOutboxRepository) Claim(ctx context.Context, limit uint, lease time.Duration) ([]*models.Event, error) {
+	events := make([]*models.Event, 0)
+	query := `UPDATE outbox SET locked_until = now() + make_interval(secs => $2)
+              WHERE id IN (
+                  SELECT id FROM outbox
+                  WHERE delivered_at IS NULL AND next_attempt_at <= now() AND (locked_until IS NULL OR locked_until <= now())
+                  ORDER BY id LIMIT $1 FOR UPDATE SKIP LOCKED
+              )
+              RETURNING id, type, login, key, payload, created_at, attempts`
+	rows, err := p.db.QueryContext(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		return events, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var event models.Event
+		var createdAt time.Time
+		err = rows.Scan(&event.ID, &event.Type, &event.Login, &event.Key, &event.Payload, &createdAt, &event.Attempts)
+		if err != nil {
+			return events, err
+		}
+		event.CreatedAt = models.CustomTime{Time: createdAt}
+		events = append(events, &event)
+	}
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].ID < events[j].ID
+	})
+	return events, rows.Err()
+}
+
+func (p *PostgresOutboxRepository) MarkDelivered(ctx context.Context, id int64) error {
+	_, err := p.db.ExecContext(
+		ctx,
+		"UPDATE outbox SET delivered_at = CURRENT_TIMESTAMP, locked_until = NULL, last_error = NULL WHERE id = $1",
+		id,
+	)
+	return err
+}
+
+func (p *PostgresOutboxRepository) MarkFailed(ctx context.Context, id int64, retryAt time.Time, reason string) error {
+	_, err := p.db.ExecContext(
+		ctx,
+		`UPDATE outbox SET attempts = attempts + 1, next_attempt_at = $2, locked_until = NULL, last_error = $3
+         WHERE id = $1`,
+		id,
+		retryAt,
+		reason,
+	)
+	return err
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// insertOutboxEvents stores events in the caller's transaction, so they are published only if it commits.
+func insertOutboxEvents(ctx context.Context, tx execer, events ...*models.Event) error {
+	for _, event := range events {
+		_, err := tx.ExecContext(
+			ctx,
+			"INSERT INTO outbox (type, login, key, payload, created_at) VALUES ($1, $2, $3, $4, $5)",
+			event.Type,
+			event.Login,
+			event.Key,
+			[]byte(event.Payload),
+			event.CreatedAt.Time,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package infrastructure
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/Aleksei-D/go-loyalty-system/internal/models"
+	"github.com/Aleksei-D/go-loyalty-system/internal/utils/common"
+)
+
+type PostgresPasswordResetRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresPasswordResetRepository(db *sql.DB) *PostgresPasswordResetRepository {
+	return &PostgresPasswordResetRepository{db: db}
+}
+
+// SaveResetToken supersedes every reset token issued to the same login before.
+func (p *PostgresPasswordResetRepository) SaveResetToken(ctx context.Context, token *models.PasswordResetToken) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(
+		ctx,
+		"DELETE FROM password_reset_tokens WHERE login = $1 OR expires_at < CURRENT_TIMESTAMP",
+		token.Login,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		"INSERT INTO password_reset_tokens (token_hash, login, expires_at) VALUES ($1, $2, $3)",
+		token.Hash,
+		token.Login,
+		token.ExpiresAt,
+	)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (p *PostgresPasswordResetRepository) ConsumeResetToken(ctx context.Context, hash string) (*models.PasswordResetToken, error) {
+	token := models.PasswordResetToken{Hash: hash}
+	query := `UPDATE password_reset_tokens SET used_at = CURRENT_TIMESTAMP
+              WHERE token_hash = $1 AND used_at IS NULL AND expires_at > CURRENT_TIMESTAMP
+              RETURNING login, expires_at`
+	err := p.db.QueryRowContext(ctx, query, hash).Scan(&token.Login, &token.ExpiresAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, common.ErrInvalidResetToken
+		}
+		return nil, err
+	}
+	return &token, nil
+}
@@ -0,0 +1,115 @@
+package infrastructure
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/Aleksei-D/go-loyalty-system/internal/models"
+	"github.com/Aleksei-D/go-loyalty-system/internal/utils/common"
+	"time"
+)
+
+type PostgresReferralRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresReferralRepository(db *sql.DB) *PostgresReferralRepository {
+	return &PostgresReferralRepository{db: db}
+}
+
+const referralColumns = "referrer, referee, created_at, rewarded_at, COALESCE(order_number, ''), referrer_reward"
+
+func scanReferral(row rowScanner) (*models.Referral, error) {
+	var referral models.Referral
+	var createdAt time.Time
+	var rewardedAt sql.NullTime
+	err := row.Scan(
+		&referral.Referrer,
+		&referral.Referee,
+		&createdAt,
+		&rewardedAt,
+		&referral.OrderNumber,
+		&referral.ReferrerPaid,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	referral.CreatedAt = models.CustomTime{Time: createdAt}
+	if rewardedAt.Valid {
+		referral.RewardedAt = &models.CustomTime{Time: rewardedAt.Time}
+	}
+	return &referral, nil
+}
+
+func (p *PostgresReferralRepository) GetAllByReferrer(ctx context.Context, referrer string) ([]*models.Referral, error) {
+	referrals := make([]*models.Referral, 0)
+	query := "SELECT " + referralColumns + " FROM referrals WHERE referrer = $1 ORDER BY created_at DESC, referee"
+	rows, err := p.db.QueryContext(ctx, query, referrer)
+	if err != nil {
+		return referrals, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		referral, err := scanReferral(rows)
+		if err != nil {
+			return referrals, err
+		}
+		referrals = append(referrals, referral)
+	}
+	return referrals, rows.Err()
+}
+
+func (p *PostgresReferralRepository) GetByReferee(ctx context.Context, referee string) (*models.Referral, error) {
+	row := p.db.QueryRowContext(ctx, "SELECT "+referralColumns+" FROM referrals WHERE referee = $1", referee)
+	referral, err := scanReferral(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, common.ErrReferralNotFound
+	}
+	return referral, err
+}
+
+// Reward pays the referral in the same transaction that marks it rewarded, so it is paid once.
+func (p *PostgresReferralRepository) Reward(ctx context.Context, reward *models.ReferralReward) error {
+	tx, err := p.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var rewardedAt sql.NullTime
+	err = tx.QueryRowContext(
+		ctx, "SELECT rewarded_at FROM referrals WHERE referee = $1 FOR UPDATE", reward.Referee,
+	).Scan(&rewardedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return common.ErrReferralNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if rewardedAt.Valid {
+		return common.ErrReferralRewarded
+	}
+
+	if reward.ReferrerAmount+reward.RefereeAmount > 0 {
+		if err = postLedgerTransaction(ctx, tx, reward.Transaction()); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		`UPDATE referrals SET rewarded_at = $2, order_number = $3, referrer_reward = $4, referee_reward = $5
+         WHERE referee = $1`,
+		reward.Referee,
+		reward.RewardedAt,
+		reward.OrderNumber,
+		reward.ReferrerAmount,
+		reward.RefereeAmount,
+	)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
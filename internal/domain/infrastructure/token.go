@@ -0,0 +1,172 @@
+package infrastructure
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/Aleksei-D/go-loyalty-system/internal/logger"
+	"github.com/Aleksei-D/go-loyalty-system/internal/models"
+	"github.com/Aleksei-D/go-loyalty-system/internal/utils/common"
+	"go.uber.org/zap"
+	"time"
+)
+
+type PostgresTokenRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresTokenRepository(db *sql.DB) *PostgresTokenRepository {
+	return &PostgresTokenRepository{db: db}
+}
+
+func (p *PostgresTokenRepository) SaveRefreshToken(ctx context.Context, token *models.RefreshToken) error {
+	_, err := p.db.ExecContext(
+		ctx,
+		"INSERT INTO refresh_tokens (token_hash, login, access_id, expires_at) VALUES ($1, $2, $3, $4)",
+		token.Hash,
+		token.Login,
+		token.AccessID,
+		token.ExpiresAt,
+	)
+	return err
+}
+
+func (p *PostgresTokenRepository) RotateRefreshToken(ctx context.Context, oldHash string, newToken *models.RefreshToken) (*models.RefreshToken, error) {
+	var login string
+	var expiresAt time.Time
+	var revokedAt sql.NullTime
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(
+		ctx,
+		"SELECT login, expires_at, revoked_at FROM refresh_tokens WHERE token_hash = $1 FOR UPDATE",
+		oldHash,
+	).Scan(&login, &expiresAt, &revokedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, common.ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+
+	if revokedAt.Valid {
+		logger.Log.Warn("refresh token reuse detected, revoking user sessions", zap.String("login", login))
+		if err = revokeUserSessions(ctx, tx, login); err != nil {
+			return nil, err
+		}
+		if err = tx.Commit(); err != nil {
+			return nil, err
+		}
+		return nil, common.ErrRefreshTokenReused
+	}
+	if !expiresAt.After(time.Now()) {
+		return nil, common.ErrInvalidRefreshToken
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE refresh_tokens SET revoked_at = CURRENT_TIMESTAMP WHERE token_hash = $1", oldHash)
+	if err != nil {
+		return nil, err
+	}
+
+	newToken.Login = login
+	_, err = tx.ExecContext(
+		ctx,
+		"INSERT INTO refresh_tokens (token_hash, login, access_id, expires_at) VALUES ($1, $2, $3, $4)",
+		newToken.Hash,
+		newToken.Login,
+		newToken.AccessID,
+		newToken.ExpiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return newToken, nil
+}
+
+func (p *PostgresTokenRepository) RevokeSession(ctx context.Context, accessID string, accessExpiresAt time.Time) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM revoked_tokens WHERE expires_at < CURRENT_TIMESTAMP")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		"INSERT INTO revoked_tokens (access_id, expires_at) VALUES ($1, $2) ON CONFLICT (access_id) DO NOTHING",
+		accessID,
+		accessExpiresAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		"UPDATE refresh_tokens SET revoked_at = CURRENT_TIMESTAMP WHERE access_id = $1 AND revoked_at IS NULL",
+		accessID,
+	)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (p *PostgresTokenRepository) IsRevoked(ctx context.Context, accessID string) (bool, error) {
+	var accessIDFromDB string
+	err := p.db.QueryRowContext(ctx, "SELECT access_id FROM revoked_tokens WHERE access_id = $1", accessID).Scan(&accessIDFromDB)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func (p *PostgresTokenRepository) RevokeUserSessions(ctx context.Context, login string) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err = revokeUserSessions(ctx, tx, login); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// revokeUserSessions also revokes access tokens of already rotated refresh tokens,
+// they stay valid until their own expiry otherwise.
+func revokeUserSessions(ctx context.Context, tx *sql.Tx, login string) error {
+	_, err := tx.ExecContext(
+		ctx,
+		`INSERT INTO revoked_tokens (access_id, expires_at)
+         SELECT access_id, expires_at FROM refresh_tokens WHERE login = $1 AND expires_at > CURRENT_TIMESTAMP
+         ON CONFLICT (access_id) DO NOTHING`,
+		login,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		"UPDATE refresh_tokens SET revoked_at = CURRENT_TIMESTAMP WHERE login = $1 AND revoked_at IS NULL",
+		login,
+	)
+	return err
+}
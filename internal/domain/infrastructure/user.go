@@ -7,7 +7,10 @@ import (
 	"fmt"
 	"github.com/Aleksei-D/go-loyalty-system/internal/logger"
 	"github.com/Aleksei-D/go-loyalty-system/internal/models"
+	"github.com/Aleksei-D/go-loyalty-system/internal/utils/common"
+	"github.com/lib/pq"
 	"go.uber.org/zap"
+	"time"
 )
 
 type PostgresUserRepository struct {
@@ -25,18 +28,28 @@ func (p *PostgresUserRepository) Create(ctx context.Context, user *models.User)
 	}
 	defer tx.Rollback()
 
-	userCreateQuery := `INSERT INTO users (login, password) VALUES ($1, $2)`
+	userCreateQuery := `INSERT INTO users (login, password, roles, referral_code) VALUES ($1, $2, $3, NULLIF($4, ''))`
 	stmtUser, err := tx.Prepare(userCreateQuery)
 	if err != nil {
 		return nil, err
 	}
 	defer stmtUser.Close()
 
-	_, err = stmtUser.ExecContext(ctx, user.Login, user.Password)
+	if len(user.Roles) == 0 {
+		user.Roles = []models.Role{models.RoleUser}
+	}
+	_, err = stmtUser.ExecContext(ctx, user.Login, user.Password, pq.Array(models.RolesToStrings(user.Roles)), user.ReferralCode)
 	if err != nil {
 		return nil, err
 	}
 
+	if user.ReferredBy != "" {
+		_, err = tx.ExecContext(ctx, "INSERT INTO referrals (referee, referrer) VALUES ($1, $2)", user.Login, user.ReferredBy)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	balanceCreateQuery := `INSERT INTO balance (login) VALUES ($1)`
 	stmtBalance, err := tx.Prepare(balanceCreateQuery)
 	if err != nil {
@@ -60,7 +73,16 @@ func (p *PostgresUserRepository) GetByLogin(ctx context.Context, login string) (
 	var user models.User
 	var loginFromDB string
 	var password string
-	err := p.db.QueryRowContext(ctx, "SELECT login, password FROM users WHERE login = $1", login).Scan(&loginFromDB, &password)
+	var lockedUntil sql.NullTime
+	var roles []string
+	var blockedAt sql.NullTime
+	var tier sql.NullString
+	var referralCode sql.NullString
+	err := p.db.QueryRowContext(
+		ctx,
+		"SELECT login, password, locked_until, roles, blocked_at, tier, referral_code FROM users WHERE login = $1",
+		login,
+	).Scan(&loginFromDB, &password, &lockedUntil, pq.Array(&roles), &blockedAt, &tier, &referralCode)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			logger.Log.Info(fmt.Sprintf("user - %s not found", login), zap.Error(err))
@@ -71,6 +93,15 @@ func (p *PostgresUserRepository) GetByLogin(ctx context.Context, login string) (
 
 	user.Login = loginFromDB
 	user.Password = password
+	if lockedUntil.Valid {
+		user.LockedUntil = &lockedUntil.Time
+	}
+	user.Roles = models.RolesFromStrings(roles)
+	if blockedAt.Valid {
+		user.BlockedAt = &blockedAt.Time
+	}
+	user.Tier = tier.String
+	user.ReferralCode = referralCode.String
 	return &user, err
 }
 
@@ -88,3 +119,135 @@ func (p *PostgresUserRepository) IsExist(ctx context.Context, login string) (boo
 
 	return true, nil
 }
+
+func (p *PostgresUserRepository) RegisterFailedLogin(ctx context.Context, login string, maxAttempts uint, lockout time.Duration) (*time.Time, error) {
+	query := `UPDATE users SET
+		failed_logins = CASE WHEN failed_logins + 1 >= $2 THEN 0 ELSE failed_logins + 1 END,
+		locked_until = CASE WHEN failed_logins + 1 >= $2 THEN now() + make_interval(secs => $3) ELSE locked_until END
+		WHERE login = $1
+		RETURNING locked_until`
+	var lockedUntil sql.NullTime
+	err := p.db.QueryRowContext(ctx, query, login, maxAttempts, lockout.Seconds()).Scan(&lockedUntil)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if !lockedUntil.Valid {
+		return nil, nil
+	}
+	return &lockedUntil.Time, nil
+}
+
+func (p *PostgresUserRepository) ResetFailedLogins(ctx context.Context, login string) error {
+	_, err := p.db.ExecContext(ctx, "UPDATE users SET failed_logins = 0, locked_until = NULL WHERE login = $1", login)
+	return err
+}
+
+func (p *PostgresUserRepository) UpdatePassword(ctx context.Context, login, passwordHash string) error {
+	_, err := p.db.ExecContext(
+		ctx,
+		"UPDATE users SET password = $1, failed_logins = 0, locked_until = NULL WHERE login = $2",
+		passwordHash,
+		login,
+	)
+	return err
+}
+
+func (p *PostgresUserRepository) GetAll(ctx context.Context) ([]*models.User, error) {
+	users := make([]*models.User, 0)
+	rows, err := p.db.QueryContext(ctx, "SELECT login, roles, blocked_at, tier FROM users ORDER BY login")
+	if err != nil {
+		return users, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var user models.User
+		var roles []string
+		var blockedAt sql.NullTime
+		var tier sql.NullString
+		if err = rows.Scan(&user.Login, pq.Array(&roles), &blockedAt, &tier); err != nil {
+			return users, err
+		}
+		user.Tier = tier.String
+
+		user.Roles = models.RolesFromStrings(roles)
+		if blockedAt.Valid {
+			user.BlockedAt = &blockedAt.Time
+		}
+		users = append(users, &user)
+	}
+	return users, rows.Err()
+}
+
+func (p *PostgresUserRepository) SetBlocked(ctx context.Context, login string, blocked bool) error {
+	query := "UPDATE users SET blocked_at = NULL WHERE login = $1"
+	if blocked {
+		query = "UPDATE users SET blocked_at = COALESCE(blocked_at, CURRENT_TIMESTAMP) WHERE login = $1"
+	}
+
+	result, err := p.db.ExecContext(ctx, query, login)
+	if err != nil {
+		return err
+	}
+	return userAffected(result)
+}
+
+func (p *PostgresUserRepository) GrantRole(ctx context.Context, login string, role models.Role) error {
+	result, err := p.db.ExecContext(
+		ctx,
+		`UPDATE users SET roles = CASE WHEN $2 = ANY(roles) THEN roles ELSE array_append(roles, $2) END
+         WHERE login = $1`,
+		login,
+		string(role),
+	)
+	if err != nil {
+		return err
+	}
+	return userAffected(result)
+}
+
+func (p *PostgresUserRepository) SetTier(ctx context.Context, login, tier string) error {
+	result, err := p.db.ExecContext(
+		ctx,
+		"UPDATE users SET tier = NULLIF($2, ''), tier_updated_at = CURRENT_TIMESTAMP WHERE login = $1",
+		login,
+		tier,
+	)
+	if err != nil {
+		return err
+	}
+	return userAffected(result)
+}
+
+func (p *PostgresUserRepository) GetByReferralCode(ctx context.Context, code string) (*models.User, error) {
+	var user models.User
+	var blockedAt sql.NullTime
+	err := p.db.QueryRowContext(
+		ctx, "SELECT login, referral_code, blocked_at FROM users WHERE referral_code = $1", code,
+	).Scan(&user.Login, &user.ReferralCode, &blockedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, common.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if blockedAt.Valid {
+		user.BlockedAt = &blockedAt.Time
+	}
+	return &user, nil
+}
+
+func userAffected(result sql.Result) error {
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return common.ErrUserNotFound
+	}
+	return nil
+}
@@ -0,0 +1,251 @@
+package infrastructure
+
+import (
+	"context"
+	"database/sql"
+	"github.com/Aleksei-D/go-loyalty-system/internal/models"
+	"github.com/Aleksei-D/go-loyalty-system/internal/utils/common"
+	"github.com/lib/pq"
+	"sort"
+	"time"
+)
+
+type PostgresWebhookRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresWebhookRepository(db *sql.DB) *PostgresWebhookRepository {
+	return &PostgresWebhookRepository{db: db}
+}
+
+func (p *PostgresWebhookRepository) CreateSubscription(ctx context.Context, subscription *models.WebhookSubscription) error {
+	eventTypes := make([]string, 0, len(subscription.EventTypes))
+	for _, eventType := range subscription.EventTypes {
+		eventTypes = append(eventTypes, string(eventType))
+	}
+
+	var createdAt time.Time
+	err := p.db.QueryRowContext(
+		ctx,
+		"INSERT INTO webhook_subscriptions (id, url, secret, event_types) VALUES ($1, $2, $3, $4) RETURNING created_at",
+		subscription.ID,
+		subscription.URL,
+		subscription.Secret,
+		pq.Array(eventTypes),
+	).Scan(&createdAt)
+	subscription.CreatedAt = models.CustomTime{Time: createdAt}
+	return err
+}
+
+func (p *PostgresWebhookRepository) GetSubscriptions(ctx context.Context) ([]*models.WebhookSubscription, error) {
+	subscriptions := make([]*models.WebhookSubscription, 0)
+	rows, err := p.db.QueryContext(
+		ctx,
+		"SELECT id, url, secret, event_types, created_at FROM webhook_subscriptions ORDER BY created_at, id",
+	)
+	if err != nil {
+		return subscriptions, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var subscription models.WebhookSubscription
+		var eventTypes []string
+		var createdAt time.Time
+		err = rows.Scan(&subscription.ID, &subscription.URL, &subscription.Secret, pq.Array(&eventTypes), &createdAt)
+		if err != nil {
+			return subscriptions, err
+		}
+
+		subscription.EventTypes = make([]models.EventType, 0, len(eventTypes))
+		for _, eventType := range eventTypes {
+			subscription.EventTypes = append(subscription.EventTypes, models.EventType(eventType))
+		}
+		subscription.CreatedAt = models.CustomTime{Time: createdAt}
+		subscriptions = append(subscriptions, &subscription)
+	}
+	return subscriptions, rows.Err()
+}
+
+func (p *PostgresWebhookRepository) DeleteSubscription(ctx context.Context, id string) error {
+	result, err := p.db.ExecContext(ctx, "DELETE FROM webhook_subscriptions WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return common.ErrWebhookNotFound
+	}
+	return nil
+}
+
+// Enqueue skips deliveries that already exist, so an event dispatched twice is still sent once per subscription.
+func (p *PostgresWebhookRepository) Enqueue(ctx context.Context, deliveries []*models.WebhookDelivery) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, delivery := range deliveries {
+		_, err = tx.ExecContext(
+			ctx,
+			`INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, payload) VALUES ($1, $2, $3, $4)
+             ON CONFLICT (subscription_id, event_id) DO NOTHING`,
+			delivery.SubscriptionID,
+			delivery.EventID,
+			delivery.EventType,
+			[]byte(delivery.Payload),
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+const webhookDeliveryColumns = `d.id, d.subscription_id, d.event_id, d.event_type, d.payload, d.status, d.attempts,
+    COALESCE(d.last_status_code, 0), COALESCE(d.last_error, ''), d.created_at, d.next_attempt_at, d.delivered_at`
+
+func scanWebhookDelivery(row rowScanner, dest ...any) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	var createdAt, nextAttemptAt time.Time
+	var deliveredAt sql.NullTime
+	err := row.Scan(append([]any{
+		&delivery.ID,
+		&delivery.SubscriptionID,
+		&delivery.EventID,
+		&delivery.EventType,
+		&delivery.Payload,
+		&delivery.Status,
+		&delivery.Attempts,
+		&delivery.LastStatusCode,
+		&delivery.LastError,
+		&createdAt,
+		&nextAttemptAt,
+		&deliveredAt,
+	}, dest...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	delivery.CreatedAt = models.CustomTime{Time: createdAt}
+	if delivery.Status == models.WebhookDeliveryPending {
+		delivery.NextAttemptAt = &models.CustomTime{Time: nextAttemptAt}
+	}
+	if deliveredAt.Valid {
+		delivery.DeliveredAt = &models.CustomTime{Time: deliveredAt.Time}
+	}
+	return &delivery, nil
+}
+
+func (p *PostgresWebhookRepository) ClaimDeliveries(ctx context.Context, limit uint, lease time.Duration) ([]*models.WebhookDelivery, error) {
+	deliveries := make([]*models.WebhookDelivery, 0)
+	query := `WITH claimed AS (
+                  UPDATE webhook_deliveries SET locked_until = now() + make_interval(secs => $2)
+                  WHERE id IN (
+                      SELECT id FROM webhook_deliveries
+                      WHERE status = 'PENDING' AND next_attempt_at <= now() AND (locked_until IS NULL OR locked_until <= now())
+                      ORDER BY next_attempt_at, id LIMIT $1 FOR UPDATE SKIP LOCKED
+                  )
+                  RETURNING *
+              )
+              SELECT ` + webhookDeliveryColumns + `, s.url, s.secret FROM claimed d
+              JOIN webhook_subscriptions s ON s.id = d.subscription_id`
+	rows, err := p.db.QueryContext(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		return deliveries, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var url, secret string
+		delivery, err := scanWebhookDelivery(rows, &url, &secret)
+		if err != nil {
+			return deliveries, err
+		}
+		delivery.URL = url
+		delivery.Secret = secret
+		deliveries = append(deliveries, delivery)
+	}
+	sort.Slice(deliveries, func(i, j int) bool {
+		return deliveries[i].ID < deliveries[j].ID
+	})
+	return deliveries, rows.Err()
+}
+
+func (p *PostgresWebhookRepository) SaveAttempt(ctx context.Context, delivery *models.WebhookDelivery) error {
+	var nextAttemptAt, deliveredAt *time.Time
+	if delivery.NextAttemptAt != nil {
+		nextAttemptAt = &delivery.NextAttemptAt.Time
+	}
+	if delivery.DeliveredAt != nil {
+		deliveredAt = &delivery.DeliveredAt.Time
+	}
+
+	_, err := p.db.ExecContext(
+		ctx,
+		`UPDATE webhook_deliveries SET status = $2, attempts = $3, last_status_code = NULLIF($4, 0),
+             last_error = NULLIF($5, ''), next_attempt_at = COALESCE($6, next_attempt_at), delivered_at = $7,
+             locked_until = NULL
+         WHERE id = $1`,
+		delivery.ID,
+		delivery.Status,
+		delivery.Attempts,
+		delivery.LastStatusCode,
+		delivery.LastError,
+		nextAttemptAt,
+		deliveredAt,
+	)
+	return err
+}
+
+func (p *PostgresWebhookRepository) GetDeliveries(
+	ctx context.Context,
+	subscriptionID string,
+	status models.WebhookDeliveryStatus,
+) ([]*models.WebhookDelivery, error) {
+	deliveries := make([]*models.WebhookDelivery, 0)
+	query := `SELECT ` + webhookDeliveryColumns + ` FROM webhook_deliveries d
+              WHERE d.subscription_id = $1 AND ($2 = '' OR d.status = $2)
+              ORDER BY d.id DESC`
+	rows, err := p.db.QueryContext(ctx, query, subscriptionID, status)
+	if err != nil {
+		return deliveries, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return deliveries, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, rows.Err()
+}
+
+// Requeue gives a dead delivery a fresh set of attempts.
+func (p *PostgresWebhookRepository) Requeue(ctx context.Context, subscriptionID string, id int64) error {
+	result, err := p.db.ExecContext(
+		ctx,
+		`UPDATE webhook_deliveries SET status = 'PENDING', attempts = 0, next_attempt_at = now(), locked_until = NULL
+         WHERE id = $1 AND subscription_id = $2 AND status = 'DEAD'`,
+		id,
+		subscriptionID,
+	)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return common.ErrWebhookDeliveryNotFound
+	}
+	return nil
+}
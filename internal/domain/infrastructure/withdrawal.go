@@ -20,33 +20,31 @@ func NewPostgresWithdrawalRepository(db *sql.DB) *PostgresWithdrawalRepository {
 	return &PostgresWithdrawalRepository{db: db}
 }
 
-func (p *PostgresWithdrawalRepository) GetAllByLogin(ctx context.Context, login string) ([]*models.Withdrawal, error) {
+func (p *PostgresWithdrawalRepository) GetAllByLogin(ctx context.Context, login string, filter *models.ListFilter) ([]*models.Withdrawal, error) {
 	withdrawals := make([]*models.Withdrawal, 0)
-	query := `SELECT order_number, sum, processed_at FROM withdrawals WHERE login = $1 ORDER BY processed_at DESC`
-	stmt, err := p.db.Prepare(query)
-	if err != nil {
-		return withdrawals, err
+	args := []any{login}
+	selectQuery := "SELECT order_number, sum, status, processed_at, refunded_at FROM withdrawals WHERE login = $1"
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		selectQuery += " AND status = $2"
 	}
+	query, args := listQuery(selectQuery, "processed_at", "order_number", filter, args)
 
-	rows, err := stmt.QueryContext(ctx, login)
+	rows, err := p.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return withdrawals, nil
-		}
 		logger.Log.Info(err.Error(), zap.Error(err))
 		return withdrawals, err
 	}
-	if rows.Err() != nil {
-		return withdrawals, rows.Err()
-	}
 	defer rows.Close()
 
 	for rows.Next() {
 		var processedAt time.Time
+		var refundedAt sql.NullTime
 		var order string
-		var sum float64
+		var sum models.Money
+		var status string
 		var withdrawal models.Withdrawal
-		err := rows.Scan(&order, &sum, &processedAt)
+		err := rows.Scan(&order, &sum, &status, &processedAt, &refundedAt)
 		if err != nil {
 			return withdrawals, err
 		}
@@ -55,9 +53,13 @@ func (p *PostgresWithdrawalRepository) GetAllByLogin(ctx context.Context, login
 		withdrawal.OrderNumber = order
 		withdrawal.ProcessedAt = models.CustomTime{Time: processedAt}
 		withdrawal.Sum = sum
+		withdrawal.Status = models.WithdrawalStatus(status)
+		if refundedAt.Valid {
+			withdrawal.RefundedAt = &models.CustomTime{Time: refundedAt.Time}
+		}
 		withdrawals = append(withdrawals, &withdrawal)
 	}
-	return withdrawals, nil
+	return withdrawals, rows.Err()
 }
 
 func (p *PostgresWithdrawalRepository) Withdraw(ctx context.Context, withdraw *models.Withdrawal) error {
@@ -67,13 +69,14 @@ func (p *PostgresWithdrawalRepository) Withdraw(ctx context.Context, withdraw *m
 	}
 	defer tx.Rollback()
 
-	var currentBalance float64
-	err = tx.QueryRowContext(ctx, "SELECT current FROM balance WHERE login = $1", withdraw.Login).Scan(&currentBalance)
+	err = lockUserAccount(ctx, tx, withdraw.Login)
+	if err != nil {
+		logger.Log.Info(err.Error(), zap.Error(err))
+		return err
+	}
+
+	currentBalance, err := accountBalance(ctx, tx, models.UserAccount(withdraw.Login))
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			logger.Log.Info(fmt.Sprintf("user balance - %s not found", withdraw.Login), zap.Error(err))
-			return err
-		}
 		return err
 	}
 
@@ -92,16 +95,25 @@ func (p *PostgresWithdrawalRepository) Withdraw(ctx context.Context, withdraw *m
 		return err
 	}
 
-	_, err = tx.ExecContext(
-		ctx,
-		"UPDATE balance SET current = current - $1 WHERE login = $2",
+	err = postLedgerTransaction(ctx, tx, models.NewTransfer(
+		models.PostingKindWithdrawal,
+		withdraw.OrderNumber,
+		models.UserAccount(withdraw.Login),
+		models.AccountWithdrawal,
 		withdraw.Sum,
-		withdraw.Login,
-	)
+	))
 	if err != nil {
 		return err
 	}
 
+	event, err := models.WithdrawalEvent(models.EventWithdrawalMade, withdraw)
+	if err != nil {
+		return err
+	}
+	if err = insertOutboxEvents(ctx, tx, event); err != nil {
+		return err
+	}
+
 	err = tx.Commit()
 	if err != nil {
 		return err
@@ -122,3 +134,69 @@ func (p *PostgresWithdrawalRepository) IsExist(ctx context.Context, withdraw *mo
 
 	return true, nil
 }
+
+func (p *PostgresWithdrawalRepository) Refund(ctx context.Context, login, orderNumber string) (*models.Withdrawal, error) {
+	tx, err := p.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	withdrawal := models.Withdrawal{Login: login, OrderNumber: orderNumber}
+	var status string
+	var processedAt time.Time
+	err = tx.QueryRowContext(
+		ctx,
+		"SELECT sum, status, processed_at FROM withdrawals WHERE order_number = $1 AND login = $2 FOR UPDATE",
+		orderNumber,
+		login,
+	).Scan(&withdrawal.Sum, &status, &processedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, common.ErrWithdrawalNotFound
+		}
+		return nil, err
+	}
+	if models.WithdrawalStatus(status) == models.WithdrawalStatusRefunded {
+		return nil, common.ErrWithdrawalRefunded
+	}
+
+	var refundedAt time.Time
+	err = tx.QueryRowContext(
+		ctx,
+		"UPDATE withdrawals SET status = $1, refunded_at = CURRENT_TIMESTAMP WHERE order_number = $2 RETURNING refunded_at",
+		models.WithdrawalStatusRefunded,
+		orderNumber,
+	).Scan(&refundedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	err = postLedgerTransaction(ctx, tx, models.NewTransfer(
+		models.PostingKindReversal,
+		orderNumber,
+		models.AccountWithdrawal,
+		models.UserAccount(login),
+		withdrawal.Sum,
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	event, err := models.WithdrawalEvent(models.EventWithdrawalRefunded, &withdrawal)
+	if err != nil {
+		return nil, err
+	}
+	if err = insertOutboxEvents(ctx, tx, event); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	withdrawal.Status = models.WithdrawalStatusRefunded
+	withdrawal.ProcessedAt = models.CustomTime{Time: processedAt}
+	withdrawal.RefundedAt = &models.CustomTime{Time: refundedAt}
+	return &withdrawal, nil
+}
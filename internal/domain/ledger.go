@@ -0,0 +1,15 @@
+package domain
+
+import (
+	"context"
+	"github.com/Aleksei-D/go-loyalty-system/internal/models"
+	"time"
+)
+
+type LedgerRepository interface {
+	GetEntriesByLogin(ctx context.Context, login string) ([]*models.LedgerEntry, error)
+	Adjust(ctx context.Context, adjustment *models.Adjustment) error
+	GetExpiryCandidates(ctx context.Context, earnedBefore time.Time) ([]string, error)
+	ExpirePoints(ctx context.Context, login string, ttl time.Duration) (models.Money, error)
+	AwardBonus(ctx context.Context, award *models.BonusAward, dailyCap models.Money) error
+}
@@ -0,0 +1,30 @@
+package memory
+
+import (
+	"context"
+	"github.com/Aleksei-D/go-loyalty-system/internal/models"
+)
+
+type BalanceRepository struct {
+	storage *Storage
+}
+
+func NewBalanceRepository(storage *Storage) *BalanceRepository {
+	return &BalanceRepository{storage: storage}
+}
+
+func (b *BalanceRepository) Get(_ context.Context, login string) (*models.Balance, error) {
+	var balance models.Balance
+	b.storage.mu.Lock()
+	defer b.storage.mu.Unlock()
+
+	if !b.storage.accounts[login] {
+		return &balance, nil
+	}
+
+	balance.Login = login
+	balance.Current = b.storage.accountBalance(models.UserAccount(login))
+	balance.Withdrawn = b.storage.accountWithdrawn(models.UserAccount(login), models.HoldAccount(login))
+	balance.Held = b.storage.accountBalance(models.HoldAccount(login))
+	return &balance, nil
+}
@@ -0,0 +1,38 @@
+package memory
+
+import (
+	"context"
+	"github.com/Aleksei-D/go-loyalty-system/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestBalanceRepository_Get(t *testing.T) {
+	ctx := context.Background()
+	storage := NewStorage()
+	_, err := NewUserRepository(storage).Create(ctx, &models.User{Login: "alice"})
+	require.NoError(t, err)
+
+	orderRepo := NewOrderRepository(storage)
+	_, err = orderRepo.Add(ctx, "alice", "12345678903")
+	require.NoError(t, err)
+	accrual := models.Money(50050)
+	require.NoError(t, orderRepo.UpdateStatus(ctx, &models.Order{
+		Number:  "12345678903",
+		Status:  models.OrderStatusProcessed,
+		Accrual: &accrual,
+	}))
+
+	err = NewWithdrawalRepository(storage).Withdraw(ctx, &models.Withdrawal{
+		Login:       "alice",
+		OrderNumber: "2377225624",
+		Sum:         4200,
+	})
+	require.NoError(t, err)
+
+	balance, err := NewBalanceRepository(storage).Get(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, models.Money(45850), balance.Current)
+	assert.Equal(t, models.Money(4200), balance.Withdrawn)
+}
@@ -0,0 +1,153 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"github.com/Aleksei-D/go-loyalty-system/internal/models"
+	"github.com/Aleksei-D/go-loyalty-system/internal/utils/common"
+	"sort"
+	"time"
+)
+
+type HoldRepository struct {
+	storage *Storage
+}
+
+func NewHoldRepository(storage *Storage) *HoldRepository {
+	return &HoldRepository{storage: storage}
+}
+
+func (h *HoldRepository) Create(_ context.Context, hold *models.Hold) error {
+	h.storage.mu.Lock()
+	defer h.storage.mu.Unlock()
+
+	if !h.storage.accounts[hold.Login] {
+		return sql.ErrNoRows
+	}
+
+	if h.storage.accountBalance(models.UserAccount(hold.Login)) < hold.Sum {
+		return common.ErrPaymentInsufficient
+	}
+
+	if _, ok := h.storage.withdrawals[hold.OrderNumber]; ok {
+		return common.ErrOrderAlreadyAdded
+	}
+	for _, existing := range h.storage.holds {
+		if existing.OrderNumber == hold.OrderNumber && existing.Status == models.HoldStatusHeld {
+			return common.ErrOrderAlreadyAdded
+		}
+	}
+
+	if err := h.storage.postLedgerTransaction(hold.HoldTransaction()); err != nil {
+		return err
+	}
+
+	hold.Status = models.HoldStatusHeld
+	hold.CreatedAt = models.CustomTime{Time: time.Now()}
+	holdCopy := *hold
+	h.storage.holds[hold.ID] = &holdCopy
+	return nil
+}
+
+func (h *HoldRepository) GetAllByLogin(_ context.Context, login string) ([]*models.Hold, error) {
+	holds := make([]*models.Hold, 0)
+	h.storage.mu.Lock()
+	defer h.storage.mu.Unlock()
+
+	for _, hold := range h.storage.holds {
+		if hold.Login != login {
+			continue
+		}
+		holdCopy := *hold
+		holds = append(holds, &holdCopy)
+	}
+
+	sort.Slice(holds, func(i, j int) bool {
+		return holds[i].CreatedAt.After(holds[j].CreatedAt.Time)
+	})
+	return holds, nil
+}
+
+func (h *HoldRepository) Capture(_ context.Context, login, id string) (*models.Hold, error) {
+	h.storage.mu.Lock()
+	defer h.storage.mu.Unlock()
+
+	hold, err := h.storage.activeHold(login, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := h.storage.withdrawals[hold.OrderNumber]; ok {
+		return nil, common.ErrOrderAlreadyAdded
+	}
+
+	if err = h.storage.postLedgerTransaction(hold.CaptureTransaction()); err != nil {
+		return nil, err
+	}
+
+	h.storage.withdrawals[hold.OrderNumber] = models.Withdrawal{
+		Login:       login,
+		OrderNumber: hold.OrderNumber,
+		Sum:         hold.Sum,
+		Status:      models.WithdrawalStatusCompleted,
+		ProcessedAt: models.CustomTime{Time: time.Now()},
+	}
+	hold.Status = models.HoldStatusCaptured
+	holdCopy := *hold
+	return &holdCopy, nil
+}
+
+func (h *HoldRepository) Release(_ context.Context, login, id string) (*models.Hold, error) {
+	h.storage.mu.Lock()
+	defer h.storage.mu.Unlock()
+
+	hold, err := h.storage.activeHold(login, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = h.storage.releaseHold(hold, models.HoldStatusReleased); err != nil {
+		return nil, err
+	}
+	holdCopy := *hold
+	return &holdCopy, nil
+}
+
+func (h *HoldRepository) ExpireHolds(_ context.Context, limit uint) (uint, error) {
+	h.storage.mu.Lock()
+	defer h.storage.mu.Unlock()
+
+	now := time.Now()
+	var expired uint
+	for _, hold := range h.storage.holds {
+		if expired >= limit {
+			break
+		}
+		if hold.Status != models.HoldStatusHeld || now.Before(hold.ExpiresAt.Time) {
+			continue
+		}
+		if err := h.storage.releaseHold(hold, models.HoldStatusExpired); err != nil {
+			return expired, err
+		}
+		expired++
+	}
+	return expired, nil
+}
+
+func (s *Storage) activeHold(login, id string) (*models.Hold, error) {
+	hold, ok := s.holds[id]
+	if !ok || hold.Login != login {
+		return nil, common.ErrHoldNotFound
+	}
+	if !hold.IsActive(time.Now()) {
+		return nil, common.ErrHoldNotActive
+	}
+	return hold, nil
+}
+
+func (s *Storage) releaseHold(hold *models.Hold, status models.HoldStatus) error {
+	if err := s.postLedgerTransaction(hold.ReleaseTransaction()); err != nil {
+		return err
+	}
+	hold.Status = status
+	return nil
+}
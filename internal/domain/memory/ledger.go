@@ -0,0 +1,131 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"github.com/Aleksei-D/go-loyalty-system/internal/models"
+	"github.com/Aleksei-D/go-loyalty-system/internal/utils/common"
+	"sort"
+	"time"
+)
+
+type LedgerRepository struct {
+	storage *Storage
+}
+
+func NewLedgerRepository(storage *Storage) *LedgerRepository {
+	return &LedgerRepository{storage: storage}
+}
+
+func (l *LedgerRepository) GetEntriesByLogin(_ context.Context, login string) ([]*models.LedgerEntry, error) {
+	l.storage.mu.Lock()
+	defer l.storage.mu.Unlock()
+
+	return l.storage.accountEntries(models.UserAccount(login)), nil
+}
+
+func (l *LedgerRepository) Adjust(_ context.Context, adjustment *models.Adjustment) error {
+	l.storage.mu.Lock()
+	defer l.storage.mu.Unlock()
+
+	if !l.storage.accounts[adjustment.Login] {
+		return sql.ErrNoRows
+	}
+
+	currentBalance := l.storage.accountBalance(models.UserAccount(adjustment.Login))
+	if currentBalance+adjustment.Amount < 0 {
+		return common.ErrNegativeBalance
+	}
+
+	adjustment.ID = int64(len(l.storage.adjustments) + 1)
+	adjustment.CreatedAt = models.CustomTime{Time: time.Now()}
+	if err := l.storage.postLedgerTransaction(adjustment.Transaction()); err != nil {
+		return err
+	}
+	l.storage.adjustments = append(l.storage.adjustments, *adjustment)
+	return nil
+}
+
+func (l *LedgerRepository) GetExpiryCandidates(_ context.Context, earnedBefore time.Time) ([]string, error) {
+	l.storage.mu.Lock()
+	defer l.storage.mu.Unlock()
+
+	logins := make([]string, 0)
+	for login := range l.storage.accounts {
+		account := models.UserAccount(login)
+		for _, record := range l.storage.ledger {
+			if !models.IsExpiringKind(record.transaction.Kind) || record.createdAt.After(earnedBefore) {
+				continue
+			}
+			if record.transaction.Touches(account) {
+				logins = append(logins, login)
+				break
+			}
+		}
+	}
+	sort.Strings(logins)
+	return logins, nil
+}
+
+func (l *LedgerRepository) AwardBonus(_ context.Context, award *models.BonusAward, dailyCap models.Money) error {
+	l.storage.mu.Lock()
+	defer l.storage.mu.Unlock()
+
+	if !l.storage.accounts[award.Login] {
+		return sql.ErrNoRows
+	}
+
+	account := models.UserAccount(award.Login)
+	day := models.BonusDay(time.Now())
+	var awardedToday models.Money
+	for _, record := range l.storage.ledger {
+		if record.transaction.Kind != models.PostingKindBonus {
+			continue
+		}
+		if record.transaction.Reference == award.OrderNumber {
+			return common.ErrBonusAwarded
+		}
+		if record.createdAt.Before(day) {
+			continue
+		}
+		for _, posting := range record.transaction.Postings {
+			if posting.Account == account {
+				awardedToday += posting.Amount
+			}
+		}
+	}
+
+	award.Cap(dailyCap, awardedToday)
+	if award.Amount == 0 {
+		return nil
+	}
+	return l.storage.postLedgerTransaction(award.Transaction())
+}
+
+func (l *LedgerRepository) ExpirePoints(_ context.Context, login string, ttl time.Duration) (models.Money, error) {
+	l.storage.mu.Lock()
+	defer l.storage.mu.Unlock()
+
+	if !l.storage.accounts[login] {
+		return 0, sql.ErrNoRows
+	}
+
+	now := time.Now()
+	entries := l.storage.accountEntries(models.UserAccount(login))
+	expired := models.ExpiredPoints(models.PointLots(entries, ttl), now)
+	if expired == 0 {
+		return 0, nil
+	}
+
+	err := l.storage.postLedgerTransaction(models.NewTransfer(
+		models.PostingKindExpiry,
+		now.Format(time.DateOnly),
+		models.UserAccount(login),
+		models.AccountExpiry,
+		expired,
+	))
+	if err != nil {
+		return 0, err
+	}
+	return expired, nil
+}
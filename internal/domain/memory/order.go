@@ -0,0 +1,292 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"github.com/Aleksei-D/go-loyalty-system/internal/models"
+	"github.com/Aleksei-D/go-loyalty-system/internal/utils/common"
+	"sort"
+	"time"
+)
+
+type OrderRepository struct {
+	storage *Storage
+}
+
+func NewOrderRepository(storage *Storage) *OrderRepository {
+	return &OrderRepository{storage: storage}
+}
+
+func (o *OrderRepository) Add(_ context.Context, login, orderNumber string) (*models.Order, error) {
+	o.storage.mu.Lock()
+	defer o.storage.mu.Unlock()
+
+	if _, ok := o.storage.orders[orderNumber]; ok {
+		return nil, fmt.Errorf("order - %s already exists", orderNumber)
+	}
+
+	uploadedAt := models.CustomTime{Time: time.Now()}
+	record := &orderRecord{
+		order: models.Order{
+			Login:      login,
+			Number:     orderNumber,
+			Status:     models.OrderStatusNew,
+			UploadedAt: uploadedAt,
+		},
+		history: []models.OrderStatusChange{
+			{Status: models.OrderStatusNew, ChangedAt: uploadedAt},
+		},
+	}
+	order := record.order
+	event, err := models.OrderAcceptedEvent(&order)
+	if err != nil {
+		return nil, err
+	}
+	o.storage.orders[orderNumber] = record
+	o.storage.appendEvents(event)
+	return &order, nil
+}
+
+func (o *OrderRepository) GetAllByLogin(_ context.Context, login string, filter *models.ListFilter) ([]*models.Order, error) {
+	orders := make([]*models.Order, 0)
+	o.storage.mu.Lock()
+	defer o.storage.mu.Unlock()
+
+	for _, record := range o.storage.orders {
+		if record.order.Login != login {
+			continue
+		}
+		if filter.Status != "" && string(record.order.Status) != filter.Status {
+			continue
+		}
+		if !filter.Contains(record.order.UploadedAt.Time, record.order.Number) {
+			continue
+		}
+		order := record.order
+		orders = append(orders, &order)
+	}
+
+	sort.Slice(orders, func(i, j int) bool {
+		if orders[i].UploadedAt.Equal(orders[j].UploadedAt.Time) {
+			return orders[i].Number > orders[j].Number
+		}
+		return orders[i].UploadedAt.After(orders[j].UploadedAt.Time)
+	})
+	if filter.Limit > 0 && uint(len(orders)) > filter.Limit {
+		orders = orders[:filter.Limit]
+	}
+	return orders, nil
+}
+
+func (o *OrderRepository) GetOrderByNumber(_ context.Context, orderNumber string) (*models.Order, error) {
+	o.storage.mu.Lock()
+	defer o.storage.mu.Unlock()
+
+	record, ok := o.storage.orders[orderNumber]
+	if !ok {
+		return nil, common.ErrOrderNotFound
+	}
+	order := record.order
+	if !record.updateAt.IsZero() {
+		order.CheckedAt = &models.CustomTime{Time: record.updateAt}
+	}
+	return &order, nil
+}
+
+func (o *OrderRepository) ClaimOrders(_ context.Context, claim *models.OrderClaim) ([]*models.Order, error) {
+	orders := make([]*models.Order, 0)
+	o.storage.mu.Lock()
+	defer o.storage.mu.Unlock()
+
+	due := make([]*orderRecord, 0)
+	now := time.Now()
+	for _, record := range o.storage.orders {
+		switch record.order.Status {
+		case models.OrderStatusNew, models.OrderStatusProcessing:
+		default:
+			continue
+		}
+		if !record.parkedAt.IsZero() || record.leaseExpiresAt.After(now) {
+			continue
+		}
+		if !record.updateAt.IsZero() && !record.updateAt.Add(claim.UpdateTimeout).Before(now) {
+			continue
+		}
+		due = append(due, record)
+	}
+	sort.Slice(due, func(i, j int) bool {
+		if due[i].updateAt.Equal(due[j].updateAt) {
+			return due[i].order.Number < due[j].order.Number
+		}
+		return due[i].updateAt.Before(due[j].updateAt)
+	})
+	if uint(len(due)) > claim.Limit {
+		due = due[:claim.Limit]
+	}
+
+	for _, record := range due {
+		if claim.Exhausted(record.claimAttempts) {
+			record.parkedAt = now
+			event, err := models.OrderParkedEvent(record.parked())
+			if err != nil {
+				return orders, err
+			}
+			record.claimedBy = ""
+			record.leaseExpiresAt = time.Time{}
+			o.storage.appendEvents(event)
+			continue
+		}
+
+		record.claimedBy = claim.Worker
+		record.leaseExpiresAt = now.Add(claim.Lease)
+		record.claimAttempts++
+		record.updateAt = now
+		orders = append(orders, &models.Order{Number: record.order.Number})
+	}
+	return orders, nil
+}
+
+func (o *OrderRepository) GetParkedOrders(_ context.Context) ([]*models.ParkedOrder, error) {
+	orders := make([]*models.ParkedOrder, 0)
+	o.storage.mu.Lock()
+	defer o.storage.mu.Unlock()
+
+	for _, record := range o.storage.orders {
+		if !record.parkedAt.IsZero() {
+			orders = append(orders, record.parked())
+		}
+	}
+	sort.Slice(orders, func(i, j int) bool {
+		if orders[i].ParkedAt.Equal(orders[j].ParkedAt.Time) {
+			return orders[i].Number < orders[j].Number
+		}
+		return orders[i].ParkedAt.Before(orders[j].ParkedAt.Time)
+	})
+	return orders, nil
+}
+
+func (o *OrderRepository) UnparkOrder(_ context.Context, orderNumber string) error {
+	o.storage.mu.Lock()
+	defer o.storage.mu.Unlock()
+
+	record, ok := o.storage.orders[orderNumber]
+	if !ok {
+		return common.ErrOrderNotFound
+	}
+	if record.parkedAt.IsZero() {
+		return common.ErrOrderNotParked
+	}
+	record.parkedAt = time.Time{}
+	record.claimAttempts = 0
+	record.updateAt = time.Time{}
+	return nil
+}
+
+func (o *OrderRepository) UpdateStatus(_ context.Context, order *models.Order) error {
+	o.storage.mu.Lock()
+	defer o.storage.mu.Unlock()
+
+	record, ok := o.storage.orders[order.Number]
+	if !ok {
+		return common.ErrOrderNotFound
+	}
+
+	currentStatus := record.order.Status
+	changed, err := currentStatus.CheckTransition(order.Status)
+	if err != nil {
+		return err
+	}
+	if !changed {
+		if !currentStatus.IsFinal() {
+			record.releaseClaim(time.Now())
+		}
+		return nil
+	}
+
+	events, err := models.OrderUpdateEvents(record.order.Login, currentStatus, order)
+	if err != nil {
+		return err
+	}
+
+	if order.Status == models.OrderStatusProcessed && order.Accrual != nil {
+		err := o.storage.postLedgerTransaction(models.NewTransfer(
+			models.PostingKindAccrual,
+			order.Number,
+			models.AccountAccrual,
+			models.UserAccount(record.order.Login),
+			*order.Accrual,
+		))
+		if err != nil {
+			return err
+		}
+	}
+
+	record.releaseClaim(time.Now())
+	record.order.Status = order.Status
+	record.order.Accrual = nil
+	if order.Accrual != nil {
+		accrual := *order.Accrual
+		record.order.Accrual = &accrual
+	}
+	record.history = append(record.history, models.OrderStatusChange{
+		PreviousStatus: &currentStatus,
+		Status:         order.Status,
+		ChangedAt:      models.CustomTime{Time: time.Now()},
+	})
+	o.storage.appendEvents(events...)
+	return nil
+}
+
+func (o *OrderRepository) GetStatusHistory(_ context.Context, orderNumber string) ([]*models.OrderStatusChange, error) {
+	history := make([]*models.OrderStatusChange, 0)
+	o.storage.mu.Lock()
+	defer o.storage.mu.Unlock()
+
+	record, ok := o.storage.orders[orderNumber]
+	if !ok {
+		return history, nil
+	}
+
+	for _, change := range record.history {
+		change := change
+		history = append(history, &change)
+	}
+	return history, nil
+}
+
+func (o *OrderRepository) IsExist(_ context.Context, orderNumber string) (bool, error) {
+	o.storage.mu.Lock()
+	defer o.storage.mu.Unlock()
+
+	_, ok := o.storage.orders[orderNumber]
+	return ok, nil
+}
+
+func (o *OrderRepository) GetAccruedTotal(_ context.Context, login string, since time.Time) (models.Money, error) {
+	o.storage.mu.Lock()
+	defer o.storage.mu.Unlock()
+
+	var total models.Money
+	for _, record := range o.storage.orders {
+		if record.order.Login != login || record.order.Status != models.OrderStatusProcessed || record.order.Accrual == nil {
+			continue
+		}
+		for _, change := range record.history {
+			if change.Status == models.OrderStatusProcessed && !change.ChangedAt.Time.Before(since) {
+				total += *record.order.Accrual
+				break
+			}
+		}
+	}
+	return total, nil
+}
+
+func (r *orderRecord) parked() *models.ParkedOrder {
+	return &models.ParkedOrder{
+		Login:    r.order.Login,
+		Number:   r.order.Number,
+		Status:   r.order.Status,
+		Attempts: r.claimAttempts,
+		ParkedAt: models.CustomTime{Time: r.parkedAt},
+	}
+}
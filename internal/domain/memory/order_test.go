@@ -0,0 +1,109 @@
+package memory
+
+import (
+	"context"
+	"github.com/Aleksei-D/go-loyalty-system/internal/models"
+	"github.com/Aleksei-D/go-loyalty-system/internal/utils/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func claimedNumbers(t *testing.T, repo *OrderRepository, limit, updateTimeout uint) []string {
+	t.Helper()
+	return claimOrders(t, repo, &models.OrderClaim{
+		Worker:        "worker-1",
+		Limit:         limit,
+		UpdateTimeout: time.Duration(updateTimeout) * time.Second,
+		Lease:         time.Minute,
+	})
+}
+
+func claimOrders(t *testing.T, repo *OrderRepository, claim *models.OrderClaim) []string {
+	t.Helper()
+	orders, err := repo.ClaimOrders(context.Background(), claim)
+	require.NoError(t, err)
+
+	numbers := make([]string, 0, len(orders))
+	for _, order := range orders {
+		numbers = append(numbers, order.Number)
+	}
+	return numbers
+}
+
+func TestOrderRepository_ClaimOrders(t *testing.T) {
+	ctx := context.Background()
+	storage := NewStorage()
+	_, err := NewUserRepository(storage).Create(ctx, &models.User{Login: "alice"})
+	require.NoError(t, err)
+	repo := NewOrderRepository(storage)
+
+	for _, number := range []string{"12345678903", "9278923470", "346436439"} {
+		_, err = repo.Add(ctx, "alice", number)
+		require.NoError(t, err)
+	}
+	require.NoError(t, repo.UpdateStatus(ctx, &models.Order{Number: "346436439", Status: models.OrderStatusInvalid}))
+
+	first := claimedNumbers(t, repo, 1, 0)
+	require.Len(t, first, 1)
+
+	second := claimedNumbers(t, repo, 10, 0)
+	require.Len(t, second, 1)
+	assert.NotEqual(t, first[0], second[0], "claimed order must not be handed out twice")
+	assert.ElementsMatch(t, []string{"12345678903", "9278923470"}, append(first, second...))
+
+	assert.Empty(t, claimedNumbers(t, repo, 10, 0), "final and claimed orders must be skipped")
+
+	require.NoError(t, repo.UpdateStatus(ctx, &models.Order{Number: first[0], Status: models.OrderStatusProcessing}))
+	assert.Empty(t, claimedNumbers(t, repo, 10, 3600), "released order must wait for update timeout")
+	assert.Equal(t, first, claimedNumbers(t, repo, 10, 0))
+
+	accrual := models.Money(500)
+	require.NoError(t, repo.UpdateStatus(ctx, &models.Order{Number: second[0], Status: models.OrderStatusProcessed, Accrual: &accrual}))
+	assert.Empty(t, claimedNumbers(t, repo, 10, 0))
+}
+
+func TestOrderRepository_ClaimOrdersLease(t *testing.T) {
+	ctx := context.Background()
+	storage := NewStorage()
+	_, err := NewUserRepository(storage).Create(ctx, &models.User{Login: "alice"})
+	require.NoError(t, err)
+	repo := NewOrderRepository(storage)
+	_, err = repo.Add(ctx, "alice", "12345678903")
+	require.NoError(t, err)
+
+	claim := &models.OrderClaim{Worker: "worker-1", Limit: 10, Lease: 50 * time.Millisecond, MaxAttempts: 2}
+	assert.Equal(t, []string{"12345678903"}, claimOrders(t, repo, claim))
+	assert.Empty(t, claimOrders(t, repo, claim), "a leased order is not handed out again")
+
+	// The worker never reported a status, so the order is reclaimed once the lease expires.
+	time.Sleep(60 * time.Millisecond)
+	assert.Equal(t, []string{"12345678903"}, claimOrders(t, repo, claim))
+	time.Sleep(60 * time.Millisecond)
+	assert.Empty(t, claimOrders(t, repo, claim), "exhausted order is parked instead of claimed")
+
+	parked, err := repo.GetParkedOrders(ctx)
+	require.NoError(t, err)
+	require.Len(t, parked, 1)
+	assert.Equal(t, "12345678903", parked[0].Number)
+	assert.Equal(t, "alice", parked[0].Login)
+	assert.Equal(t, uint(2), parked[0].Attempts)
+	assert.Equal(t, models.EventOrderParked, storage.outbox[len(storage.outbox)-1].event.Type)
+	time.Sleep(60 * time.Millisecond)
+	assert.Empty(t, claimOrders(t, repo, claim), "parked order stays parked")
+
+	assert.ErrorIs(t, repo.UnparkOrder(ctx, "9278923470"), common.ErrOrderNotFound)
+	require.NoError(t, repo.UnparkOrder(ctx, "12345678903"))
+	assert.ErrorIs(t, repo.UnparkOrder(ctx, "12345678903"), common.ErrOrderNotParked)
+	assert.Equal(t, []string{"12345678903"}, claimOrders(t, repo, claim))
+
+	// A status report ends the lease and resets the attempts.
+	require.NoError(t, repo.UpdateStatus(ctx, &models.Order{Number: "12345678903", Status: models.OrderStatusProcessing}))
+	assert.Equal(t, []string{"12345678903"}, claimOrders(t, repo, claim))
+	time.Sleep(60 * time.Millisecond)
+	assert.Equal(t, []string{"12345678903"}, claimOrders(t, repo, claim))
+	parked, err = repo.GetParkedOrders(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, parked)
+}
@@ -0,0 +1,67 @@
+package memory
+
+import (
+	"context"
+	"github.com/Aleksei-D/go-loyalty-system/internal/models"
+	"time"
+)
+
+type OutboxRepository struct {
+	storage *Storage
+}
+
+func NewOutboxRepository(storage *Storage) *OutboxRepository {
+	return &OutboxRepository{storage: storage}
+}
+
+func (o *OutboxRepository) Claim(_ context.Context, limit uint, lease time.Duration) ([]*models.Event, error) {
+	o.storage.mu.Lock()
+	defer o.storage.mu.Unlock()
+
+	now := time.Now()
+	events := make([]*models.Event, 0)
+	for _, record := range o.storage.outbox {
+		if uint(len(events)) >= limit {
+			break
+		}
+		if record.delivered || record.nextAttemptAt.After(now) || record.lockedUntil.After(now) {
+			continue
+		}
+		record.lockedUntil = now.Add(lease)
+		event := record.event
+		events = append(events, &event)
+	}
+	return events, nil
+}
+
+func (o *OutboxRepository) MarkDelivered(_ context.Context, id int64) error {
+	o.storage.mu.Lock()
+	defer o.storage.mu.Unlock()
+
+	if record := o.storage.outboxRecord(id); record != nil {
+		record.delivered = true
+		record.lockedUntil = time.Time{}
+		record.lastError = ""
+	}
+	return nil
+}
+
+func (o *OutboxRepository) MarkFailed(_ context.Context, id int64, retryAt time.Time, reason string) error {
+	o.storage.mu.Lock()
+	defer o.storage.mu.Unlock()
+
+	if record := o.storage.outboxRecord(id); record != nil {
+		record.event.Attempts++
+		record.nextAttemptAt = retryAt
+		record.lockedUntil = time.Time{}
+		record.lastError = reason
+	}
+	return nil
+}
+
+func (s *Storage) outboxRecord(id int64) *outboxRecord {
+	if id < 1 || id > int64(len(s.outbox)) {
+		return nil
+	}
+	return s.outbox[id-1]
+}
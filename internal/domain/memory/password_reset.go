@@ -0,0 +1,50 @@
+package memory
+
+import (
+	"context"
+	"github.com/Aleksei-D/go-loyalty-system/internal/models"
+	"github.com/Aleksei-D/go-loyalty-system/internal/utils/common"
+	"time"
+)
+
+type resetTokenRecord struct {
+	token models.PasswordResetToken
+	used  bool
+}
+
+type PasswordResetRepository struct {
+	storage *Storage
+}
+
+func NewPasswordResetRepository(storage *Storage) *PasswordResetRepository {
+	return &PasswordResetRepository{storage: storage}
+}
+
+func (p *PasswordResetRepository) SaveResetToken(_ context.Context, token *models.PasswordResetToken) error {
+	p.storage.mu.Lock()
+	defer p.storage.mu.Unlock()
+
+	now := time.Now()
+	for hash, record := range p.storage.resetTokens {
+		if record.token.Login == token.Login || !record.token.ExpiresAt.After(now) {
+			delete(p.storage.resetTokens, hash)
+		}
+	}
+
+	p.storage.resetTokens[token.Hash] = &resetTokenRecord{token: *token}
+	return nil
+}
+
+func (p *PasswordResetRepository) ConsumeResetToken(_ context.Context, hash string) (*models.PasswordResetToken, error) {
+	p.storage.mu.Lock()
+	defer p.storage.mu.Unlock()
+
+	record, ok := p.storage.resetTokens[hash]
+	if !ok || record.used || !record.token.ExpiresAt.After(time.Now()) {
+		return nil, common.ErrInvalidResetToken
+	}
+
+	record.used = true
+	token := record.token
+	return &token, nil
+}
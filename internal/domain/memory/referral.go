@@ -0,0 +1,71 @@
+package memory
+
+import (
+	"context"
+	"github.com/Aleksei-D/go-loyalty-system/internal/models"
+	"github.com/Aleksei-D/go-loyalty-system/internal/utils/common"
+	"sort"
+)
+
+type ReferralRepository struct {
+	storage *Storage
+}
+
+func NewReferralRepository(storage *Storage) *ReferralRepository {
+	return &ReferralRepository{storage: storage}
+}
+
+func (r *ReferralRepository) GetAllByReferrer(_ context.Context, referrer string) ([]*models.Referral, error) {
+	r.storage.mu.Lock()
+	defer r.storage.mu.Unlock()
+
+	referrals := make([]*models.Referral, 0)
+	for _, referral := range r.storage.referrals {
+		if referral.Referrer == referrer {
+			referralCopy := *referral
+			referrals = append(referrals, &referralCopy)
+		}
+	}
+	sort.Slice(referrals, func(i, j int) bool {
+		if !referrals[i].CreatedAt.Equal(referrals[j].CreatedAt.Time) {
+			return referrals[i].CreatedAt.After(referrals[j].CreatedAt.Time)
+		}
+		return referrals[i].Referee < referrals[j].Referee
+	})
+	return referrals, nil
+}
+
+func (r *ReferralRepository) GetByReferee(_ context.Context, referee string) (*models.Referral, error) {
+	r.storage.mu.Lock()
+	defer r.storage.mu.Unlock()
+
+	referral, ok := r.storage.referrals[referee]
+	if !ok {
+		return nil, common.ErrReferralNotFound
+	}
+	referralCopy := *referral
+	return &referralCopy, nil
+}
+
+func (r *ReferralRepository) Reward(_ context.Context, reward *models.ReferralReward) error {
+	r.storage.mu.Lock()
+	defer r.storage.mu.Unlock()
+
+	referral, ok := r.storage.referrals[reward.Referee]
+	if !ok {
+		return common.ErrReferralNotFound
+	}
+	if referral.RewardedAt != nil {
+		return common.ErrReferralRewarded
+	}
+
+	if reward.ReferrerAmount+reward.RefereeAmount > 0 {
+		if err := r.storage.postLedgerTransaction(reward.Transaction()); err != nil {
+			return err
+		}
+	}
+	referral.RewardedAt = &models.CustomTime{Time: reward.RewardedAt}
+	referral.OrderNumber = reward.OrderNumber
+	referral.ReferrerPaid = reward.ReferrerAmount
+	return nil
+}
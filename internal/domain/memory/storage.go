@@ -0,0 +1,162 @@
+package memory
+
+import (
+	"fmt"
+	"github.com/Aleksei-D/go-loyalty-system/internal/models"
+	"sync"
+	"time"
+)
+
+type orderRecord struct {
+	order          models.Order
+	updateAt       time.Time
+	claimedBy      string
+	leaseExpiresAt time.Time
+	claimAttempts  uint
+	parkedAt       time.Time
+	history        []models.OrderStatusChange
+}
+
+// releaseClaim ends the lease once the accrual system reported a status.
+func (r *orderRecord) releaseClaim(now time.Time) {
+	r.updateAt = now
+	r.claimedBy = ""
+	r.leaseExpiresAt = time.Time{}
+	r.claimAttempts = 0
+	r.parkedAt = time.Time{}
+}
+
+type ledgerRecord struct {
+	id          int64
+	transaction models.LedgerTransaction
+	createdAt   time.Time
+}
+
+type outboxRecord struct {
+	event         models.Event
+	nextAttemptAt time.Time
+	lockedUntil   time.Time
+	delivered     bool
+	lastError     string
+}
+
+type webhookDeliveryRecord struct {
+	delivery    models.WebhookDelivery
+	lockedUntil time.Time
+}
+
+type Storage struct {
+	mu            sync.Mutex
+	users         map[string]models.User
+	failedLogins  map[string]uint
+	accounts      map[string]bool
+	orders        map[string]*orderRecord
+	withdrawals   map[string]models.Withdrawal
+	ledger        []ledgerRecord
+	adjustments   []models.Adjustment
+	refreshTokens map[string]*refreshTokenRecord
+	revokedTokens map[string]time.Time
+	resetTokens   map[string]*resetTokenRecord
+	holds         map[string]*models.Hold
+	referrals     map[string]*models.Referral
+	outbox        []*outboxRecord
+	webhooks      map[string]*models.WebhookSubscription
+	deliveries    []*webhookDeliveryRecord
+	deliverySeq   int64
+}
+
+func NewStorage() *Storage {
+	return &Storage{
+		users:         make(map[string]models.User),
+		failedLogins:  make(map[string]uint),
+		accounts:      make(map[string]bool),
+		orders:        make(map[string]*orderRecord),
+		withdrawals:   make(map[string]models.Withdrawal),
+		refreshTokens: make(map[string]*refreshTokenRecord),
+		revokedTokens: make(map[string]time.Time),
+		resetTokens:   make(map[string]*resetTokenRecord),
+		holds:         make(map[string]*models.Hold),
+		referrals:     make(map[string]*models.Referral),
+		webhooks:      make(map[string]*models.WebhookSubscription),
+	}
+}
+
+func (s *Storage) postLedgerTransaction(transaction *models.LedgerTransaction) error {
+	if !transaction.IsBalanced() {
+		return fmt.Errorf("ledger transaction %s %s is not balanced", transaction.Kind, transaction.Reference)
+	}
+
+	s.ledger = append(s.ledger, ledgerRecord{
+		id:          int64(len(s.ledger) + 1),
+		transaction: *transaction,
+		createdAt:   time.Now(),
+	})
+	return nil
+}
+
+func (s *Storage) appendEvents(events ...*models.Event) {
+	for _, event := range events {
+		record := &outboxRecord{event: *event, nextAttemptAt: event.CreatedAt.Time}
+		record.event.ID = int64(len(s.outbox) + 1)
+		s.outbox = append(s.outbox, record)
+	}
+}
+
+func (s *Storage) accountEntries(account string) []*models.LedgerEntry {
+	entries := make([]*models.LedgerEntry, 0)
+	for i := len(s.ledger) - 1; i >= 0; i-- {
+		record := s.ledger[i]
+		for _, posting := range record.transaction.Postings {
+			if posting.Account != account {
+				continue
+			}
+			entries = append(entries, &models.LedgerEntry{
+				TransactionID: record.id,
+				Kind:          record.transaction.Kind,
+				Reference:     record.transaction.Reference,
+				Amount:        posting.Amount,
+				Comment:       record.transaction.Comment,
+				CreatedAt:     models.CustomTime{Time: record.createdAt},
+			})
+		}
+	}
+	return entries
+}
+
+func (s *Storage) accountBalance(account string) models.Money {
+	var balance models.Money
+	for _, record := range s.ledger {
+		for _, posting := range record.transaction.Postings {
+			if posting.Account == account {
+				balance += posting.Amount
+			}
+		}
+	}
+	return balance
+}
+
+func (s *Storage) accountWithdrawn(accounts ...string) models.Money {
+	var withdrawn models.Money
+	for _, record := range s.ledger {
+		if !record.transaction.Touches(accounts...) {
+			continue
+		}
+		for _, posting := range record.transaction.Postings {
+			if posting.Account == models.AccountWithdrawal {
+				withdrawn += posting.Amount
+			}
+		}
+	}
+	return withdrawn
+}
+
+func (s *Storage) revokeUserSessions(login string) {
+	now := time.Now()
+	for _, record := range s.refreshTokens {
+		if record.token.Login != login || !record.token.ExpiresAt.After(now) {
+			continue
+		}
+		record.revoked = true
+		s.revokedTokens[record.token.AccessID] = record.token.ExpiresAt
+	}
+}
@@ -0,0 +1,91 @@
+package memory
+
+import (
+	"context"
+	"github.com/Aleksei-D/go-loyalty-system/internal/logger"
+	"github.com/Aleksei-D/go-loyalty-system/internal/models"
+	"github.com/Aleksei-D/go-loyalty-system/internal/utils/common"
+	"go.uber.org/zap"
+	"time"
+)
+
+type refreshTokenRecord struct {
+	token   models.RefreshToken
+	revoked bool
+}
+
+type TokenRepository struct {
+	storage *Storage
+}
+
+func NewTokenRepository(storage *Storage) *TokenRepository {
+	return &TokenRepository{storage: storage}
+}
+
+func (t *TokenRepository) SaveRefreshToken(_ context.Context, token *models.RefreshToken) error {
+	t.storage.mu.Lock()
+	defer t.storage.mu.Unlock()
+
+	t.storage.refreshTokens[token.Hash] = &refreshTokenRecord{token: *token}
+	return nil
+}
+
+func (t *TokenRepository) RotateRefreshToken(_ context.Context, oldHash string, newToken *models.RefreshToken) (*models.RefreshToken, error) {
+	t.storage.mu.Lock()
+	defer t.storage.mu.Unlock()
+
+	record, ok := t.storage.refreshTokens[oldHash]
+	if !ok {
+		return nil, common.ErrInvalidRefreshToken
+	}
+
+	if record.revoked {
+		logger.Log.Warn("refresh token reuse detected, revoking user sessions", zap.String("login", record.token.Login))
+		t.storage.revokeUserSessions(record.token.Login)
+		return nil, common.ErrRefreshTokenReused
+	}
+	if !record.token.ExpiresAt.After(time.Now()) {
+		return nil, common.ErrInvalidRefreshToken
+	}
+
+	record.revoked = true
+	newToken.Login = record.token.Login
+	t.storage.refreshTokens[newToken.Hash] = &refreshTokenRecord{token: *newToken}
+	return newToken, nil
+}
+
+func (t *TokenRepository) RevokeSession(_ context.Context, accessID string, accessExpiresAt time.Time) error {
+	t.storage.mu.Lock()
+	defer t.storage.mu.Unlock()
+
+	now := time.Now()
+	for id, expiresAt := range t.storage.revokedTokens {
+		if expiresAt.Before(now) {
+			delete(t.storage.revokedTokens, id)
+		}
+	}
+
+	t.storage.revokedTokens[accessID] = accessExpiresAt
+	for _, record := range t.storage.refreshTokens {
+		if record.token.AccessID == accessID {
+			record.revoked = true
+		}
+	}
+	return nil
+}
+
+func (t *TokenRepository) IsRevoked(_ context.Context, accessID string) (bool, error) {
+	t.storage.mu.Lock()
+	defer t.storage.mu.Unlock()
+
+	_, ok := t.storage.revokedTokens[accessID]
+	return ok, nil
+}
+
+func (t *TokenRepository) RevokeUserSessions(_ context.Context, login string) error {
+	t.storage.mu.Lock()
+	defer t.storage.mu.Unlock()
+
+	t.storage.revokeUserSessions(login)
+	return nil
+}
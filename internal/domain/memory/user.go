@@ -0,0 +1,197 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"github.com/Aleksei-D/go-loyalty-system/internal/models"
+	"github.com/Aleksei-D/go-loyalty-system/internal/utils/common"
+	"sort"
+	"time"
+)
+
+type UserRepository struct {
+	storage *Storage
+}
+
+func NewUserRepository(storage *Storage) *UserRepository {
+	return &UserRepository{storage: storage}
+}
+
+func (u *UserRepository) Create(_ context.Context, user *models.User) (*models.User, error) {
+	u.storage.mu.Lock()
+	defer u.storage.mu.Unlock()
+
+	if _, ok := u.storage.users[user.Login]; ok {
+		return nil, fmt.Errorf("user - %s already exists", user.Login)
+	}
+
+	if len(user.Roles) == 0 {
+		user.Roles = []models.Role{models.RoleUser}
+	}
+	if user.ReferralCode != "" {
+		for _, existUser := range u.storage.users {
+			if existUser.ReferralCode == user.ReferralCode {
+				return nil, fmt.Errorf("referral code - %s already exists", user.ReferralCode)
+			}
+		}
+	}
+	u.storage.users[user.Login] = copyUser(user)
+	u.storage.accounts[user.Login] = true
+	if user.ReferredBy != "" {
+		u.storage.referrals[user.Login] = &models.Referral{
+			Referrer:  user.ReferredBy,
+			Referee:   user.Login,
+			CreatedAt: models.CustomTime{Time: time.Now()},
+		}
+	}
+	return user, nil
+}
+
+func (u *UserRepository) GetByLogin(_ context.Context, login string) (*models.User, error) {
+	u.storage.mu.Lock()
+	defer u.storage.mu.Unlock()
+
+	user := u.storage.users[login]
+	userCopy := copyUser(&user)
+	return &userCopy, nil
+}
+
+func (u *UserRepository) IsExist(_ context.Context, login string) (bool, error) {
+	u.storage.mu.Lock()
+	defer u.storage.mu.Unlock()
+
+	_, ok := u.storage.users[login]
+	return ok, nil
+}
+
+func (u *UserRepository) RegisterFailedLogin(_ context.Context, login string, maxAttempts uint, lockout time.Duration) (*time.Time, error) {
+	u.storage.mu.Lock()
+	defer u.storage.mu.Unlock()
+
+	user, ok := u.storage.users[login]
+	if !ok {
+		return nil, nil
+	}
+
+	u.storage.failedLogins[login]++
+	if u.storage.failedLogins[login] >= maxAttempts {
+		u.storage.failedLogins[login] = 0
+		lockedUntil := time.Now().Add(lockout)
+		user.LockedUntil = &lockedUntil
+		u.storage.users[login] = user
+	}
+	return user.LockedUntil, nil
+}
+
+func (u *UserRepository) ResetFailedLogins(_ context.Context, login string) error {
+	u.storage.mu.Lock()
+	defer u.storage.mu.Unlock()
+
+	user, ok := u.storage.users[login]
+	if !ok {
+		return nil
+	}
+
+	delete(u.storage.failedLogins, login)
+	user.LockedUntil = nil
+	u.storage.users[login] = user
+	return nil
+}
+
+func (u *UserRepository) UpdatePassword(_ context.Context, login, passwordHash string) error {
+	u.storage.mu.Lock()
+	defer u.storage.mu.Unlock()
+
+	user, ok := u.storage.users[login]
+	if !ok {
+		return nil
+	}
+
+	delete(u.storage.failedLogins, login)
+	user.Password = passwordHash
+	user.LockedUntil = nil
+	u.storage.users[login] = user
+	return nil
+}
+
+func (u *UserRepository) GetAll(_ context.Context) ([]*models.User, error) {
+	u.storage.mu.Lock()
+	defer u.storage.mu.Unlock()
+
+	users := make([]*models.User, 0, len(u.storage.users))
+	for _, user := range u.storage.users {
+		userCopy := copyUser(&user)
+		users = append(users, &userCopy)
+	}
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].Login < users[j].Login
+	})
+	return users, nil
+}
+
+func (u *UserRepository) SetBlocked(_ context.Context, login string, blocked bool) error {
+	u.storage.mu.Lock()
+	defer u.storage.mu.Unlock()
+
+	user, ok := u.storage.users[login]
+	if !ok {
+		return common.ErrUserNotFound
+	}
+
+	switch {
+	case !blocked:
+		user.BlockedAt = nil
+	case user.BlockedAt == nil:
+		blockedAt := time.Now()
+		user.BlockedAt = &blockedAt
+	}
+	u.storage.users[login] = user
+	return nil
+}
+
+func (u *UserRepository) GrantRole(_ context.Context, login string, role models.Role) error {
+	u.storage.mu.Lock()
+	defer u.storage.mu.Unlock()
+
+	user, ok := u.storage.users[login]
+	if !ok {
+		return common.ErrUserNotFound
+	}
+	if !user.HasRole(role) {
+		user.Roles = append(append([]models.Role{}, user.Roles...), role)
+		u.storage.users[login] = user
+	}
+	return nil
+}
+
+func (u *UserRepository) SetTier(_ context.Context, login, tier string) error {
+	u.storage.mu.Lock()
+	defer u.storage.mu.Unlock()
+
+	user, ok := u.storage.users[login]
+	if !ok {
+		return common.ErrUserNotFound
+	}
+	user.Tier = tier
+	u.storage.users[login] = user
+	return nil
+}
+
+func (u *UserRepository) GetByReferralCode(_ context.Context, code string) (*models.User, error) {
+	u.storage.mu.Lock()
+	defer u.storage.mu.Unlock()
+
+	for _, user := range u.storage.users {
+		if user.ReferralCode == code {
+			userCopy := copyUser(&user)
+			return &userCopy, nil
+		}
+	}
+	return nil, common.ErrUserNotFound
+}
+
+func copyUser(user *models.User) models.User {
+	userCopy := *user
+	userCopy.Roles = append([]models.Role(nil), user.Roles...)
+	return userCopy
+}
@@ -0,0 +1,190 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"github.com/Aleksei-D/go-loyalty-system/internal/models"
+	"github.com/Aleksei-D/go-loyalty-system/internal/utils/common"
+	"sort"
+	"time"
+)
+
+type WebhookRepository struct {
+	storage *Storage
+}
+
+func NewWebhookRepository(storage *Storage) *WebhookRepository {
+	return &WebhookRepository{storage: storage}
+}
+
+func (w *WebhookRepository) CreateSubscription(_ context.Context, subscription *models.WebhookSubscription) error {
+	w.storage.mu.Lock()
+	defer w.storage.mu.Unlock()
+
+	if _, ok := w.storage.webhooks[subscription.ID]; ok {
+		return fmt.Errorf("webhook - %s already exists", subscription.ID)
+	}
+	subscription.CreatedAt = models.CustomTime{Time: time.Now()}
+	subscriptionCopy := *subscription
+	subscriptionCopy.EventTypes = append([]models.EventType(nil), subscription.EventTypes...)
+	w.storage.webhooks[subscription.ID] = &subscriptionCopy
+	return nil
+}
+
+func (w *WebhookRepository) GetSubscriptions(_ context.Context) ([]*models.WebhookSubscription, error) {
+	w.storage.mu.Lock()
+	defer w.storage.mu.Unlock()
+
+	subscriptions := make([]*models.WebhookSubscription, 0, len(w.storage.webhooks))
+	for _, subscription := range w.storage.webhooks {
+		subscriptionCopy := *subscription
+		subscriptionCopy.EventTypes = append([]models.EventType(nil), subscription.EventTypes...)
+		subscriptions = append(subscriptions, &subscriptionCopy)
+	}
+	sort.Slice(subscriptions, func(i, j int) bool {
+		if subscriptions[i].CreatedAt.Equal(subscriptions[j].CreatedAt.Time) {
+			return subscriptions[i].ID < subscriptions[j].ID
+		}
+		return subscriptions[i].CreatedAt.Before(subscriptions[j].CreatedAt.Time)
+	})
+	return subscriptions, nil
+}
+
+func (w *WebhookRepository) DeleteSubscription(_ context.Context, id string) error {
+	w.storage.mu.Lock()
+	defer w.storage.mu.Unlock()
+
+	if _, ok := w.storage.webhooks[id]; !ok {
+		return common.ErrWebhookNotFound
+	}
+	delete(w.storage.webhooks, id)
+
+	deliveries := w.storage.deliveries[:0]
+	for _, record := range w.storage.deliveries {
+		if record.delivery.SubscriptionID != id {
+			deliveries = append(deliveries, record)
+		}
+	}
+	w.storage.deliveries = deliveries
+	return nil
+}
+
+func (w *WebhookRepository) Enqueue(_ context.Context, deliveries []*models.WebhookDelivery) error {
+	w.storage.mu.Lock()
+	defer w.storage.mu.Unlock()
+
+	now := time.Now()
+	for _, delivery := range deliveries {
+		if _, ok := w.storage.webhooks[delivery.SubscriptionID]; !ok || w.storage.hasDelivery(delivery) {
+			continue
+		}
+		record := &webhookDeliveryRecord{delivery: *delivery}
+		w.storage.deliverySeq++
+		record.delivery.ID = w.storage.deliverySeq
+		record.delivery.Status = models.WebhookDeliveryPending
+		record.delivery.CreatedAt = models.CustomTime{Time: now}
+		record.delivery.NextAttemptAt = &models.CustomTime{Time: now}
+		w.storage.deliveries = append(w.storage.deliveries, record)
+	}
+	return nil
+}
+
+func (w *WebhookRepository) ClaimDeliveries(_ context.Context, limit uint, lease time.Duration) ([]*models.WebhookDelivery, error) {
+	w.storage.mu.Lock()
+	defer w.storage.mu.Unlock()
+
+	now := time.Now()
+	deliveries := make([]*models.WebhookDelivery, 0)
+	for _, record := range w.storage.deliveries {
+		if uint(len(deliveries)) >= limit {
+			break
+		}
+		if record.delivery.Status != models.WebhookDeliveryPending || record.delivery.NextAttemptAt.After(now) || record.lockedUntil.After(now) {
+			continue
+		}
+		record.lockedUntil = now.Add(lease)
+		delivery := record.delivery
+		subscription := w.storage.webhooks[delivery.SubscriptionID]
+		delivery.URL = subscription.URL
+		delivery.Secret = subscription.Secret
+		deliveries = append(deliveries, &delivery)
+	}
+	return deliveries, nil
+}
+
+func (w *WebhookRepository) SaveAttempt(_ context.Context, delivery *models.WebhookDelivery) error {
+	w.storage.mu.Lock()
+	defer w.storage.mu.Unlock()
+
+	record := w.storage.deliveryRecord(delivery.ID)
+	if record == nil {
+		return common.ErrWebhookDeliveryNotFound
+	}
+	nextAttemptAt := record.delivery.NextAttemptAt
+	if delivery.NextAttemptAt != nil {
+		nextAttemptAt = delivery.NextAttemptAt
+	}
+	record.delivery.Status = delivery.Status
+	record.delivery.Attempts = delivery.Attempts
+	record.delivery.LastStatusCode = delivery.LastStatusCode
+	record.delivery.LastError = delivery.LastError
+	record.delivery.NextAttemptAt = nextAttemptAt
+	record.delivery.DeliveredAt = delivery.DeliveredAt
+	record.lockedUntil = time.Time{}
+	return nil
+}
+
+func (w *WebhookRepository) GetDeliveries(
+	_ context.Context,
+	subscriptionID string,
+	status models.WebhookDeliveryStatus,
+) ([]*models.WebhookDelivery, error) {
+	w.storage.mu.Lock()
+	defer w.storage.mu.Unlock()
+
+	deliveries := make([]*models.WebhookDelivery, 0)
+	for i := len(w.storage.deliveries) - 1; i >= 0; i-- {
+		delivery := w.storage.deliveries[i].delivery
+		if delivery.SubscriptionID != subscriptionID || (status != "" && delivery.Status != status) {
+			continue
+		}
+		if delivery.Status != models.WebhookDeliveryPending {
+			delivery.NextAttemptAt = nil
+		}
+		deliveries = append(deliveries, &delivery)
+	}
+	return deliveries, nil
+}
+
+func (w *WebhookRepository) Requeue(_ context.Context, subscriptionID string, id int64) error {
+	w.storage.mu.Lock()
+	defer w.storage.mu.Unlock()
+
+	record := w.storage.deliveryRecord(id)
+	if record == nil || record.delivery.SubscriptionID != subscriptionID || record.delivery.Status != models.WebhookDeliveryDead {
+		return common.ErrWebhookDeliveryNotFound
+	}
+	record.delivery.Status = models.WebhookDeliveryPending
+	record.delivery.Attempts = 0
+	record.delivery.NextAttemptAt = &models.CustomTime{Time: time.Now()}
+	record.lockedUntil = time.Time{}
+	return nil
+}
+
+func (s *Storage) hasDelivery(delivery *models.WebhookDelivery) bool {
+	for _, record := range s.deliveries {
+		if record.delivery.SubscriptionID == delivery.SubscriptionID && record.delivery.EventID == delivery.EventID {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Storage) deliveryRecord(id int64) *webhookDeliveryRecord {
+	for _, record := range s.deliveries {
+		if record.delivery.ID == id {
+			return record
+		}
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/Aleksei-D/go-loyalty-system/internal/models"
+	"github.com/Aleksei-D/go-loyalty-system/internal/utils/common"
+	"sort"
+	"time"
+)
+
+type WithdrawalRepository struct {
+	storage *Storage
+}
+
+func NewWithdrawalRepository(storage *Storage) *WithdrawalRepository {
+	return &WithdrawalRepository{storage: storage}
+}
+
+func (w *WithdrawalRepository) GetAllByLogin(_ context.Context, login string, filter *models.ListFilter) ([]*models.Withdrawal, error) {
+	withdrawals := make([]*models.Withdrawal, 0)
+	w.storage.mu.Lock()
+	defer w.storage.mu.Unlock()
+
+	for _, withdrawal := range w.storage.withdrawals {
+		if withdrawal.Login != login {
+			continue
+		}
+		if filter.Status != "" && string(withdrawal.Status) != filter.Status {
+			continue
+		}
+		if !filter.Contains(withdrawal.ProcessedAt.Time, withdrawal.OrderNumber) {
+			continue
+		}
+		withdrawal := withdrawal
+		withdrawals = append(withdrawals, &withdrawal)
+	}
+
+	sort.Slice(withdrawals, func(i, j int) bool {
+		if withdrawals[i].ProcessedAt.Equal(withdrawals[j].ProcessedAt.Time) {
+			return withdrawals[i].OrderNumber > withdrawals[j].OrderNumber
+		}
+		return withdrawals[i].ProcessedAt.After(withdrawals[j].ProcessedAt.Time)
+	})
+	if filter.Limit > 0 && uint(len(withdrawals)) > filter.Limit {
+		withdrawals = withdrawals[:filter.Limit]
+	}
+	return withdrawals, nil
+}
+
+func (w *WithdrawalRepository) Withdraw(_ context.Context, withdraw *models.Withdrawal) error {
+	w.storage.mu.Lock()
+	defer w.storage.mu.Unlock()
+
+	if !w.storage.accounts[withdraw.Login] {
+		return sql.ErrNoRows
+	}
+
+	currentBalance := w.storage.accountBalance(models.UserAccount(withdraw.Login))
+
+	if currentBalance < withdraw.Sum {
+		return common.ErrPaymentInsufficient
+	}
+
+	if _, ok := w.storage.withdrawals[withdraw.OrderNumber]; ok {
+		return fmt.Errorf("withdrawal for order - %s already exists", withdraw.OrderNumber)
+	}
+
+	event, err := models.WithdrawalEvent(models.EventWithdrawalMade, withdraw)
+	if err != nil {
+		return err
+	}
+
+	err = w.storage.postLedgerTransaction(models.NewTransfer(
+		models.PostingKindWithdrawal,
+		withdraw.OrderNumber,
+		models.UserAccount(withdraw.Login),
+		models.AccountWithdrawal,
+		withdraw.Sum,
+	))
+	if err != nil {
+		return err
+	}
+
+	w.storage.withdrawals[withdraw.OrderNumber] = models.Withdrawal{
+		Login:       withdraw.Login,
+		OrderNumber: withdraw.OrderNumber,
+		Sum:         withdraw.Sum,
+		Status:      models.WithdrawalStatusCompleted,
+		ProcessedAt: models.CustomTime{Time: time.Now()},
+	}
+	w.storage.appendEvents(event)
+	return nil
+}
+
+func (w *WithdrawalRepository) IsExist(_ context.Context, withdraw *models.Withdrawal) (bool, error) {
+	w.storage.mu.Lock()
+	defer w.storage.mu.Unlock()
+
+	_, ok := w.storage.withdrawals[withdraw.OrderNumber]
+	return ok, nil
+}
+
+func (w *WithdrawalRepository) Refund(_ context.Context, login, orderNumber string) (*models.Withdrawal, error) {
+	w.storage.mu.Lock()
+	defer w.storage.mu.Unlock()
+
+	withdrawal, ok := w.storage.withdrawals[orderNumber]
+	if !ok || withdrawal.Login != login {
+		return nil, common.ErrWithdrawalNotFound
+	}
+	if withdrawal.Status == models.WithdrawalStatusRefunded {
+		return nil, common.ErrWithdrawalRefunded
+	}
+
+	event, err := models.WithdrawalEvent(models.EventWithdrawalRefunded, &withdrawal)
+	if err != nil {
+		return nil, err
+	}
+
+	err = w.storage.postLedgerTransaction(models.NewTransfer(
+		models.PostingKindReversal,
+		orderNumber,
+		models.AccountWithdrawal,
+		models.UserAccount(login),
+		withdrawal.Sum,
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	withdrawal.Status = models.WithdrawalStatusRefunded
+	withdrawal.RefundedAt = &models.CustomTime{Time: time.Now()}
+	w.storage.withdrawals[orderNumber] = withdrawal
+	w.storage.appendEvents(event)
+	return &withdrawal, nil
+}
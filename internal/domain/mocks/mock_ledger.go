@@ -0,0 +1,110 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ledger.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	models "github.com/Aleksei-D/go-loyalty-system/internal/models"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockLedgerRepository is a mock of LedgerRepository interface.
+type MockLedgerRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockLedgerRepositoryMockRecorder
+}
+
+// MockLedgerRepositoryMockRecorder is the mock recorder for MockLedgerRepository.
+type MockLedgerRepositoryMockRecorder struct {
+	mock *MockLedgerRepository
+}
+
+// NewMockLedgerRepository creates a new mock instance.
+func NewMockLedgerRepository(ctrl *gomock.Controller) *MockLedgerRepository {
+	mock := &MockLedgerRepository{ctrl: ctrl}
+	mock.recorder = &MockLedgerRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLedgerRepository) EXPECT() *MockLedgerRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Adjust mocks base method.
+func (m *MockLedgerRepository) Adjust(ctx context.Context, adjustment *models.Adjustment) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Adjust", ctx, adjustment)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Adjust indicates an expected call of Adjust.
+func (mr *MockLedgerRepositoryMockRecorder) Adjust(ctx, adjustment interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Adjust", reflect.TypeOf((*MockLedgerRepository)(nil).Adjust), ctx, adjustment)
+}
+
+// AwardBonus mocks base method.
+func (m *MockLedgerRepository) AwardBonus(ctx context.Context, award *models.BonusAward, dailyCap models.Money) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AwardBonus", ctx, award, dailyCap)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AwardBonus indicates an expected call of AwardBonus.
+func (mr *MockLedgerRepositoryMockRecorder) AwardBonus(ctx, award, dailyCap interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AwardBonus", reflect.TypeOf((*MockLedgerRepository)(nil).AwardBonus), ctx, award, dailyCap)
+}
+
+// ExpirePoints mocks base method.
+func (m *MockLedgerRepository) ExpirePoints(ctx context.Context, login string, ttl time.Duration) (models.Money, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpirePoints", ctx, login, ttl)
+	ret0, _ := ret[0].(models.Money)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExpirePoints indicates an expected call of ExpirePoints.
+func (mr *MockLedgerRepositoryMockRecorder) ExpirePoints(ctx, login, ttl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpirePoints", reflect.TypeOf((*MockLedgerRepository)(nil).ExpirePoints), ctx, login, ttl)
+}
+
+// GetEntriesByLogin mocks base method.
+func (m *MockLedgerRepository) GetEntriesByLogin(ctx context.Context, login string) ([]*models.LedgerEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEntriesByLogin", ctx, login)
+	ret0, _ := ret[0].([]*models.LedgerEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEntriesByLogin indicates an expected call of GetEntriesByLogin.
+func (mr *MockLedgerRepositoryMockRecorder) GetEntriesByLogin(ctx, login interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEntriesByLogin", reflect.TypeOf((*MockLedgerRepository)(nil).GetEntriesByLogin), ctx, login)
+}
+
+// GetExpiryCandidates mocks base method.
+func (m *MockLedgerRepository) GetExpiryCandidates(ctx context.Context, earnedBefore time.Time) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetExpiryCandidates", ctx, earnedBefore)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetExpiryCandidates indicates an expected call of GetExpiryCandidates.
+func (mr *MockLedgerRepositoryMockRecorder) GetExpiryCandidates(ctx, earnedBefore interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExpiryCandidates", reflect.TypeOf((*MockLedgerRepository)(nil).GetExpiryCandidates), ctx, earnedBefore)
+}
@@ -7,6 +7,7 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	models "github.com/Aleksei-D/go-loyalty-system/internal/models"
 	gomock "github.com/golang/mock/gomock"
@@ -50,34 +51,49 @@ func (mr *MockOrderRepositoryMockRecorder) Add(ctx, login, orderNumber interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockOrderRepository)(nil).Add), ctx, login, orderNumber)
 }
 
-// GetAllByLogin mocks base method.
-func (m *MockOrderRepository) GetAllByLogin(ctx context.Context, login string) ([]*models.Order, error) {
+// ClaimOrders mocks base method.
+func (m *MockOrderRepository) ClaimOrders(ctx context.Context, claim *models.OrderClaim) ([]*models.Order, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAllByLogin", ctx, login)
+	ret := m.ctrl.Call(m, "ClaimOrders", ctx, claim)
 	ret0, _ := ret[0].([]*models.Order)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetAllByLogin indicates an expected call of GetAllByLogin.
-func (mr *MockOrderRepositoryMockRecorder) GetAllByLogin(ctx, login interface{}) *gomock.Call {
+// ClaimOrders indicates an expected call of ClaimOrders.
+func (mr *MockOrderRepositoryMockRecorder) ClaimOrders(ctx, claim interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimOrders", reflect.TypeOf((*MockOrderRepository)(nil).ClaimOrders), ctx, claim)
+}
+
+// GetAccruedTotal mocks base method.
+func (m *MockOrderRepository) GetAccruedTotal(ctx context.Context, login string, since time.Time) (models.Money, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccruedTotal", ctx, login, since)
+	ret0, _ := ret[0].(models.Money)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccruedTotal indicates an expected call of GetAccruedTotal.
+func (mr *MockOrderRepositoryMockRecorder) GetAccruedTotal(ctx, login, since interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllByLogin", reflect.TypeOf((*MockOrderRepository)(nil).GetAllByLogin), ctx, login)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccruedTotal", reflect.TypeOf((*MockOrderRepository)(nil).GetAccruedTotal), ctx, login, since)
 }
 
-// GetNotAcceptedOrderNumbers mocks base method.
-func (m *MockOrderRepository) GetNotAcceptedOrderNumbers(ctx context.Context, limit, updateTimeout uint) ([]*models.Order, error) {
+// GetAllByLogin mocks base method.
+func (m *MockOrderRepository) GetAllByLogin(ctx context.Context, login string, filter *models.ListFilter) ([]*models.Order, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetNotAcceptedOrderNumbers", ctx, limit, updateTimeout)
+	ret := m.ctrl.Call(m, "GetAllByLogin", ctx, login, filter)
 	ret0, _ := ret[0].([]*models.Order)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetNotAcceptedOrderNumbers indicates an expected call of GetNotAcceptedOrderNumbers.
-func (mr *MockOrderRepositoryMockRecorder) GetNotAcceptedOrderNumbers(ctx, limit, updateTimeout interface{}) *gomock.Call {
+// GetAllByLogin indicates an expected call of GetAllByLogin.
+func (mr *MockOrderRepositoryMockRecorder) GetAllByLogin(ctx, login, filter interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotAcceptedOrderNumbers", reflect.TypeOf((*MockOrderRepository)(nil).GetNotAcceptedOrderNumbers), ctx, limit, updateTimeout)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllByLogin", reflect.TypeOf((*MockOrderRepository)(nil).GetAllByLogin), ctx, login, filter)
 }
 
 // GetOrderByNumber mocks base method.
@@ -95,6 +111,36 @@ func (mr *MockOrderRepositoryMockRecorder) GetOrderByNumber(ctx, orderNumber int
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderByNumber", reflect.TypeOf((*MockOrderRepository)(nil).GetOrderByNumber), ctx, orderNumber)
 }
 
+// GetParkedOrders mocks base method.
+func (m *MockOrderRepository) GetParkedOrders(ctx context.Context) ([]*models.ParkedOrder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetParkedOrders", ctx)
+	ret0, _ := ret[0].([]*models.ParkedOrder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetParkedOrders indicates an expected call of GetParkedOrders.
+func (mr *MockOrderRepositoryMockRecorder) GetParkedOrders(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetParkedOrders", reflect.TypeOf((*MockOrderRepository)(nil).GetParkedOrders), ctx)
+}
+
+// GetStatusHistory mocks base method.
+func (m *MockOrderRepository) GetStatusHistory(ctx context.Context, orderNumber string) ([]*models.OrderStatusChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStatusHistory", ctx, orderNumber)
+	ret0, _ := ret[0].([]*models.OrderStatusChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStatusHistory indicates an expected call of GetStatusHistory.
+func (mr *MockOrderRepositoryMockRecorder) GetStatusHistory(ctx, orderNumber interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStatusHistory", reflect.TypeOf((*MockOrderRepository)(nil).GetStatusHistory), ctx, orderNumber)
+}
+
 // IsExist mocks base method.
 func (m *MockOrderRepository) IsExist(ctx context.Context, orderNumber string) (bool, error) {
 	m.ctrl.T.Helper()
@@ -110,6 +156,20 @@ func (mr *MockOrderRepositoryMockRecorder) IsExist(ctx, orderNumber interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsExist", reflect.TypeOf((*MockOrderRepository)(nil).IsExist), ctx, orderNumber)
 }
 
+// UnparkOrder mocks base method.
+func (m *MockOrderRepository) UnparkOrder(ctx context.Context, orderNumber string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnparkOrder", ctx, orderNumber)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnparkOrder indicates an expected call of UnparkOrder.
+func (mr *MockOrderRepositoryMockRecorder) UnparkOrder(ctx, orderNumber interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnparkOrder", reflect.TypeOf((*MockOrderRepository)(nil).UnparkOrder), ctx, orderNumber)
+}
+
 // UpdateStatus mocks base method.
 func (m *MockOrderRepository) UpdateStatus(ctx context.Context, order *models.Order) error {
 	m.ctrl.T.Helper()
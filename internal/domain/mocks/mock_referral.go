@@ -0,0 +1,80 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: referral.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	models "github.com/Aleksei-D/go-loyalty-system/internal/models"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockReferralRepository is a mock of ReferralRepository interface.
+type MockReferralRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockReferralRepositoryMockRecorder
+}
+
+// MockReferralRepositoryMockRecorder is the mock recorder for MockReferralRepository.
+type MockReferralRepositoryMockRecorder struct {
+	mock *MockReferralRepository
+}
+
+// NewMockReferralRepository creates a new mock instance.
+func NewMockReferralRepository(ctrl *gomock.Controller) *MockReferralRepository {
+	mock := &MockReferralRepository{ctrl: ctrl}
+	mock.recorder = &MockReferralRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReferralRepository) EXPECT() *MockReferralRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetAllByReferrer mocks base method.
+func (m *MockReferralRepository) GetAllByReferrer(ctx context.Context, referrer string) ([]*models.Referral, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllByReferrer", ctx, referrer)
+	ret0, _ := ret[0].([]*models.Referral)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllByReferrer indicates an expected call of GetAllByReferrer.
+func (mr *MockReferralRepositoryMockRecorder) GetAllByReferrer(ctx, referrer interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllByReferrer", reflect.TypeOf((*MockReferralRepository)(nil).GetAllByReferrer), ctx, referrer)
+}
+
+// GetByReferee mocks base method.
+func (m *MockReferralRepository) GetByReferee(ctx context.Context, referee string) (*models.Referral, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByReferee", ctx, referee)
+	ret0, _ := ret[0].(*models.Referral)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByReferee indicates an expected call of GetByReferee.
+func (mr *MockReferralRepositoryMockRecorder) GetByReferee(ctx, referee interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByReferee", reflect.TypeOf((*MockReferralRepository)(nil).GetByReferee), ctx, referee)
+}
+
+// Reward mocks base method.
+func (m *MockReferralRepository) Reward(ctx context.Context, reward *models.ReferralReward) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reward", ctx, reward)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reward indicates an expected call of Reward.
+func (mr *MockReferralRepositoryMockRecorder) Reward(ctx, reward interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reward", reflect.TypeOf((*MockReferralRepository)(nil).Reward), ctx, reward)
+}
@@ -7,6 +7,7 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	models "github.com/Aleksei-D/go-loyalty-system/internal/models"
 	gomock "github.com/golang/mock/gomock"
@@ -50,6 +51,21 @@ func (mr *MockUserRepositoryMockRecorder) Create(ctx, user interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockUserRepository)(nil).Create), ctx, user)
 }
 
+// GetAll mocks base method.
+func (m *MockUserRepository) GetAll(ctx context.Context) ([]*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAll", ctx)
+	ret0, _ := ret[0].([]*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAll indicates an expected call of GetAll.
+func (mr *MockUserRepositoryMockRecorder) GetAll(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockUserRepository)(nil).GetAll), ctx)
+}
+
 // GetByLogin mocks base method.
 func (m *MockUserRepository) GetByLogin(ctx context.Context, login string) (*models.User, error) {
 	m.ctrl.T.Helper()
@@ -65,6 +81,35 @@ func (mr *MockUserRepositoryMockRecorder) GetByLogin(ctx, login interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByLogin", reflect.TypeOf((*MockUserRepository)(nil).GetByLogin), ctx, login)
 }
 
+// GetByReferralCode mocks base method.
+func (m *MockUserRepository) GetByReferralCode(ctx context.Context, code string) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByReferralCode", ctx, code)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByReferralCode indicates an expected call of GetByReferralCode.
+func (mr *MockUserRepositoryMockRecorder) GetByReferralCode(ctx, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByReferralCode", reflect.TypeOf((*MockUserRepository)(nil).GetByReferralCode), ctx, code)
+}
+
+// GrantRole mocks base method.
+func (m *MockUserRepository) GrantRole(ctx context.Context, login string, role models.Role) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GrantRole", ctx, login, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GrantRole indicates an expected call of GrantRole.
+func (mr *MockUserRepositoryMockRecorder) GrantRole(ctx, login, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GrantRole", reflect.TypeOf((*MockUserRepository)(nil).GrantRole), ctx, login, role)
+}
+
 // IsExist mocks base method.
 func (m *MockUserRepository) IsExist(ctx context.Context, login string) (bool, error) {
 	m.ctrl.T.Helper()
@@ -79,3 +124,74 @@ func (mr *MockUserRepositoryMockRecorder) IsExist(ctx, login interface{}) *gomoc
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsExist", reflect.TypeOf((*MockUserRepository)(nil).IsExist), ctx, login)
 }
+
+// RegisterFailedLogin mocks base method.
+func (m *MockUserRepository) RegisterFailedLogin(ctx context.Context, login string, maxAttempts uint, lockout time.Duration) (*time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RegisterFailedLogin", ctx, login, maxAttempts, lockout)
+	ret0, _ := ret[0].(*time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RegisterFailedLogin indicates an expected call of RegisterFailedLogin.
+func (mr *MockUserRepositoryMockRecorder) RegisterFailedLogin(ctx, login, maxAttempts, lockout interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterFailedLogin", reflect.TypeOf((*MockUserRepository)(nil).RegisterFailedLogin), ctx, login, maxAttempts, lockout)
+}
+
+// ResetFailedLogins mocks base method.
+func (m *MockUserRepository) ResetFailedLogins(ctx context.Context, login string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetFailedLogins", ctx, login)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetFailedLogins indicates an expected call of ResetFailedLogins.
+func (mr *MockUserRepositoryMockRecorder) ResetFailedLogins(ctx, login interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetFailedLogins", reflect.TypeOf((*MockUserRepository)(nil).ResetFailedLogins), ctx, login)
+}
+
+// SetBlocked mocks base method.
+func (m *MockUserRepository) SetBlocked(ctx context.Context, login string, blocked bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetBlocked", ctx, login, blocked)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetBlocked indicates an expected call of SetBlocked.
+func (mr *MockUserRepositoryMockRecorder) SetBlocked(ctx, login, blocked interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetBlocked", reflect.TypeOf((*MockUserRepository)(nil).SetBlocked), ctx, login, blocked)
+}
+
+// SetTier mocks base method.
+func (m *MockUserRepository) SetTier(ctx context.Context, login, tier string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTier", ctx, login, tier)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetTier indicates an expected call of SetTier.
+func (mr *MockUserRepositoryMockRecorder) SetTier(ctx, login, tier interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTier", reflect.TypeOf((*MockUserRepository)(nil).SetTier), ctx, login, tier)
+}
+
+// UpdatePassword mocks base method.
+func (m *MockUserRepository) UpdatePassword(ctx context.Context, login, passwordHash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePassword", ctx, login, passwordHash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePassword indicates an expected call of UpdatePassword.
+func (mr *MockUserRepositoryMockRecorder) UpdatePassword(ctx, login, passwordHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePassword", reflect.TypeOf((*MockUserRepository)(nil).UpdatePassword), ctx, login, passwordHash)
+}
@@ -36,18 +36,18 @@ func (m *MockWithdrawalRepository) EXPECT() *MockWithdrawalRepositoryMockRecorde
 }
 
 // GetAllByLogin mocks base method.
-func (m *MockWithdrawalRepository) GetAllByLogin(ctx context.Context, login string) ([]*models.Withdrawal, error) {
+func (m *MockWithdrawalRepository) GetAllByLogin(ctx context.Context, login string, filter *models.ListFilter) ([]*models.Withdrawal, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAllByLogin", ctx, login)
+	ret := m.ctrl.Call(m, "GetAllByLogin", ctx, login, filter)
 	ret0, _ := ret[0].([]*models.Withdrawal)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetAllByLogin indicates an expected call of GetAllByLogin.
-func (mr *MockWithdrawalRepositoryMockRecorder) GetAllByLogin(ctx, login interface{}) *gomock.Call {
+func (mr *MockWithdrawalRepositoryMockRecorder) GetAllByLogin(ctx, login, filter interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllByLogin", reflect.TypeOf((*MockWithdrawalRepository)(nil).GetAllByLogin), ctx, login)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllByLogin", reflect.TypeOf((*MockWithdrawalRepository)(nil).GetAllByLogin), ctx, login, filter)
 }
 
 // IsExist mocks base method.
@@ -65,6 +65,21 @@ func (mr *MockWithdrawalRepositoryMockRecorder) IsExist(ctx, withdraw interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsExist", reflect.TypeOf((*MockWithdrawalRepository)(nil).IsExist), ctx, withdraw)
 }
 
+// Refund mocks base method.
+func (m *MockWithdrawalRepository) Refund(ctx context.Context, login, orderNumber string) (*models.Withdrawal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Refund", ctx, login, orderNumber)
+	ret0, _ := ret[0].(*models.Withdrawal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Refund indicates an expected call of Refund.
+func (mr *MockWithdrawalRepositoryMockRecorder) Refund(ctx, login, orderNumber interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Refund", reflect.TypeOf((*MockWithdrawalRepository)(nil).Refund), ctx, login, orderNumber)
+}
+
 // Withdraw mocks base method.
 func (m *MockWithdrawalRepository) Withdraw(ctx context.Context, withdraw *models.Withdrawal) error {
 	m.ctrl.T.Helper()
@@ -3,13 +3,18 @@ package domain
 import (
 	"context"
 	"github.com/Aleksei-D/go-loyalty-system/internal/models"
+	"time"
 )
 
 type OrderRepository interface {
 	Add(ctx context.Context, login, orderNumber string) (*models.Order, error)
-	GetAllByLogin(ctx context.Context, login string) ([]*models.Order, error)
+	GetAllByLogin(ctx context.Context, login string, filter *models.ListFilter) ([]*models.Order, error)
 	GetOrderByNumber(ctx context.Context, orderNumber string) (*models.Order, error)
-	GetNotAcceptedOrderNumbers(ctx context.Context, limit, updateTimeout uint) ([]*models.Order, error)
+	ClaimOrders(ctx context.Context, claim *models.OrderClaim) ([]*models.Order, error)
+	GetParkedOrders(ctx context.Context) ([]*models.ParkedOrder, error)
+	UnparkOrder(ctx context.Context, orderNumber string) error
 	UpdateStatus(ctx context.Context, order *models.Order) error
+	GetStatusHistory(ctx context.Context, orderNumber string) ([]*models.OrderStatusChange, error)
 	IsExist(ctx context.Context, orderNumber string) (bool, error)
+	GetAccruedTotal(ctx context.Context, login string, since time.Time) (models.Money, error)
 }
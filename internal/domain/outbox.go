@@ -0,0 +1,13 @@
+package domain
+
+import (
+	"context"
+	"github.com/Aleksei-D/go-loyalty-system/internal/models"
+	"time"
+)
+
+type OutboxRepository interface {
+	Claim(ctx context.Context, limit uint, lease time.Duration) ([]*models.Event, error)
+	MarkDelivered(ctx context.Context, id int64) error
+	MarkFailed(ctx context.Context, id int64, retryAt time.Time, reason string) error
+}
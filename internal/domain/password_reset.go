@@ -0,0 +1,11 @@
+package domain
+
+import (
+	"context"
+	"github.com/Aleksei-D/go-loyalty-system/internal/models"
+)
+
+type PasswordResetRepository interface {
+	SaveResetToken(ctx context.Context, token *models.PasswordResetToken) error
+	ConsumeResetToken(ctx context.Context, hash string) (*models.PasswordResetToken, error)
+}
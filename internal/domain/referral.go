@@ -0,0 +1,12 @@
+package domain
+
+import (
+	"context"
+	"github.com/Aleksei-D/go-loyalty-system/internal/models"
+)
+
+type ReferralRepository interface {
+	GetAllByReferrer(ctx context.Context, referrer string) ([]*models.Referral, error)
+	GetByReferee(ctx context.Context, referee string) (*models.Referral, error)
+	Reward(ctx context.Context, reward *models.ReferralReward) error
+}
@@ -0,0 +1,15 @@
+package domain
+
+import (
+	"context"
+	"github.com/Aleksei-D/go-loyalty-system/internal/models"
+	"time"
+)
+
+type TokenRepository interface {
+	SaveRefreshToken(ctx context.Context, token *models.RefreshToken) error
+	RotateRefreshToken(ctx context.Context, oldHash string, newToken *models.RefreshToken) (*models.RefreshToken, error)
+	RevokeSession(ctx context.Context, accessID string, accessExpiresAt time.Time) error
+	IsRevoked(ctx context.Context, accessID string) (bool, error)
+	RevokeUserSessions(ctx context.Context, login string) error
+}
@@ -3,10 +3,19 @@ package domain
 import (
 	"context"
 	"github.com/Aleksei-D/go-loyalty-system/internal/models"
+	"time"
 )
 
 type UserRepository interface {
 	Create(ctx context.Context, user *models.User) (*models.User, error)
 	GetByLogin(ctx context.Context, login string) (*models.User, error)
 	IsExist(ctx context.Context, login string) (bool, error)
+	RegisterFailedLogin(ctx context.Context, login string, maxAttempts uint, lockout time.Duration) (*time.Time, error)
+	ResetFailedLogins(ctx context.Context, login string) error
+	UpdatePassword(ctx context.Context, login, passwordHash string) error
+	GetAll(ctx context.Context) ([]*models.User, error)
+	SetBlocked(ctx context.Context, login string, blocked bool) error
+	GrantRole(ctx context.Context, login string, role models.Role) error
+	SetTier(ctx context.Context, login, tier string) error
+	GetByReferralCode(ctx context.Context, code string) (*models.User, error)
 }
@@ -0,0 +1,18 @@
+package domain
+
+import (
+	"context"
+	"github.com/Aleksei-D/go-loyalty-system/internal/models"
+	"time"
+)
+
+type WebhookRepository interface {
+	CreateSubscription(ctx context.Context, subscription *models.WebhookSubscription) error
+	GetSubscriptions(ctx context.Context) ([]*models.WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, id string) error
+	Enqueue(ctx context.Context, deliveries []*models.WebhookDelivery) error
+	ClaimDeliveries(ctx context.Context, limit uint, lease time.Duration) ([]*models.WebhookDelivery, error)
+	SaveAttempt(ctx context.Context, delivery *models.WebhookDelivery) error
+	GetDeliveries(ctx context.Context, subscriptionID string, status models.WebhookDeliveryStatus) ([]*models.WebhookDelivery, error)
+	Requeue(ctx context.Context, subscriptionID string, id int64) error
+}
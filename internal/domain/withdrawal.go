@@ -6,7 +6,8 @@ import (
 )
 
 type WithdrawalRepository interface {
-	GetAllByLogin(ctx context.Context, login string) ([]*models.Withdrawal, error)
+	GetAllByLogin(ctx context.Context, login string, filter *models.ListFilter) ([]*models.Withdrawal, error)
 	Withdraw(ctx context.Context, withdraw *models.Withdrawal) error
 	IsExist(ctx context.Context, withdraw *models.Withdrawal) (bool, error)
+	Refund(ctx context.Context, login, orderNumber string) (*models.Withdrawal, error)
 }
@@ -0,0 +1,128 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/Aleksei-D/go-loyalty-system/internal/logger"
+	"github.com/Aleksei-D/go-loyalty-system/internal/models"
+	"go.uber.org/zap"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const httpSinkTimeout = 10 * time.Second
+
+// Sink receives outbox events. Delivery is at least once, so sinks see an event again after a failed attempt
+// and consumers should deduplicate by event id.
+type Sink interface {
+	Deliver(ctx context.Context, event *models.Event) error
+}
+
+// ParseSinks builds sinks from a comma separated list of "log", "file:<path>" and http(s) URLs.
+func ParseSinks(spec string) ([]Sink, error) {
+	sinks := make([]Sink, 0)
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		switch {
+		case item == "":
+		case item == "log":
+			sinks = append(sinks, NewLogSink())
+		case strings.HasPrefix(item, "file:"):
+			sinks = append(sinks, NewFileSink(strings.TrimPrefix(item, "file:")))
+		case strings.HasPrefix(item, "http://"), strings.HasPrefix(item, "https://"):
+			sinks = append(sinks, NewHTTPSink(item, &http.Client{Timeout: httpSinkTimeout}))
+		default:
+			return nil, fmt.Errorf("unknown event sink %q", item)
+		}
+	}
+	return sinks, nil
+}
+
+type LogSink struct{}
+
+func NewLogSink() *LogSink {
+	return &LogSink{}
+}
+
+func (l *LogSink) Deliver(_ context.Context, event *models.Event) error {
+	logger.Log.Info(
+		"event",
+		zap.Int64("id", event.ID),
+		zap.String("type", string(event.Type)),
+		zap.String("login", event.Login),
+		zap.String("key", event.Key),
+		zap.ByteString("payload", event.Payload),
+	)
+	return nil
+}
+
+// FileSink appends every event as a JSON line to a local file.
+type FileSink struct {
+	mu   sync.Mutex
+	path string
+}
+
+func NewFileSink(path string) *FileSink {
+	return &FileSink{path: path}
+}
+
+func (f *FileSink) Deliver(_ context.Context, event *models.Event) error {
+	buf, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	file, err := os.OpenFile(f.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(buf, '\n'))
+	return err
+}
+
+// HTTPSink posts every event as JSON to a URL; any non-2xx answer is a failed delivery.
+type HTTPSink struct {
+	url    string
+	client *http.Client
+}
+
+func NewHTTPSink(url string, client *http.Client) *HTTPSink {
+	return &HTTPSink{url: url, client: client}
+}
+
+func (h *HTTPSink) Deliver(ctx context.Context, event *models.Event) error {
+	buf, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.url, bytes.NewReader(buf))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-ID", strconv.FormatInt(event.ID, 10))
+	req.Header.Set("X-Event-Type", string(event.Type))
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("event sink %s answered %s", h.url, resp.Status)
+	}
+	return nil
+}
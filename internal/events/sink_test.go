@@ -0,0 +1,72 @@
+package events
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"github.com/Aleksei-D/go-loyalty-system/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseSinks(t *testing.T) {
+	sinks, err := ParseSinks("log, file:/tmp/events.jsonl,https://example.com/hook,")
+	require.NoError(t, err)
+	require.Len(t, sinks, 3)
+	assert.IsType(t, &LogSink{}, sinks[0])
+	assert.IsType(t, &FileSink{}, sinks[1])
+	assert.IsType(t, &HTTPSink{}, sinks[2])
+
+	_, err = ParseSinks("kafka://broker")
+	assert.Error(t, err)
+}
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	sink := NewFileSink(path)
+	event, err := models.NewEvent(models.EventOrderAccepted, "alice", "12345678903", &models.OrderAcceptedPayload{Order: "12345678903"})
+	require.NoError(t, err)
+
+	require.NoError(t, sink.Deliver(context.Background(), event))
+	require.NoError(t, sink.Deliver(context.Background(), event))
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	lines := 0
+	for scanner := bufio.NewScanner(file); scanner.Scan(); lines++ {
+		var delivered models.Event
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &delivered))
+		assert.Equal(t, models.EventOrderAccepted, delivered.Type)
+		assert.JSONEq(t, string(event.Payload), string(delivered.Payload))
+	}
+	assert.Equal(t, 2, lines)
+}
+
+func TestHTTPSink(t *testing.T) {
+	status := http.StatusOK
+	var received models.Event
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "7", r.Header.Get("X-Event-ID"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	sink := NewHTTPSink(server.URL, server.Client())
+	event, err := models.NewEvent(models.EventWithdrawalMade, "alice", "2377225624", &models.WithdrawalPayload{Order: "2377225624", Sum: 100})
+	require.NoError(t, err)
+	event.ID = 7
+
+	require.NoError(t, sink.Deliver(context.Background(), event))
+	assert.Equal(t, models.EventWithdrawalMade, received.Type)
+
+	status = http.StatusServiceUnavailable
+	assert.Error(t, sink.Deliver(context.Background(), event))
+}
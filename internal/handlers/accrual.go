@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/Aleksei-D/go-loyalty-system/internal/service"
+	"github.com/Aleksei-D/go-loyalty-system/internal/utils/common"
+	"io"
+	"net/http"
+)
+
+const (
+	AccrualSignatureHeader = "X-Accrual-Signature"
+	accrualCallbackLimit   = 64 << 10
+)
+
+type AccrualHandler struct {
+	as *service.AccrualCallbackService
+}
+
+func NewAccrualHandler(as *service.AccrualCallbackService) *AccrualHandler {
+	return &AccrualHandler{as: as}
+}
+
+func (a *AccrualHandler) APIAccrualCallbackHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		buf, err := io.ReadAll(io.LimitReader(r.Body, accrualCallbackLimit))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer r.Body.Close()
+
+		_, err = a.as.Handle(r.Context(), r.Header.Get(AccrualSignatureHeader), buf)
+		if err != nil {
+			switch {
+			case errors.Is(err, common.ErrCallbackDisabled):
+				http.Error(w, err.Error(), http.StatusNotFound)
+			case errors.Is(err, common.ErrInvalidSignature):
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+			case errors.Is(err, common.ErrInvalidCallback):
+				http.Error(w, err.Error(), http.StatusBadRequest)
+			case errors.Is(err, common.ErrOrderNotFound):
+				http.Error(w, err.Error(), http.StatusNotFound)
+			case errors.Is(err, common.ErrIllegalStatusTransition):
+				http.Error(w, err.Error(), http.StatusConflict)
+			default:
+				http.Error(w, "server error", http.StatusInternalServerError)
+			}
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+}
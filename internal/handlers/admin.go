@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/Aleksei-D/go-loyalty-system/internal/service"
+	"github.com/Aleksei-D/go-loyalty-system/internal/utils/common"
+	"github.com/Aleksei-D/go-loyalty-system/internal/utils/crypto"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+)
+
+type AdminHandlers struct {
+	as *service.AdminService
+}
+
+func NewAdminHandlers(as *service.AdminService) *AdminHandlers {
+	return &AdminHandlers{as}
+}
+
+// UserContext resolves {login} and makes it the current login, so the regular
+// user handlers serve the data of the inspected user.
+func (a *AdminHandlers) UserContext(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		login := chi.URLParam(r, "login")
+		if _, err := a.as.GetUser(r.Context(), login); err != nil {
+			writeAdminError(w, err)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), common.LoginKey("login"), login)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func (a *AdminHandlers) APIGetUsersHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		users, err := a.as.ListUsers(r.Context())
+		if err != nil {
+			http.Error(w, "server error", http.StatusInternalServerError)
+			return
+		}
+
+		usersJSON, err := json.Marshal(users)
+		if err != nil {
+			http.Error(w, "invalid marshaling", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(usersJSON)
+	}
+}
+
+func (a *AdminHandlers) APIGetUserHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, err := a.as.GetUser(r.Context(), chi.URLParam(r, "login"))
+		if err != nil {
+			writeAdminError(w, err)
+			return
+		}
+
+		userJSON, err := json.Marshal(user)
+		if err != nil {
+			http.Error(w, "invalid marshaling", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(userJSON)
+	}
+}
+
+func (a *AdminHandlers) APIBlockUserHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := r.Context().Value(common.LoginKey("claims")).(*crypto.Claims)
+		if !ok {
+			http.Error(w, "Not authorized", http.StatusUnauthorized)
+			return
+		}
+
+		if err := a.as.Block(r.Context(), claims.Login, chi.URLParam(r, "login")); err != nil {
+			writeAdminError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+func (a *AdminHandlers) APIUnblockUserHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := r.Context().Value(common.LoginKey("claims")).(*crypto.Claims)
+		if !ok {
+			http.Error(w, "Not authorized", http.StatusUnauthorized)
+			return
+		}
+
+		if err := a.as.Unblock(r.Context(), claims.Login, chi.URLParam(r, "login")); err != nil {
+			writeAdminError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+func writeAdminError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, common.ErrUserNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, common.ErrSelfBlock):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, "server error", http.StatusInternalServerError)
+	}
+}
@@ -2,8 +2,12 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"github.com/Aleksei-D/go-loyalty-system/internal/models"
 	"github.com/Aleksei-D/go-loyalty-system/internal/service"
 	"github.com/Aleksei-D/go-loyalty-system/internal/utils/common"
+	"github.com/Aleksei-D/go-loyalty-system/internal/utils/crypto"
+	"io"
 	"net/http"
 )
 
@@ -40,3 +44,84 @@ func (b *BalanceHandler) APIGetBalanceHandler() func(http.ResponseWriter, *http.
 		w.Write(balanceJSON)
 	}
 }
+
+func (b *BalanceHandler) APIGetBalanceHistoryHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		login, ok := r.Context().Value(common.LoginKey("login")).(string)
+		if !ok {
+			http.Error(w, "Not authorized", http.StatusUnauthorized)
+			return
+		}
+
+		entries, err := b.bs.GetHistory(r.Context(), login)
+		if err != nil {
+			if errors.Is(err, common.ErrNoContent) {
+				http.Error(w, "balance history not found", http.StatusNoContent)
+			} else {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+
+		entriesJSON, err := json.Marshal(entries)
+		if err != nil {
+			http.Error(w, "invalid marshaling", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(entriesJSON)
+	}
+}
+
+func (b *BalanceHandler) APIAdjustBalanceHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		login, ok := r.Context().Value(common.LoginKey("login")).(string)
+		if !ok {
+			http.Error(w, "Not authorized", http.StatusUnauthorized)
+			return
+		}
+		claims, ok := r.Context().Value(common.LoginKey("claims")).(*crypto.Claims)
+		if !ok {
+			http.Error(w, "Not authorized", http.StatusUnauthorized)
+			return
+		}
+
+		var request models.AdjustmentRequest
+		buf, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer r.Body.Close()
+
+		if err = json.Unmarshal(buf, &request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		adjustment, err := b.bs.Adjust(r.Context(), claims.Login, login, &request)
+		if err != nil {
+			switch {
+			case errors.Is(err, common.ErrInvalidAdjustment):
+				http.Error(w, err.Error(), http.StatusBadRequest)
+			case errors.Is(err, common.ErrNegativeBalance):
+				http.Error(w, err.Error(), http.StatusConflict)
+			default:
+				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			}
+			return
+		}
+
+		adjustmentJSON, err := json.Marshal(adjustment)
+		if err != nil {
+			http.Error(w, "invalid marshaling", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(adjustmentJSON)
+	}
+}
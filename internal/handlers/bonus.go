@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/Aleksei-D/go-loyalty-system/internal/models"
+	"github.com/Aleksei-D/go-loyalty-system/internal/service"
+	"github.com/Aleksei-D/go-loyalty-system/internal/utils/common"
+	"io"
+	"net/http"
+)
+
+type BonusHandler struct {
+	bs *service.BonusService
+}
+
+func NewBonusHandler(bs *service.BonusService) *BonusHandler {
+	return &BonusHandler{bs: bs}
+}
+
+func (b *BonusHandler) APIGetBonusRulesHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeBonusRules(w, b.bs.Rules())
+	}
+}
+
+func (b *BonusHandler) APIUpdateBonusRulesHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var rules models.BonusRules
+		buf, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer r.Body.Close()
+
+		if err = json.Unmarshal(buf, &rules); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if err = b.bs.SetRules(&rules); err != nil {
+			if errors.Is(err, common.ErrInvalidBonusRules) {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+		writeBonusRules(w, &rules)
+	}
+}
+
+func writeBonusRules(w http.ResponseWriter, rules *models.BonusRules) {
+	rulesJSON, err := json.Marshal(rules)
+	if err != nil {
+		http.Error(w, "invalid marshaling", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(rulesJSON)
+}
@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"encoding/json"
+	"github.com/Aleksei-D/go-loyalty-system/internal/service"
+	"net/http"
+)
+
+type KeysHandler struct {
+	ts *service.TokenService
+}
+
+func NewKeysHandler(ts *service.TokenService) *KeysHandler {
+	return &KeysHandler{ts: ts}
+}
+
+func (k *KeysHandler) APIGetJWKSHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		jwksJSON, err := json.Marshal(k.ts.JWKS())
+		if err != nil {
+			http.Error(w, "invalid marshaling", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(jwksJSON)
+	}
+}
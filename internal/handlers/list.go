@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"fmt"
+	"github.com/Aleksei-D/go-loyalty-system/internal/models"
+	"github.com/Aleksei-D/go-loyalty-system/internal/utils/common"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	listLimitDefault = 100
+	listLimitMax     = 1000
+)
+
+// parseListFilter leaves the list unbounded unless the client asks for a page with limit or cursor.
+func parseListFilter(r *http.Request) (*models.ListFilter, error) {
+	query := r.URL.Query()
+	filter := models.ListFilter{Status: query.Get("status")}
+
+	if limit := query.Get("limit"); limit != "" {
+		value, err := strconv.ParseUint(limit, 10, 32)
+		if err != nil || value == 0 || value > listLimitMax {
+			return nil, fmt.Errorf("%w: limit must be between 1 and %d", common.ErrInvalidListFilter, listLimitMax)
+		}
+		filter.Limit = uint(value)
+	}
+
+	if cursor := query.Get("cursor"); cursor != "" {
+		listCursor, err := models.DecodeListCursor(cursor)
+		if err != nil {
+			return nil, fmt.Errorf("%w: malformed cursor", common.ErrInvalidListFilter)
+		}
+		filter.Cursor = listCursor
+		if filter.Limit == 0 {
+			filter.Limit = listLimitDefault
+		}
+	}
+
+	for name, target := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		value := query.Get(name)
+		if value == "" {
+			continue
+		}
+		date, err := parseListDate(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s must be RFC 3339 time or YYYY-MM-DD date", common.ErrInvalidListFilter, name)
+		}
+		*target = &date
+	}
+	return &filter, nil
+}
+
+func parseListDate(value string) (time.Time, error) {
+	date, err := time.Parse(time.RFC3339, value)
+	if err == nil {
+		return date, nil
+	}
+	return time.Parse(time.DateOnly, value)
+}
+
+func setNextPageHeaders(w http.ResponseWriter, r *http.Request, nextCursor string) {
+	if nextCursor == "" {
+		return
+	}
+
+	nextURL := *r.URL
+	query := nextURL.Query()
+	query.Set("cursor", nextCursor)
+	nextURL.RawQuery = query.Encode()
+	w.Header().Set("Link", fmt.Sprintf(`<%s>; rel="next"`, nextURL.RequestURI()))
+	w.Header().Set("X-Next-Cursor", nextCursor)
+}
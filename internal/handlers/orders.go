@@ -5,6 +5,7 @@ import (
 	"errors"
 	"github.com/Aleksei-D/go-loyalty-system/internal/service"
 	"github.com/Aleksei-D/go-loyalty-system/internal/utils/common"
+	"github.com/go-chi/chi/v5"
 	"io"
 	"net/http"
 )
@@ -59,13 +60,110 @@ func (o *OrderHandlers) APIGetOrdersHandler() func(http.ResponseWriter, *http.Re
 			return
 		}
 
-		orders, err := o.orderService.GetAllByLogin(r.Context(), login)
+		filter, err := parseListFilter(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		orders, nextCursor, err := o.orderService.GetAllByLogin(r.Context(), login, filter)
+		if err != nil {
+			if errors.Is(err, common.ErrInvalidListFilter) {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+			} else {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
 		if len(orders) == 0 {
 			http.Error(w, "Orders if not load", http.StatusNoContent)
 			return
 		}
+
+		ordersJSON, err := json.Marshal(orders)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			http.Error(w, "invalid marshaling", http.StatusInternalServerError)
+			return
+		}
+
+		setNextPageHeaders(w, r, nextCursor)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(ordersJSON)
+	}
+}
+
+func (o *OrderHandlers) APIGetOrderHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		login, ok := r.Context().Value(common.LoginKey("login")).(string)
+		if !ok {
+			http.Error(w, "Not authorized", http.StatusUnauthorized)
+			return
+		}
+
+		order, err := o.orderService.GetOrder(r.Context(), login, chi.URLParam(r, "number"))
+		if err != nil {
+			switch {
+			case errors.Is(err, common.ErrOrderNotFound):
+				http.Error(w, err.Error(), http.StatusNotFound)
+			case errors.Is(err, common.ErrOrderBelongAnotherUser):
+				http.Error(w, err.Error(), http.StatusForbidden)
+			default:
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+
+		orderJSON, err := json.Marshal(order)
+		if err != nil {
+			http.Error(w, "invalid marshaling", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(orderJSON)
+	}
+}
+
+func (o *OrderHandlers) APIGetOrderHistoryHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		login, ok := r.Context().Value(common.LoginKey("login")).(string)
+		if !ok {
+			http.Error(w, "Not authorized", http.StatusUnauthorized)
+			return
+		}
+
+		history, err := o.orderService.GetStatusHistory(r.Context(), login, chi.URLParam(r, "number"))
+		if err != nil {
+			switch {
+			case errors.Is(err, common.ErrOrderNotFound):
+				http.Error(w, err.Error(), http.StatusNotFound)
+			case errors.Is(err, common.ErrOrderBelongAnotherUser):
+				http.Error(w, err.Error(), http.StatusForbidden)
+			default:
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+
+		historyJSON, err := json.Marshal(history)
+		if err != nil {
+			http.Error(w, "invalid marshaling", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(historyJSON)
+	}
+}
+
+func (o *OrderHandlers) APIGetParkedOrdersHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		orders, err := o.orderService.GetParkedOrders(r.Context())
+		if err != nil {
+			http.Error(w, "server error", http.StatusInternalServerError)
 			return
 		}
 
@@ -80,3 +178,21 @@ func (o *OrderHandlers) APIGetOrdersHandler() func(http.ResponseWriter, *http.Re
 		w.Write(ordersJSON)
 	}
 }
+
+func (o *OrderHandlers) APIUnparkOrderHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		err := o.orderService.UnparkOrder(r.Context(), chi.URLParam(r, "number"))
+		if err != nil {
+			switch {
+			case errors.Is(err, common.ErrOrderNotFound):
+				http.Error(w, err.Error(), http.StatusNotFound)
+			case errors.Is(err, common.ErrOrderNotParked):
+				http.Error(w, err.Error(), http.StatusConflict)
+			default:
+				http.Error(w, "server error", http.StatusInternalServerError)
+			}
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/Aleksei-D/go-loyalty-system/internal/models"
+	"github.com/Aleksei-D/go-loyalty-system/internal/service"
+	"github.com/Aleksei-D/go-loyalty-system/internal/utils/common"
+	"io"
+	"net/http"
+)
+
+type PasswordHandlers struct {
+	ps *service.PasswordService
+	ts *service.TokenService
+}
+
+func NewPasswordHandlers(ps *service.PasswordService, ts *service.TokenService) *PasswordHandlers {
+	return &PasswordHandlers{ps, ts}
+}
+
+func (p *PasswordHandlers) APIChangePasswordHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		login, ok := r.Context().Value(common.LoginKey("login")).(string)
+		if !ok {
+			http.Error(w, "Not authorized", http.StatusUnauthorized)
+			return
+		}
+
+		var request models.PasswordChangeRequest
+		buf, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer r.Body.Close()
+
+		if err = json.Unmarshal(buf, &request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		err = p.ps.ChangePassword(r.Context(), login, &request)
+		if err != nil {
+			writePasswordError(w, err)
+			return
+		}
+
+		tokenPair, err := p.ts.Issue(r.Context(), login)
+		if err != nil {
+			http.Error(w, "Failed to generate token", http.StatusInternalServerError)
+			return
+		}
+		writeTokenPair(w, tokenPair)
+	}
+}
+
+func (p *PasswordHandlers) APIPasswordResetHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request models.PasswordResetRequest
+		buf, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer r.Body.Close()
+
+		if err = json.Unmarshal(buf, &request); err != nil || request.Login == "" {
+			http.Error(w, "invalid request", http.StatusBadRequest)
+			return
+		}
+
+		if err = p.ps.RequestReset(r.Context(), request.Login); err != nil {
+			http.Error(w, "server error", http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+func (p *PasswordHandlers) APIPasswordResetConfirmHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request models.PasswordResetConfirmRequest
+		buf, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer r.Body.Close()
+
+		if err = json.Unmarshal(buf, &request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if err = p.ps.ConfirmReset(r.Context(), &request); err != nil {
+			writePasswordError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+func writePasswordError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, common.ErrInvalidCredentials):
+		http.Error(w, "Invalid credentials", http.StatusUnauthorized)
+	case errors.Is(err, common.ErrInvalidResetToken):
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+	case errors.Is(err, common.ErrWeakPassword):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, "server error", http.StatusInternalServerError)
+	}
+}
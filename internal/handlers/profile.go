@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"encoding/json"
+	"github.com/Aleksei-D/go-loyalty-system/internal/service"
+	"github.com/Aleksei-D/go-loyalty-system/internal/utils/common"
+	"net/http"
+)
+
+type ProfileHandler struct {
+	ts *service.TierService
+}
+
+func NewProfileHandler(ts *service.TierService) *ProfileHandler {
+	return &ProfileHandler{ts: ts}
+}
+
+func (p *ProfileHandler) APIGetProfileHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		login, ok := r.Context().Value(common.LoginKey("login")).(string)
+		if !ok {
+			http.Error(w, "Not authorized", http.StatusUnauthorized)
+			return
+		}
+
+		profile, err := p.ts.Profile(r.Context(), login)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		profileJSON, err := json.Marshal(profile)
+		if err != nil {
+			http.Error(w, "invalid marshaling", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(profileJSON)
+	}
+}
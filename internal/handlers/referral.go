@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"encoding/json"
+	"github.com/Aleksei-D/go-loyalty-system/internal/service"
+	"github.com/Aleksei-D/go-loyalty-system/internal/utils/common"
+	"net/http"
+)
+
+type ReferralHandler struct {
+	rs *service.ReferralService
+}
+
+func NewReferralHandler(rs *service.ReferralService) *ReferralHandler {
+	return &ReferralHandler{rs: rs}
+}
+
+func (h *ReferralHandler) APIGetReferralsHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		login, ok := r.Context().Value(common.LoginKey("login")).(string)
+		if !ok {
+			http.Error(w, "Not authorized", http.StatusUnauthorized)
+			return
+		}
+
+		stats, err := h.rs.Stats(r.Context(), login)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		statsJSON, err := json.Marshal(stats)
+		if err != nil {
+			http.Error(w, "invalid marshaling", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(statsJSON)
+	}
+}
@@ -6,17 +6,21 @@ import (
 	"github.com/Aleksei-D/go-loyalty-system/internal/models"
 	"github.com/Aleksei-D/go-loyalty-system/internal/service"
 	"github.com/Aleksei-D/go-loyalty-system/internal/utils/common"
+	"github.com/Aleksei-D/go-loyalty-system/internal/utils/crypto"
 	"io"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 )
 
 type UserHandlers struct {
-	us        *service.UserService
-	secretKey string
+	us *service.UserService
+	ts *service.TokenService
 }
 
-func NewUserHandlers(us *service.UserService, secretKey string) *UserHandlers {
-	return &UserHandlers{us, secretKey}
+func NewUserHandlers(us *service.UserService, ts *service.TokenService) *UserHandlers {
+	return &UserHandlers{us, ts}
 }
 
 func (u *UserHandlers) APIUserRegisterHandler() func(http.ResponseWriter, *http.Request) {
@@ -38,20 +42,17 @@ func (u *UserHandlers) APIUserRegisterHandler() func(http.ResponseWriter, *http.
 			switch {
 			case errors.Is(err, common.ErrUserAlreadyExists):
 				http.Error(w, err.Error(), http.StatusConflict)
+			case errors.Is(err, common.ErrInvalidLogin),
+				errors.Is(err, common.ErrWeakPassword),
+				errors.Is(err, common.ErrInvalidReferralCode):
+				http.Error(w, err.Error(), http.StatusBadRequest)
 			default:
 				http.Error(w, "server error", http.StatusInternalServerError)
 			}
 			return
 		}
 
-		tokenString, err := u.us.GetToken(user.Login, u.secretKey)
-		if err != nil {
-			http.Error(w, "Failed to generate token", http.StatusInternalServerError)
-			return
-		}
-
-		w.Header().Add("Authorization", tokenString)
-		w.WriteHeader(http.StatusOK)
+		u.writeTokenPair(w, r, user.Login)
 	}
 }
 
@@ -75,19 +76,95 @@ func (u *UserHandlers) APIUserLoginHandler() func(http.ResponseWriter, *http.Req
 			switch {
 			case errors.Is(err, common.ErrInvalidCredentials):
 				http.Error(w, "Invalid credentials", http.StatusUnauthorized)
+			case errors.Is(err, common.ErrUserBlocked):
+				http.Error(w, err.Error(), http.StatusForbidden)
+			case errors.Is(err, common.ErrAccountLocked):
+				var lockedErr *common.AccountLockedError
+				if errors.As(err, &lockedErr) {
+					retryAfter := int(math.Ceil(time.Until(lockedErr.Until).Seconds()))
+					w.Header().Set("Retry-After", strconv.Itoa(max(retryAfter, 1)))
+				}
+				http.Error(w, err.Error(), http.StatusLocked)
 			default:
 				http.Error(w, "server error", http.StatusInternalServerError)
 			}
 			return
 		}
 
-		tokenString, err := u.us.GetToken(loginUser.Login, u.secretKey)
+		u.writeTokenPair(w, r, loginUser.Login)
+	}
+}
+
+func (u *UserHandlers) APITokenRefreshHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var refreshRequest models.RefreshRequest
+		buf, err := io.ReadAll(r.Body)
 		if err != nil {
-			http.Error(w, "Failed to generate token", http.StatusInternalServerError)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer r.Body.Close()
+
+		if err = json.Unmarshal(buf, &refreshRequest); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		tokenPair, err := u.ts.Refresh(r.Context(), refreshRequest.RefreshToken)
+		if err != nil {
+			switch {
+			case errors.Is(err, common.ErrInvalidRefreshToken), errors.Is(err, common.ErrRefreshTokenReused):
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+			case errors.Is(err, common.ErrUserBlocked):
+				http.Error(w, err.Error(), http.StatusForbidden)
+			default:
+				http.Error(w, "server error", http.StatusInternalServerError)
+			}
 			return
 		}
 
-		w.Header().Add("Authorization", tokenString)
+		writeTokenPair(w, tokenPair)
+	}
+}
+
+func (u *UserHandlers) APIUserLogoutHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := r.Context().Value(common.LoginKey("claims")).(*crypto.Claims)
+		if !ok {
+			http.Error(w, "Not authorized", http.StatusUnauthorized)
+			return
+		}
+
+		if err := u.ts.Logout(r.Context(), claims); err != nil {
+			http.Error(w, "server error", http.StatusInternalServerError)
+			return
+		}
 		w.WriteHeader(http.StatusOK)
 	}
 }
+
+func (u *UserHandlers) writeTokenPair(w http.ResponseWriter, r *http.Request, login string) {
+	tokenPair, err := u.ts.Issue(r.Context(), login)
+	if err != nil {
+		if errors.Is(err, common.ErrUserBlocked) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		http.Error(w, "Failed to generate token", http.StatusInternalServerError)
+		return
+	}
+	writeTokenPair(w, tokenPair)
+}
+
+func writeTokenPair(w http.ResponseWriter, tokenPair *models.TokenPair) {
+	tokenPairJSON, err := json.Marshal(tokenPair)
+	if err != nil {
+		http.Error(w, "invalid marshaling", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Add("Authorization", tokenPair.AccessToken)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(tokenPairJSON)
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/Aleksei-D/go-loyalty-system/internal/models"
+	"github.com/Aleksei-D/go-loyalty-system/internal/service"
+	"github.com/Aleksei-D/go-loyalty-system/internal/utils/common"
+	"github.com/go-chi/chi/v5"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+type WebhookHandler struct {
+	ws *service.WebhookService
+}
+
+func NewWebhookHandler(ws *service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{ws: ws}
+}
+
+func (wh *WebhookHandler) APIGetWebhooksHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		subscriptions, err := wh.ws.List(r.Context())
+		if err != nil {
+			writeWebhookError(w, err)
+			return
+		}
+		writeWebhookJSON(w, http.StatusOK, subscriptions)
+	}
+}
+
+func (wh *WebhookHandler) APICreateWebhookHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var subscription models.WebhookSubscription
+		buf, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer r.Body.Close()
+
+		if err = json.Unmarshal(buf, &subscription); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if err = wh.ws.Create(r.Context(), &subscription); err != nil {
+			writeWebhookError(w, err)
+			return
+		}
+		writeWebhookJSON(w, http.StatusCreated, &subscription)
+	}
+}
+
+func (wh *WebhookHandler) APIDeleteWebhookHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := wh.ws.Delete(r.Context(), chi.URLParam(r, "id")); err != nil {
+			writeWebhookError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (wh *WebhookHandler) APIGetDeliveriesHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		status := models.WebhookDeliveryStatus(r.URL.Query().Get("status"))
+		deliveries, err := wh.ws.Deliveries(r.Context(), chi.URLParam(r, "id"), status)
+		if err != nil {
+			writeWebhookError(w, err)
+			return
+		}
+		writeWebhookJSON(w, http.StatusOK, deliveries)
+	}
+}
+
+func (wh *WebhookHandler) APIRetryDeliveryHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		deliveryID, err := strconv.ParseInt(chi.URLParam(r, "delivery"), 10, 64)
+		if err != nil {
+			http.Error(w, "invalid delivery id", http.StatusBadRequest)
+			return
+		}
+
+		if err = wh.ws.Retry(r.Context(), chi.URLParam(r, "id"), deliveryID); err != nil {
+			writeWebhookError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+func writeWebhookJSON(w http.ResponseWriter, status int, value any) {
+	buf, err := json.Marshal(value)
+	if err != nil {
+		http.Error(w, "invalid marshaling", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(buf)
+}
+
+func writeWebhookError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, common.ErrInvalidWebhook):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, common.ErrWebhookNotFound), errors.Is(err, common.ErrWebhookDeliveryNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		http.Error(w, "server error", http.StatusInternalServerError)
+	}
+}
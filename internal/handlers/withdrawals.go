@@ -6,6 +6,7 @@ import (
 	"github.com/Aleksei-D/go-loyalty-system/internal/models"
 	"github.com/Aleksei-D/go-loyalty-system/internal/service"
 	"github.com/Aleksei-D/go-loyalty-system/internal/utils/common"
+	"github.com/go-chi/chi/v5"
 	"io"
 	"net/http"
 )
@@ -45,6 +46,8 @@ func (wh *WithdrawHandlers) APIWithdrawHandler() func(http.ResponseWriter, *http
 				http.Error(w, err.Error(), http.StatusUnprocessableEntity)
 			case errors.Is(err, common.ErrOrderAlreadyAdded):
 				http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			case errors.Is(err, common.ErrInvalidSum):
+				http.Error(w, err.Error(), http.StatusBadRequest)
 			case errors.Is(err, common.ErrPaymentInsufficient):
 				http.Error(w, err.Error(), http.StatusPaymentRequired)
 			default:
@@ -65,11 +68,20 @@ func (wh *WithdrawHandlers) APIGetWithdrawalsHandler() func(http.ResponseWriter,
 			return
 		}
 
-		withdrawals, err := wh.ws.GetAllByLogin(r.Context(), login)
+		filter, err := parseListFilter(r)
 		if err != nil {
-			if errors.Is(err, common.ErrNoContent) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		withdrawals, nextCursor, err := wh.ws.GetAllByLogin(r.Context(), login, filter)
+		if err != nil {
+			switch {
+			case errors.Is(err, common.ErrNoContent):
 				http.Error(w, "withdrawals not found", http.StatusNoContent)
-			} else {
+			case errors.Is(err, common.ErrInvalidListFilter):
+				http.Error(w, err.Error(), http.StatusBadRequest)
+			default:
 				http.Error(w, err.Error(), http.StatusInternalServerError)
 			}
 			return
@@ -81,8 +93,165 @@ func (wh *WithdrawHandlers) APIGetWithdrawalsHandler() func(http.ResponseWriter,
 			return
 		}
 
+		setNextPageHeaders(w, r, nextCursor)
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
 		w.Write(ordersJSON)
 	}
 }
+
+func (wh *WithdrawHandlers) APIRefundWithdrawalHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		login, ok := r.Context().Value(common.LoginKey("login")).(string)
+		if !ok {
+			http.Error(w, "Not authorized", http.StatusUnauthorized)
+			return
+		}
+
+		withdrawal, err := wh.ws.Refund(r.Context(), login, chi.URLParam(r, "order"))
+		if err != nil {
+			switch {
+			case errors.Is(err, common.ErrWithdrawalNotFound):
+				http.Error(w, err.Error(), http.StatusNotFound)
+			case errors.Is(err, common.ErrWithdrawalRefunded):
+				http.Error(w, err.Error(), http.StatusConflict)
+			default:
+				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			}
+			return
+		}
+
+		withdrawalJSON, err := json.Marshal(withdrawal)
+		if err != nil {
+			http.Error(w, "invalid marshaling", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(withdrawalJSON)
+	}
+}
+
+func (wh *WithdrawHandlers) APIHoldHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		login, ok := r.Context().Value(common.LoginKey("login")).(string)
+		if !ok {
+			http.Error(w, "Not authorized", http.StatusUnauthorized)
+			return
+		}
+
+		var request models.HoldRequest
+		buf, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer r.Body.Close()
+
+		if err = json.Unmarshal(buf, &request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		hold, err := wh.ws.Hold(r.Context(), login, &request)
+		if err != nil {
+			writeHoldError(w, err)
+			return
+		}
+		writeHold(w, http.StatusCreated, hold)
+	}
+}
+
+func (wh *WithdrawHandlers) APIGetHoldsHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		login, ok := r.Context().Value(common.LoginKey("login")).(string)
+		if !ok {
+			http.Error(w, "Not authorized", http.StatusUnauthorized)
+			return
+		}
+
+		holds, err := wh.ws.GetHolds(r.Context(), login)
+		if err != nil {
+			if errors.Is(err, common.ErrNoContent) {
+				http.Error(w, "holds not found", http.StatusNoContent)
+			} else {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+
+		holdsJSON, err := json.Marshal(holds)
+		if err != nil {
+			http.Error(w, "invalid marshaling", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(holdsJSON)
+	}
+}
+
+func (wh *WithdrawHandlers) APICaptureHoldHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		login, ok := r.Context().Value(common.LoginKey("login")).(string)
+		if !ok {
+			http.Error(w, "Not authorized", http.StatusUnauthorized)
+			return
+		}
+
+		hold, err := wh.ws.CaptureHold(r.Context(), login, chi.URLParam(r, "id"))
+		if err != nil {
+			writeHoldError(w, err)
+			return
+		}
+		writeHold(w, http.StatusOK, hold)
+	}
+}
+
+func (wh *WithdrawHandlers) APIReleaseHoldHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		login, ok := r.Context().Value(common.LoginKey("login")).(string)
+		if !ok {
+			http.Error(w, "Not authorized", http.StatusUnauthorized)
+			return
+		}
+
+		hold, err := wh.ws.ReleaseHold(r.Context(), login, chi.URLParam(r, "id"))
+		if err != nil {
+			writeHoldError(w, err)
+			return
+		}
+		writeHold(w, http.StatusOK, hold)
+	}
+}
+
+func writeHold(w http.ResponseWriter, status int, hold *models.Hold) {
+	holdJSON, err := json.Marshal(hold)
+	if err != nil {
+		http.Error(w, "invalid marshaling", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(holdJSON)
+}
+
+func writeHoldError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, common.ErrInvalidOrderNumber), errors.Is(err, common.ErrOrderAlreadyAdded):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+	case errors.Is(err, common.ErrInvalidSum):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, common.ErrPaymentInsufficient):
+		http.Error(w, err.Error(), http.StatusPaymentRequired)
+	case errors.Is(err, common.ErrHoldNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, common.ErrHoldNotActive):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
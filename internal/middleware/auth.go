@@ -2,13 +2,16 @@ package middleware
 
 import (
 	"context"
-	"fmt"
 	"github.com/Aleksei-D/go-loyalty-system/internal/utils/common"
-	"github.com/golang-jwt/jwt/v5"
+	"github.com/Aleksei-D/go-loyalty-system/internal/utils/crypto"
 	"net/http"
 )
 
-func AuthMiddleware(secretKey string) func(http.Handler) http.Handler {
+type TokenVerifier interface {
+	Verify(ctx context.Context, tokenString string) (*crypto.Claims, error)
+}
+
+func AuthMiddleware(verifier TokenVerifier) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			authHeader := r.Header.Get("Authorization")
@@ -17,26 +20,15 @@ func AuthMiddleware(secretKey string) func(http.Handler) http.Handler {
 				return
 			}
 
-			token, err := jwt.Parse(authHeader, func(token *jwt.Token) (interface{}, error) {
-				if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-					return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-				}
-				return []byte(secretKey), nil
-			})
-
+			claims, err := verifier.Verify(r.Context(), authHeader)
 			if err != nil {
 				http.Error(w, "Invalid token: "+err.Error(), http.StatusUnauthorized)
 				return
 			}
 
-			if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-				login := claims["login"].(string)
-				ctx := context.WithValue(r.Context(), common.LoginKey("login"), login)
-				next.ServeHTTP(w, r.WithContext(ctx))
-			} else {
-				http.Error(w, "Invalid token claims", http.StatusUnauthorized)
-				return
-			}
+			ctx := context.WithValue(r.Context(), common.LoginKey("login"), claims.Login)
+			ctx = context.WithValue(ctx, common.LoginKey("claims"), claims)
+			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
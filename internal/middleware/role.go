@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"github.com/Aleksei-D/go-loyalty-system/internal/utils/common"
+	"github.com/Aleksei-D/go-loyalty-system/internal/utils/crypto"
+	"net/http"
+)
+
+// RequireRole must run after AuthMiddleware, which puts the token claims into the context.
+func RequireRole(role string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, ok := r.Context().Value(common.LoginKey("claims")).(*crypto.Claims)
+			if !ok {
+				http.Error(w, "Not authorized", http.StatusUnauthorized)
+				return
+			}
+			if !claims.HasRole(role) {
+				http.Error(w, "Forbidden", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package models
+
+import "strconv"
+
+type AdjustmentRequest struct {
+	Amount Money  `json:"amount"`
+	Reason string `json:"reason"`
+}
+
+// Adjustment is a signed manual correction of a user balance made by an operator.
+type Adjustment struct {
+	ID        int64      `json:"id"`
+	Login     string     `json:"login"`
+	Amount    Money      `json:"amount"`
+	Reason    string     `json:"reason"`
+	Operator  string     `json:"operator"`
+	CreatedAt CustomTime `json:"created_at"`
+}
+
+func (a *Adjustment) Reference() string {
+	return "adjustment:" + strconv.FormatInt(a.ID, 10)
+}
+
+func (a *Adjustment) Transaction() *LedgerTransaction {
+	transaction := NewTransfer(PostingKindAdjustment, a.Reference(), AccountAdjustment, UserAccount(a.Login), a.Amount)
+	transaction.Comment = a.Reason
+	return transaction
+}
@@ -1,7 +1,9 @@
 package models
 
 type Balance struct {
-	Login     string  `json:"-"`
-	Current   float64 `json:"current"`
-	Withdrawn float64 `json:"withdrawn"`
+	Login     string            `json:"-"`
+	Current   Money             `json:"current"`
+	Withdrawn Money             `json:"withdrawn"`
+	Held      Money             `json:"held,omitempty"`
+	Expiring  []*ExpiringPoints `json:"expiring,omitempty"`
 }
@@ -0,0 +1,123 @@
+package models
+
+import (
+	"fmt"
+	"github.com/Aleksei-D/go-loyalty-system/internal/utils/common"
+	"strings"
+	"time"
+)
+
+// BonusRule grants a bonus for a processed order that matches all of its conditions.
+// Percent is taken from the accrual, so 50 turns the accrual into a 1.5x multiplier; Amount is a fixed bonus.
+type BonusRule struct {
+	Name       string     `json:"name"`
+	Tier       string     `json:"tier,omitempty"`
+	FirstOrder bool       `json:"first_order,omitempty"`
+	From       *time.Time `json:"from,omitempty"`
+	To         *time.Time `json:"to,omitempty"`
+	Percent    uint       `json:"percent,omitempty"`
+	Amount     Money      `json:"amount,omitempty"`
+}
+
+// BonusRules are evaluated together; DailyCap limits the bonuses a user gets per UTC day, zero means no limit.
+type BonusRules struct {
+	DailyCap Money        `json:"daily_cap,omitempty"`
+	Rules    []*BonusRule `json:"rules"`
+}
+
+// BonusOrder is what the rules know about a processed order.
+type BonusOrder struct {
+	Login       string
+	Number      string
+	Accrual     Money
+	Tier        string
+	FirstOrder  bool
+	ProcessedAt time.Time
+}
+
+type BonusAward struct {
+	Login       string
+	OrderNumber string
+	Amount      Money
+	Rules       []string
+}
+
+func (b *BonusRules) Validate() error {
+	if b.DailyCap < 0 {
+		return fmt.Errorf("%w: negative daily cap", common.ErrInvalidBonusRules)
+	}
+
+	names := make(map[string]bool)
+	for _, rule := range b.Rules {
+		switch {
+		case rule == nil || strings.TrimSpace(rule.Name) == "":
+			return fmt.Errorf("%w: rule name is required", common.ErrInvalidBonusRules)
+		case names[rule.Name]:
+			return fmt.Errorf("%w: duplicate rule %q", common.ErrInvalidBonusRules, rule.Name)
+		case strings.Contains(rule.Name, ","):
+			return fmt.Errorf("%w: rule %q: name cannot contain a comma", common.ErrInvalidBonusRules, rule.Name)
+		case rule.Amount < 0 || (rule.Percent == 0 && rule.Amount == 0):
+			return fmt.Errorf("%w: rule %q grants nothing", common.ErrInvalidBonusRules, rule.Name)
+		case rule.From != nil && rule.To != nil && !rule.From.Before(*rule.To):
+			return fmt.Errorf("%w: rule %q: from must be before to", common.ErrInvalidBonusRules, rule.Name)
+		}
+		names[rule.Name] = true
+	}
+	return nil
+}
+
+func (r *BonusRule) matches(order *BonusOrder) bool {
+	switch {
+	case r.Tier != "" && r.Tier != order.Tier:
+		return false
+	case r.FirstOrder && !order.FirstOrder:
+		return false
+	case r.From != nil && order.ProcessedAt.Before(*r.From):
+		return false
+	case r.To != nil && !order.ProcessedAt.Before(*r.To):
+		return false
+	}
+	return true
+}
+
+func (r *BonusRule) bonus(order *BonusOrder) Money {
+	return order.Accrual*Money(r.Percent)/100 + r.Amount
+}
+
+// Evaluate sums the bonuses of every matching rule, nil means the order earns no bonus.
+// The daily cap is applied by the ledger, which knows what was already awarded that day.
+func (b *BonusRules) Evaluate(order *BonusOrder) *BonusAward {
+	award := &BonusAward{Login: order.Login, OrderNumber: order.Number}
+	for _, rule := range b.Rules {
+		if !rule.matches(order) {
+			continue
+		}
+		if bonus := rule.bonus(order); bonus > 0 {
+			award.Amount += bonus
+			award.Rules = append(award.Rules, rule.Name)
+		}
+	}
+	if award.Amount == 0 {
+		return nil
+	}
+	return award
+}
+
+// Cap trims the award so the bonuses of the day stay within dailyCap.
+func (a *BonusAward) Cap(dailyCap, awardedToday Money) {
+	if dailyCap == 0 {
+		return
+	}
+	a.Amount = min(a.Amount, max(dailyCap-awardedToday, 0))
+}
+
+func (a *BonusAward) Transaction() *LedgerTransaction {
+	transaction := NewTransfer(PostingKindBonus, a.OrderNumber, AccountBonus, UserAccount(a.Login), a.Amount)
+	transaction.Comment = strings.Join(a.Rules, ",")
+	return transaction
+}
+
+// BonusDay is the start of the UTC day the daily cap is counted from.
+func BonusDay(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestBonusRules_Validate(t *testing.T) {
+	from := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 7)
+
+	valid := &BonusRules{DailyCap: 1000, Rules: []*BonusRule{
+		{Name: "gold", Tier: "gold", Percent: 50},
+		{Name: "welcome", FirstOrder: true, Amount: 10000},
+		{Name: "black-friday", From: &from, To: &to, Percent: 100},
+	}}
+	assert.NoError(t, valid.Validate())
+
+	invalid := []*BonusRules{
+		{DailyCap: -1},
+		{Rules: []*BonusRule{{Percent: 10}}},
+		{Rules: []*BonusRule{{Name: "a", Percent: 10}, {Name: "a", Amount: 10}}},
+		{Rules: []*BonusRule{{Name: "a,b", Percent: 10}}},
+		{Rules: []*BonusRule{{Name: "empty"}}},
+		{Rules: []*BonusRule{{Name: "negative", Amount: -10}}},
+		{Rules: []*BonusRule{{Name: "window", From: &to, To: &from, Percent: 10}}},
+	}
+	for _, rules := range invalid {
+		assert.Error(t, rules.Validate())
+	}
+}
+
+func TestBonusRules_Evaluate(t *testing.T) {
+	from := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 7)
+	rules := &BonusRules{Rules: []*BonusRule{
+		{Name: "gold", Tier: "gold", Percent: 50},
+		{Name: "welcome", FirstOrder: true, Amount: 10000},
+		{Name: "black-friday", From: &from, To: &to, Percent: 100},
+	}}
+	order := func(tier string, first bool, at time.Time) *BonusOrder {
+		return &BonusOrder{Login: "alice", Number: "1", Accrual: 2001, Tier: tier, FirstOrder: first, ProcessedAt: at}
+	}
+
+	assert.Nil(t, rules.Evaluate(order("silver", false, to)))
+
+	award := rules.Evaluate(order("gold", true, to))
+	assert.Equal(t, &BonusAward{Login: "alice", OrderNumber: "1", Amount: 1000 + 10000, Rules: []string{"gold", "welcome"}}, award)
+
+	award = rules.Evaluate(order("gold", false, from))
+	assert.Equal(t, Money(1000+2001), award.Amount)
+	assert.Equal(t, []string{"gold", "black-friday"}, award.Rules)
+
+	transaction := award.Transaction()
+	assert.Equal(t, PostingKindBonus, transaction.Kind)
+	assert.Equal(t, "gold,black-friday", transaction.Comment)
+	assert.True(t, transaction.IsBalanced())
+}
+
+func TestBonusAward_Cap(t *testing.T) {
+	award := &BonusAward{Amount: 500}
+	award.Cap(0, 10000)
+	assert.Equal(t, Money(500), award.Amount, "zero cap means no limit")
+
+	award.Cap(1000, 700)
+	assert.Equal(t, Money(300), award.Amount)
+
+	award.Cap(1000, 1200)
+	assert.Equal(t, Money(0), award.Amount)
+}
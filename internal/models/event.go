@@ -0,0 +1,138 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type EventType string
+
+const (
+	EventOrderAccepted      EventType = "order.accepted"
+	EventOrderStatusChanged EventType = "order.status_changed"
+	EventOrderProcessed     EventType = "order.processed"
+	EventOrderInvalid       EventType = "order.invalid"
+	EventOrderParked        EventType = "order.needs_attention"
+	EventPointsCredited     EventType = "points.credited"
+	EventWithdrawalMade     EventType = "withdrawal.made"
+	EventWithdrawalRefunded EventType = "withdrawal.refunded"
+)
+
+// Event is a domain event stored in the outbox by the transaction that caused it.
+// Key is the order number the event is about.
+type Event struct {
+	ID        int64           `json:"id"`
+	Type      EventType       `json:"type"`
+	Login     string          `json:"login"`
+	Key       string          `json:"key"`
+	Payload   json.RawMessage `json:"payload"`
+	CreatedAt CustomTime      `json:"created_at"`
+	Attempts  uint            `json:"-"`
+}
+
+var finalOrderEvents = map[OrderStatus]EventType{
+	OrderStatusProcessed: EventOrderProcessed,
+	OrderStatusInvalid:   EventOrderInvalid,
+}
+
+// OrderAcceptedPayload is also the payload of the final status events.
+type OrderAcceptedPayload struct {
+	Order  string      `json:"order"`
+	Status OrderStatus `json:"status"`
+}
+
+type OrderStatusChangedPayload struct {
+	Order          string      `json:"order"`
+	PreviousStatus OrderStatus `json:"previous_status"`
+	Status         OrderStatus `json:"status"`
+	Accrual        *Money      `json:"accrual,omitempty"`
+}
+
+type OrderParkedPayload struct {
+	Order    string      `json:"order"`
+	Status   OrderStatus `json:"status"`
+	Attempts uint        `json:"attempts"`
+}
+
+type PointsCreditedPayload struct {
+	Order  string `json:"order"`
+	Amount Money  `json:"amount"`
+}
+
+type WithdrawalPayload struct {
+	Order string `json:"order"`
+	Sum   Money  `json:"sum"`
+}
+
+func NewEvent(eventType EventType, login, key string, payload any) (*Event, error) {
+	buf, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return &Event{
+		Type:      eventType,
+		Login:     login,
+		Key:       key,
+		Payload:   buf,
+		CreatedAt: CustomTime{Time: time.Now()},
+	}, nil
+}
+
+func OrderAcceptedEvent(order *Order) (*Event, error) {
+	return NewEvent(EventOrderAccepted, order.Login, order.Number, &OrderAcceptedPayload{
+		Order:  order.Number,
+		Status: order.Status,
+	})
+}
+
+// OrderUpdateEvents are the events of a status change: the change itself, the final status reached if any,
+// and the credit of the accrual.
+func OrderUpdateEvents(login string, previousStatus OrderStatus, order *Order) ([]*Event, error) {
+	changed, err := NewEvent(EventOrderStatusChanged, login, order.Number, &OrderStatusChangedPayload{
+		Order:          order.Number,
+		PreviousStatus: previousStatus,
+		Status:         order.Status,
+		Accrual:        order.Accrual,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	events := []*Event{changed}
+	if final, ok := finalOrderEvents[order.Status]; ok {
+		finished, err := NewEvent(final, login, order.Number, &OrderAcceptedPayload{
+			Order:  order.Number,
+			Status: order.Status,
+		})
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, finished)
+	}
+	if order.Status == OrderStatusProcessed && order.Accrual != nil {
+		credited, err := NewEvent(EventPointsCredited, login, order.Number, &PointsCreditedPayload{
+			Order:  order.Number,
+			Amount: *order.Accrual,
+		})
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, credited)
+	}
+	return events, nil
+}
+
+func OrderParkedEvent(order *ParkedOrder) (*Event, error) {
+	return NewEvent(EventOrderParked, order.Login, order.Number, &OrderParkedPayload{
+		Order:    order.Number,
+		Status:   order.Status,
+		Attempts: order.Attempts,
+	})
+}
+
+func WithdrawalEvent(eventType EventType, withdrawal *Withdrawal) (*Event, error) {
+	return NewEvent(eventType, withdrawal.Login, withdrawal.OrderNumber, &WithdrawalPayload{
+		Order: withdrawal.OrderNumber,
+		Sum:   withdrawal.Sum,
+	})
+}
@@ -0,0 +1,147 @@
+package models
+
+import (
+	"sort"
+	"time"
+)
+
+// ExpiringPoints is the amount of points that expire at the end of Date.
+type ExpiringPoints struct {
+	Date   string `json:"date"`
+	Amount Money  `json:"amount"`
+}
+
+// PointLot is the unspent part of a credit; lots without ExpiresAt never expire.
+type PointLot struct {
+	TransactionID int64
+	Amount        Money
+	ExpiresAt     *time.Time
+}
+
+// IsExpiringKind tells whether credits of the kind are subject to points expiration.
+func IsExpiringKind(kind string) bool {
+	return kind == PostingKindAccrual || kind == PostingKindBonus
+}
+
+func (l *PointLot) expiredAt(t time.Time) bool {
+	return l.ExpiresAt != nil && !l.ExpiresAt.After(t)
+}
+
+// PointLots replays the user account entries and returns the lots left after spending them first in, first out.
+// Only accruals and bonuses expire after ttl. Points returned by a refund or a released hold restore the lots their debit
+// consumed, and an expiry posting consumes the lots that had expired by the time it was made.
+func PointLots(entries []*LedgerEntry, ttl time.Duration) []*PointLot {
+	sorted := append([]*LedgerEntry(nil), entries...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].TransactionID < sorted[j].TransactionID
+	})
+
+	lots := make([]*PointLot, 0)
+	consumed := make(map[string][]*PointLot)
+	for _, entry := range sorted {
+		switch {
+		case entry.Amount > 0 && (entry.Kind == PostingKindReversal || entry.Kind == PostingKindRelease):
+			var restored []*PointLot
+			restored, consumed[entry.Reference] = takeLots(consumed[entry.Reference], entry.Amount, nil)
+			lots = insertLots(lots, restored)
+			if rest := entry.Amount - sumLots(restored); rest > 0 {
+				lots = insertLots(lots, []*PointLot{{TransactionID: entry.TransactionID, Amount: rest}})
+			}
+		case entry.Amount > 0:
+			lot := &PointLot{TransactionID: entry.TransactionID, Amount: entry.Amount}
+			if IsExpiringKind(entry.Kind) && ttl > 0 {
+				expiresAt := entry.CreatedAt.Add(ttl)
+				lot.ExpiresAt = &expiresAt
+			}
+			lots = insertLots(lots, []*PointLot{lot})
+		case entry.Amount < 0 && entry.Kind == PostingKindExpiry:
+			at := entry.CreatedAt.Time
+			_, lots = takeLots(lots, -entry.Amount, func(lot *PointLot) bool { return lot.expiredAt(at) })
+		case entry.Amount < 0:
+			var taken []*PointLot
+			taken, lots = takeLots(lots, -entry.Amount, nil)
+			if entry.Kind == PostingKindWithdrawal || entry.Kind == PostingKindHold {
+				consumed[entry.Reference] = insertLots(consumed[entry.Reference], taken)
+			}
+		}
+	}
+	return lots
+}
+
+// ExpiredPoints is the amount of the lots that have expired by now.
+func ExpiredPoints(lots []*PointLot, now time.Time) Money {
+	var expired Money
+	for _, lot := range lots {
+		if lot.expiredAt(now) {
+			expired += lot.Amount
+		}
+	}
+	return expired
+}
+
+// ExpiringBreakdown groups the lots that still have to expire by date, soonest first.
+func ExpiringBreakdown(lots []*PointLot, now time.Time) []*ExpiringPoints {
+	byDate := make(map[string]*ExpiringPoints)
+	breakdown := make([]*ExpiringPoints, 0)
+	for _, lot := range lots {
+		if lot.ExpiresAt == nil || lot.expiredAt(now) {
+			continue
+		}
+		date := lot.ExpiresAt.Format(time.DateOnly)
+		if _, ok := byDate[date]; !ok {
+			byDate[date] = &ExpiringPoints{Date: date}
+			breakdown = append(breakdown, byDate[date])
+		}
+		byDate[date].Amount += lot.Amount
+	}
+
+	sort.Slice(breakdown, func(i, j int) bool {
+		return breakdown[i].Date < breakdown[j].Date
+	})
+	return breakdown
+}
+
+// takeLots removes amount from the matching lots in order and returns what was taken and what is left.
+func takeLots(lots []*PointLot, amount Money, match func(lot *PointLot) bool) ([]*PointLot, []*PointLot) {
+	taken := make([]*PointLot, 0)
+	left := make([]*PointLot, 0, len(lots))
+	for _, lot := range lots {
+		if amount == 0 || (match != nil && !match(lot)) {
+			left = append(left, lot)
+			continue
+		}
+
+		part := min(lot.Amount, amount)
+		amount -= part
+		taken = append(taken, &PointLot{TransactionID: lot.TransactionID, Amount: part, ExpiresAt: lot.ExpiresAt})
+		if part < lot.Amount {
+			left = append(left, &PointLot{TransactionID: lot.TransactionID, Amount: lot.Amount - part, ExpiresAt: lot.ExpiresAt})
+		}
+	}
+	return taken, left
+}
+
+// insertLots keeps lots ordered by the credit that created them, merging parts of the same credit.
+func insertLots(lots []*PointLot, added []*PointLot) []*PointLot {
+	for _, lot := range added {
+		i := sort.Search(len(lots), func(i int) bool {
+			return lots[i].TransactionID >= lot.TransactionID
+		})
+		if i < len(lots) && lots[i].TransactionID == lot.TransactionID {
+			lots[i] = &PointLot{TransactionID: lot.TransactionID, Amount: lots[i].Amount + lot.Amount, ExpiresAt: lot.ExpiresAt}
+			continue
+		}
+		lots = append(lots, nil)
+		copy(lots[i+1:], lots[i:])
+		lots[i] = lot
+	}
+	return lots
+}
+
+func sumLots(lots []*PointLot) Money {
+	var total Money
+	for _, lot := range lots {
+		total += lot.Amount
+	}
+	return total
+}
@@ -0,0 +1,122 @@
+package models
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestPointLots(t *testing.T) {
+	const ttl = 10 * 24 * time.Hour
+	day := func(n int) CustomTime {
+		return CustomTime{Time: time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC).AddDate(0, 0, n)}
+	}
+	entry := func(id int64, kind, reference string, amount Money, createdAt CustomTime) *LedgerEntry {
+		return &LedgerEntry{TransactionID: id, Kind: kind, Reference: reference, Amount: amount, CreatedAt: createdAt}
+	}
+
+	tests := []struct {
+		name     string
+		entries  []*LedgerEntry
+		now      CustomTime
+		expired  Money
+		expiring []*ExpiringPoints
+	}{
+		{
+			name: "withdrawal spends the oldest accrual first",
+			entries: []*LedgerEntry{
+				entry(1, PostingKindAccrual, "1", 1000, day(0)),
+				entry(2, PostingKindAccrual, "2", 500, day(3)),
+				entry(3, PostingKindWithdrawal, "9", -1200, day(4)),
+			},
+			now:      day(5),
+			expiring: []*ExpiringPoints{{Date: "2026-01-14", Amount: 300}},
+		},
+		{
+			name: "unspent part of an old accrual expires",
+			entries: []*LedgerEntry{
+				entry(1, PostingKindAccrual, "1", 1000, day(0)),
+				entry(2, PostingKindWithdrawal, "9", -400, day(1)),
+				entry(3, PostingKindAccrual, "2", 500, day(3)),
+			},
+			now:      day(11),
+			expired:  600,
+			expiring: []*ExpiringPoints{{Date: "2026-01-14", Amount: 500}},
+		},
+		{
+			name: "adjustments never expire but are spent in order",
+			entries: []*LedgerEntry{
+				entry(1, PostingKindAdjustment, "adjustment:1", 300, day(0)),
+				entry(2, PostingKindAccrual, "1", 1000, day(1)),
+				entry(3, PostingKindWithdrawal, "9", -500, day(2)),
+			},
+			now:     day(20),
+			expired: 800,
+		},
+		{
+			name: "refund restores the lots the withdrawal consumed",
+			entries: []*LedgerEntry{
+				entry(1, PostingKindAccrual, "1", 1000, day(0)),
+				entry(2, PostingKindWithdrawal, "9", -1000, day(1)),
+				entry(3, PostingKindReversal, "9", 1000, day(2)),
+			},
+			now:     day(10),
+			expired: 1000,
+		},
+		{
+			name: "released hold restores the lots and keeps FIFO order",
+			entries: []*LedgerEntry{
+				entry(1, PostingKindAccrual, "1", 1000, day(0)),
+				entry(2, PostingKindAccrual, "2", 1000, day(5)),
+				entry(3, PostingKindHold, "9", -1500, day(6)),
+				entry(4, PostingKindRelease, "9", 1500, day(7)),
+				entry(5, PostingKindWithdrawal, "8", -500, day(8)),
+			},
+			now:      day(10),
+			expired:  500,
+			expiring: []*ExpiringPoints{{Date: "2026-01-16", Amount: 1000}},
+		},
+		{
+			name: "expiry posting consumes only expired lots",
+			entries: []*LedgerEntry{
+				entry(1, PostingKindAdjustment, "adjustment:1", 200, day(0)),
+				entry(2, PostingKindAccrual, "1", 1000, day(0)),
+				entry(3, PostingKindAccrual, "2", 700, day(2)),
+				entry(4, PostingKindExpiry, "2026-01-11", -1000, day(10)),
+			},
+			now:      day(11),
+			expiring: []*ExpiringPoints{{Date: "2026-01-13", Amount: 700}},
+		},
+		{
+			name: "expiring amounts are grouped by date",
+			entries: []*LedgerEntry{
+				entry(1, PostingKindAccrual, "1", 100, day(0)),
+				entry(2, PostingKindAccrual, "2", 200, CustomTime{Time: day(0).Add(time.Hour)}),
+				entry(3, PostingKindAccrual, "3", 300, day(1)),
+			},
+			now:      day(2),
+			expiring: []*ExpiringPoints{{Date: "2026-01-11", Amount: 300}, {Date: "2026-01-12", Amount: 300}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lots := PointLots(tt.entries, ttl)
+			assert.Equal(t, tt.expired, ExpiredPoints(lots, tt.now.Time))
+
+			expiring := ExpiringBreakdown(lots, tt.now.Time)
+			if tt.expiring == nil {
+				assert.Empty(t, expiring)
+			} else {
+				assert.Equal(t, tt.expiring, expiring)
+			}
+		})
+	}
+}
+
+func TestPointLots_WithoutTTL(t *testing.T) {
+	lots := PointLots([]*LedgerEntry{
+		{TransactionID: 1, Kind: PostingKindAccrual, Amount: 1000, CreatedAt: CustomTime{Time: time.Now().AddDate(-5, 0, 0)}},
+	}, 0)
+	assert.Equal(t, Money(0), ExpiredPoints(lots, time.Now()))
+	assert.Empty(t, ExpiringBreakdown(lots, time.Now()))
+}
@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+type HoldStatus string
+
+const (
+	HoldStatusHeld     HoldStatus = "HELD"
+	HoldStatusCaptured HoldStatus = "CAPTURED"
+	HoldStatusReleased HoldStatus = "RELEASED"
+	HoldStatusExpired  HoldStatus = "EXPIRED"
+)
+
+type HoldRequest struct {
+	OrderNumber string `json:"order"`
+	Sum         Money  `json:"sum"`
+}
+
+// Hold reserves points for an order until it is captured as a withdrawal, released or expires.
+type Hold struct {
+	ID          string     `json:"id"`
+	Login       string     `json:"-"`
+	OrderNumber string     `json:"order"`
+	Sum         Money      `json:"sum"`
+	Status      HoldStatus `json:"status"`
+	CreatedAt   CustomTime `json:"created_at"`
+	ExpiresAt   CustomTime `json:"expires_at"`
+}
+
+func (h *Hold) IsActive(now time.Time) bool {
+	return h.Status == HoldStatusHeld && now.Before(h.ExpiresAt.Time)
+}
+
+func (h *Hold) HoldTransaction() *LedgerTransaction {
+	return NewTransfer(PostingKindHold, h.OrderNumber, UserAccount(h.Login), HoldAccount(h.Login), h.Sum)
+}
+
+func (h *Hold) CaptureTransaction() *LedgerTransaction {
+	return NewTransfer(PostingKindWithdrawal, h.OrderNumber, HoldAccount(h.Login), AccountWithdrawal, h.Sum)
+}
+
+func (h *Hold) ReleaseTransaction() *LedgerTransaction {
+	return NewTransfer(PostingKindRelease, h.OrderNumber, HoldAccount(h.Login), UserAccount(h.Login), h.Sum)
+}
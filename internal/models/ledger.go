@@ -0,0 +1,86 @@
+package models
+
+import "slices"
+
+const (
+	PostingKindAccrual    = "ACCRUAL"
+	PostingKindWithdrawal = "WITHDRAWAL"
+	PostingKindAdjustment = "ADJUSTMENT"
+	PostingKindReversal   = "REVERSAL"
+	PostingKindHold       = "HOLD"
+	PostingKindRelease    = "RELEASE"
+	PostingKindExpiry     = "EXPIRY"
+	PostingKindBonus      = "BONUS"
+	PostingKindReferral   = "REFERRAL"
+)
+
+const (
+	AccountAccrual    = "system:accrual"
+	AccountWithdrawal = "system:withdrawal"
+	AccountAdjustment = "system:adjustment"
+	AccountExpiry     = "system:expiry"
+	AccountBonus      = "system:bonus"
+	AccountReferral   = "system:referral"
+
+	userAccountPrefix = "user:"
+	holdAccountPrefix = "hold:"
+)
+
+func UserAccount(login string) string {
+	return userAccountPrefix + login
+}
+
+// HoldAccount keeps the points reserved by the user's active holds.
+func HoldAccount(login string) string {
+	return holdAccountPrefix + login
+}
+
+type Posting struct {
+	Account string
+	Amount  Money
+}
+
+type LedgerTransaction struct {
+	Kind      string
+	Reference string
+	Comment   string
+	Postings  []Posting
+}
+
+// NewTransfer builds a balanced transaction moving amount from one account to another.
+func NewTransfer(kind, reference, from, to string, amount Money) *LedgerTransaction {
+	return &LedgerTransaction{
+		Kind:      kind,
+		Reference: reference,
+		Postings: []Posting{
+			{Account: from, Amount: -amount},
+			{Account: to, Amount: amount},
+		},
+	}
+}
+
+func (l *LedgerTransaction) IsBalanced() bool {
+	var total Money
+	for _, posting := range l.Postings {
+		total += posting.Amount
+	}
+	return total == 0
+}
+
+func (l *LedgerTransaction) Touches(accounts ...string) bool {
+	for _, posting := range l.Postings {
+		if slices.Contains(accounts, posting.Account) {
+			return true
+		}
+	}
+	return false
+}
+
+type LedgerEntry struct {
+	TransactionID int64      `json:"id"`
+	Kind          string     `json:"kind"`
+	Reference     string     `json:"reference"`
+	Amount        Money      `json:"amount"`
+	Comment       string     `json:"comment,omitempty"`
+	CreatedAt     CustomTime `json:"created_at"`
+}
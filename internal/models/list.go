@@ -0,0 +1,57 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+)
+
+type ListCursor struct {
+	Time time.Time `json:"t"`
+	Key  string    `json:"k"`
+}
+
+func (c *ListCursor) Encode() (string, error) {
+	buf, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func DecodeListCursor(s string) (*ListCursor, error) {
+	buf, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+
+	var cursor ListCursor
+	if err = json.Unmarshal(buf, &cursor); err != nil {
+		return nil, err
+	}
+	return &cursor, nil
+}
+
+// ListFilter selects one page of a list ordered from newest to oldest; From is inclusive and To is exclusive.
+type ListFilter struct {
+	Limit  uint
+	Cursor *ListCursor
+	Status string
+	From   *time.Time
+	To     *time.Time
+}
+
+func (f *ListFilter) Contains(t time.Time, key string) bool {
+	if f.From != nil && t.Before(*f.From) {
+		return false
+	}
+	if f.To != nil && !t.Before(*f.To) {
+		return false
+	}
+	if f.Cursor != nil {
+		if t.After(f.Cursor.Time) || (t.Equal(f.Cursor.Time) && key >= f.Cursor.Key) {
+			return false
+		}
+	}
+	return true
+}
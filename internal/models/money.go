@@ -0,0 +1,122 @@
+package models
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"github.com/Aleksei-D/go-loyalty-system/internal/utils/common"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const moneyScale = 100
+
+// Money is an exact amount of loyalty points stored in hundredths.
+type Money int64
+
+var (
+	moneyScaleRat = big.NewRat(moneyScale, 1)
+	// big.Rat also understands fractions and hex/octal literals, so the input is checked first.
+	decimalPattern    = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?$`)
+	jsonNumberPattern = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?([eE][+-]?[0-9]{1,3})?$`)
+)
+
+// ParseMoney accepts a plain decimal with at most two fractional digits.
+func ParseMoney(s string) (Money, error) {
+	hundredths, ok := parseHundredths(s, decimalPattern)
+	if !ok || !hundredths.IsInt() {
+		return 0, fmt.Errorf("%w: %q", common.ErrInvalidSum, s)
+	}
+	return moneyFromInt(hundredths.Num())
+}
+
+// RoundMoney accepts any JSON number and rounds it half away from zero to two fractional digits.
+func RoundMoney(s string) (Money, error) {
+	hundredths, ok := parseHundredths(s, jsonNumberPattern)
+	if !ok {
+		return 0, fmt.Errorf("%w: %q", common.ErrInvalidSum, s)
+	}
+
+	quo, rem := new(big.Int).QuoRem(hundredths.Num(), hundredths.Denom(), new(big.Int))
+	if new(big.Int).Mul(new(big.Int).Abs(rem), big.NewInt(2)).Cmp(hundredths.Denom()) >= 0 {
+		quo.Add(quo, big.NewInt(int64(hundredths.Sign())))
+	}
+	return moneyFromInt(quo)
+}
+
+func parseHundredths(s string, pattern *regexp.Regexp) (*big.Rat, bool) {
+	s = strings.TrimSpace(s)
+	if !pattern.MatchString(s) {
+		return nil, false
+	}
+
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, false
+	}
+	return r.Mul(r, moneyScaleRat), true
+}
+
+func moneyFromInt(i *big.Int) (Money, error) {
+	if !i.IsInt64() {
+		return 0, fmt.Errorf("%w: %s hundredths overflows", common.ErrInvalidSum, i)
+	}
+	return Money(i.Int64()), nil
+}
+
+func (m Money) IsPositive() bool {
+	return m > 0
+}
+
+func (m Money) String() string {
+	sign := ""
+	abs := int64(m)
+	if abs < 0 {
+		sign = "-"
+		abs = -abs
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, abs/moneyScale, abs%moneyScale)
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	s := m.String()
+	s = strings.TrimRight(s, "0")
+	s = strings.TrimSuffix(s, ".")
+	return []byte(s), nil
+}
+
+func (m *Money) UnmarshalJSON(b []byte) error {
+	money, err := ParseMoney(string(b))
+	if err != nil {
+		return err
+	}
+	*m = money
+	return nil
+}
+
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
+
+func (m *Money) Scan(src interface{}) error {
+	var money Money
+	var err error
+	switch v := src.(type) {
+	case []byte:
+		money, err = ParseMoney(string(v))
+	case string:
+		money, err = ParseMoney(v)
+	case int64:
+		money, err = ParseMoney(strconv.FormatInt(v, 10))
+	case float64:
+		money, err = RoundMoney(strconv.FormatFloat(v, 'f', -1, 64))
+	default:
+		return fmt.Errorf("cannot scan %T into Money", src)
+	}
+	if err != nil {
+		return err
+	}
+	*m = money
+	return nil
+}
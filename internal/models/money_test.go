@@ -0,0 +1,132 @@
+package models
+
+import (
+	"encoding/json"
+	"github.com/Aleksei-D/go-loyalty-system/internal/utils/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Money
+		wantErr bool
+	}{
+		{input: "0", want: 0},
+		{input: "751", want: 75100},
+		{input: "500.5", want: 50050},
+		{input: "0.01", want: 1},
+		{input: "42.10", want: 4210},
+		{input: " 3.5 ", want: 350},
+		{input: "-12.34", want: -1234},
+		{input: "1.005", wantErr: true},
+		{input: "1e2", wantErr: true},
+		{input: "1/3", wantErr: true},
+		{input: "0x10", wantErr: true},
+		{input: "+1", wantErr: true},
+		{input: ".5", wantErr: true},
+		{input: "5.", wantErr: true},
+		{input: "", wantErr: true},
+		{input: "abc", wantErr: true},
+		{input: "92233720368547758.08", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseMoney(tt.input)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, common.ErrInvalidSum)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRoundMoney(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Money
+		wantErr bool
+	}{
+		{input: "729.98", want: 72998},
+		{input: "1.004", want: 100},
+		{input: "1.005", want: 101},
+		{input: "-1.005", want: -101},
+		{input: "-1.004", want: -100},
+		{input: "0.125", want: 13},
+		{input: "1e2", want: 10000},
+		{input: "1.5E-3", want: 0},
+		{input: "5e-3", want: 1},
+		{input: "1/3", wantErr: true},
+		{input: "NaN", wantErr: true},
+		{input: "1e1000", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := RoundMoney(tt.input)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, common.ErrInvalidSum)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMoney_MarshalJSON(t *testing.T) {
+	tests := []struct {
+		money Money
+		want  string
+	}{
+		{money: 0, want: "0"},
+		{money: 50000, want: "500"},
+		{money: 50050, want: "500.5"},
+		{money: 4201, want: "42.01"},
+		{money: -1234, want: "-12.34"},
+		{money: -5, want: "-0.05"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			got, err := json.Marshal(tt.money)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+
+			var parsed Money
+			require.NoError(t, json.Unmarshal(got, &parsed))
+			assert.Equal(t, tt.money, parsed)
+		})
+	}
+}
+
+func TestMoney_Scan(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     interface{}
+		want    Money
+		wantErr bool
+	}{
+		{name: "numeric bytes", src: []byte("500.50"), want: 50050},
+		{name: "string", src: "-0.01", want: -1},
+		{name: "int64", src: int64(42), want: 4200},
+		{name: "float64 rounded", src: 0.125, want: 13},
+		{name: "negative float64 rounded", src: -2.675000001, want: -268},
+		{name: "too many digits", src: "1.001", wantErr: true},
+		{name: "unsupported type", src: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Money
+			err := got.Scan(tt.src)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -1,30 +1,148 @@
 package models
 
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/Aleksei-D/go-loyalty-system/internal/utils/common"
+	"time"
+)
+
+type OrderStatus string
+
 const (
-	OrderStatusNew        = "NEW"
-	OrderStatusProcessing = "PROCESSING"
-	OrderStatusProcessed  = "PROCESSED"
-	OrderStatusInvalid    = "INVALID"
+	OrderStatusNew        OrderStatus = "NEW"
+	OrderStatusProcessing OrderStatus = "PROCESSING"
+	OrderStatusProcessed  OrderStatus = "PROCESSED"
+	OrderStatusInvalid    OrderStatus = "INVALID"
+
+	AccrualStatusRegistered = "REGISTERED"
 )
 
+var orderStatusTransitions = map[OrderStatus][]OrderStatus{
+	OrderStatusNew:        {OrderStatusProcessing, OrderStatusProcessed, OrderStatusInvalid},
+	OrderStatusProcessing: {OrderStatusProcessed, OrderStatusInvalid},
+}
+
+func (s OrderStatus) IsValid() bool {
+	switch s {
+	case OrderStatusNew, OrderStatusProcessing, OrderStatusProcessed, OrderStatusInvalid:
+		return true
+	}
+	return false
+}
+
+func (s OrderStatus) IsFinal() bool {
+	return s == OrderStatusProcessed || s == OrderStatusInvalid
+}
+
+func (s OrderStatus) CanTransitionTo(next OrderStatus) bool {
+	for _, allowed := range orderStatusTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckTransition reports whether moving to next changes the order; repeating the current status
+// or reporting NEW for an order still in progress is a no-op.
+func (s OrderStatus) CheckTransition(next OrderStatus) (bool, error) {
+	if s == next || (next == OrderStatusNew && s.IsValid() && !s.IsFinal()) {
+		return false, nil
+	}
+	if !s.CanTransitionTo(next) {
+		return false, fmt.Errorf("%w: %s -> %s", common.ErrIllegalStatusTransition, s, next)
+	}
+	return true, nil
+}
+
 type Order struct {
-	Login      string     `json:"-"`
-	Number     string     `json:"number"`
-	Status     string     `json:"status"`
-	Accrual    *float64   `json:"accrual,omitempty"`
-	UploadedAt CustomTime `json:"uploaded_at"`
+	Login      string      `json:"-"`
+	Number     string      `json:"number"`
+	Status     OrderStatus `json:"status"`
+	Accrual    *Money      `json:"accrual,omitempty"`
+	UploadedAt CustomTime  `json:"uploaded_at"`
+	CheckedAt  *CustomTime `json:"checked_at,omitempty"`
+}
+
+type OrderStatusChange struct {
+	PreviousStatus *OrderStatus `json:"previous_status,omitempty"`
+	Status         OrderStatus  `json:"status"`
+	ChangedAt      CustomTime   `json:"changed_at"`
+}
+
+// OrderClaim asks for up to Limit orders that were not checked for UpdateTimeout. A claimed order is leased
+// to Worker until Lease passes; an order claimed MaxAttempts times without a status report is parked instead.
+type OrderClaim struct {
+	Worker        string
+	Limit         uint
+	UpdateTimeout time.Duration
+	Lease         time.Duration
+	MaxAttempts   uint
+}
+
+// Exhausted reports whether an order claimed attempts times must be parked; zero MaxAttempts never parks.
+func (c *OrderClaim) Exhausted(attempts uint) bool {
+	return c.MaxAttempts > 0 && attempts >= c.MaxAttempts
+}
+
+// ParkedOrder needs attention: the accrual system never reported its status within the claim attempts.
+type ParkedOrder struct {
+	Login    string      `json:"login"`
+	Number   string      `json:"number"`
+	Status   OrderStatus `json:"status"`
+	Attempts uint        `json:"attempts"`
+	ParkedAt CustomTime  `json:"parked_at"`
 }
 
 type OrderStatusResponse struct {
-	Order   string   `json:"order"`
-	Status  string   `json:"status"`
-	Accrual *float64 `json:"accrual,omitempty"`
+	Order   string `json:"order"`
+	Status  string `json:"status"`
+	Accrual *Money `json:"accrual,omitempty"`
 }
 
-func (o *OrderStatusResponse) ToOrder() *Order {
+// UnmarshalJSON rounds the accrual reported by the accrual system to two fractional digits.
+func (o *OrderStatusResponse) UnmarshalJSON(b []byte) error {
+	var response struct {
+		Order   string       `json:"order"`
+		Status  string       `json:"status"`
+		Accrual *json.Number `json:"accrual,omitempty"`
+	}
+	if err := json.Unmarshal(b, &response); err != nil {
+		return err
+	}
+
+	o.Order = response.Order
+	o.Status = response.Status
+	o.Accrual = nil
+	if response.Accrual != nil {
+		accrual, err := RoundMoney(response.Accrual.String())
+		if err != nil {
+			return err
+		}
+		o.Accrual = &accrual
+	}
+	return nil
+}
+
+func (o *OrderStatusResponse) ToOrder() (*Order, error) {
+	var status OrderStatus
+	switch o.Status {
+	case AccrualStatusRegistered:
+		status = OrderStatusNew
+	case string(OrderStatusProcessing):
+		status = OrderStatusProcessing
+	case string(OrderStatusProcessed):
+		status = OrderStatusProcessed
+	case string(OrderStatusInvalid):
+		status = OrderStatusInvalid
+	default:
+		return nil, fmt.Errorf("%w: %q", common.ErrUnknownAccrualStatus, o.Status)
+	}
+
 	return &Order{
 		Number:  o.Order,
-		Status:  o.Status,
+		Status:  status,
 		Accrual: o.Accrual,
-	}
+	}, nil
 }
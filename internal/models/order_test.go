@@ -0,0 +1,73 @@
+package models
+
+import (
+	"github.com/Aleksei-D/go-loyalty-system/internal/utils/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestOrderStatus_CheckTransition(t *testing.T) {
+	tests := []struct {
+		from        OrderStatus
+		to          OrderStatus
+		wantChanged bool
+		wantErr     bool
+	}{
+		{from: OrderStatusNew, to: OrderStatusNew},
+		{from: OrderStatusNew, to: OrderStatusProcessing, wantChanged: true},
+		{from: OrderStatusNew, to: OrderStatusProcessed, wantChanged: true},
+		{from: OrderStatusNew, to: OrderStatusInvalid, wantChanged: true},
+		{from: OrderStatusProcessing, to: OrderStatusNew},
+		{from: OrderStatusProcessing, to: OrderStatusProcessing},
+		{from: OrderStatusProcessing, to: OrderStatusProcessed, wantChanged: true},
+		{from: OrderStatusProcessing, to: OrderStatusInvalid, wantChanged: true},
+		{from: OrderStatusProcessed, to: OrderStatusProcessed},
+		{from: OrderStatusProcessed, to: OrderStatusNew, wantErr: true},
+		{from: OrderStatusProcessed, to: OrderStatusProcessing, wantErr: true},
+		{from: OrderStatusProcessed, to: OrderStatusInvalid, wantErr: true},
+		{from: OrderStatusInvalid, to: OrderStatusInvalid},
+		{from: OrderStatusInvalid, to: OrderStatusNew, wantErr: true},
+		{from: OrderStatusInvalid, to: OrderStatusProcessing, wantErr: true},
+		{from: OrderStatusInvalid, to: OrderStatusProcessed, wantErr: true},
+		{from: OrderStatusNew, to: OrderStatus("UNKNOWN"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.from)+"->"+string(tt.to), func(t *testing.T) {
+			changed, err := tt.from.CheckTransition(tt.to)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, common.ErrIllegalStatusTransition)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantChanged, changed)
+		})
+	}
+}
+
+func TestOrderStatusResponse_ToOrder(t *testing.T) {
+	tests := []struct {
+		status  string
+		want    OrderStatus
+		wantErr bool
+	}{
+		{status: AccrualStatusRegistered, want: OrderStatusNew},
+		{status: "PROCESSING", want: OrderStatusProcessing},
+		{status: "PROCESSED", want: OrderStatusProcessed},
+		{status: "INVALID", want: OrderStatusInvalid},
+		{status: "NEW", wantErr: true},
+		{status: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			order, err := (&OrderStatusResponse{Order: "12345678903", Status: tt.status}).ToOrder()
+			if tt.wantErr {
+				assert.ErrorIs(t, err, common.ErrUnknownAccrualStatus)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "12345678903", order.Number)
+			assert.Equal(t, tt.want, order.Status)
+		})
+	}
+}
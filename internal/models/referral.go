@@ -0,0 +1,58 @@
+package models
+
+import "time"
+
+// Referral links a referee to the user whose code they registered with; it is rewarded at most once.
+type Referral struct {
+	Referrer     string      `json:"-"`
+	Referee      string      `json:"login"`
+	CreatedAt    CustomTime  `json:"registered_at"`
+	RewardedAt   *CustomTime `json:"rewarded_at,omitempty"`
+	OrderNumber  string      `json:"order,omitempty"`
+	ReferrerPaid Money       `json:"reward,omitempty"`
+}
+
+type ReferralStats struct {
+	Code      string      `json:"code"`
+	Invited   int         `json:"invited"`
+	Rewarded  int         `json:"rewarded"`
+	Earned    Money       `json:"earned"`
+	Referrals []*Referral `json:"referrals"`
+}
+
+func NewReferralStats(code string, referrals []*Referral) *ReferralStats {
+	stats := &ReferralStats{Code: code, Invited: len(referrals), Referrals: referrals}
+	for _, referral := range referrals {
+		if referral.RewardedAt != nil {
+			stats.Rewarded++
+			stats.Earned += referral.ReferrerPaid
+		}
+	}
+	return stats
+}
+
+// ReferralReward pays both parties of a referral for the referee's first processed order.
+type ReferralReward struct {
+	Referrer       string
+	Referee        string
+	OrderNumber    string
+	ReferrerAmount Money
+	RefereeAmount  Money
+	RewardedAt     time.Time
+}
+
+func (r *ReferralReward) Transaction() *LedgerTransaction {
+	postings := []Posting{{Account: AccountReferral, Amount: -(r.ReferrerAmount + r.RefereeAmount)}}
+	if r.ReferrerAmount > 0 {
+		postings = append(postings, Posting{Account: UserAccount(r.Referrer), Amount: r.ReferrerAmount})
+	}
+	if r.RefereeAmount > 0 {
+		postings = append(postings, Posting{Account: UserAccount(r.Referee), Amount: r.RefereeAmount})
+	}
+	return &LedgerTransaction{
+		Kind:      PostingKindReferral,
+		Reference: r.OrderNumber,
+		Comment:   "referral:" + r.Referee,
+		Postings:  postings,
+	}
+}
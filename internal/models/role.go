@@ -0,0 +1,28 @@
+package models
+
+type Role string
+
+const (
+	RoleUser  Role = "user"
+	RoleAdmin Role = "admin"
+)
+
+func (r Role) IsValid() bool {
+	return r == RoleUser || r == RoleAdmin
+}
+
+func RolesToStrings(roles []Role) []string {
+	values := make([]string, 0, len(roles))
+	for _, role := range roles {
+		values = append(values, string(role))
+	}
+	return values
+}
+
+func RolesFromStrings(values []string) []Role {
+	roles := make([]Role, 0, len(values))
+	for _, value := range values {
+		roles = append(roles, Role(value))
+	}
+	return roles
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Tier is reached once the rolling accrued total is at least Threshold.
+type Tier struct {
+	Name      string
+	Threshold Money
+}
+
+// Tiers are ordered by threshold, the first one is the entry tier.
+type Tiers []Tier
+
+// ParseTiers reads a comma separated list of name:threshold pairs, e.g. "bronze:0,silver:1000,gold:5000".
+func ParseTiers(spec string) (Tiers, error) {
+	tiers := make(Tiers, 0)
+	names := make(map[string]bool)
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		name, threshold, ok := strings.Cut(item, ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("tier %q: expected name:threshold", item)
+		}
+		if names[name] {
+			return nil, fmt.Errorf("tier %q: duplicate name", name)
+		}
+
+		amount, err := ParseMoney(strings.TrimSpace(threshold))
+		if err != nil || amount < 0 {
+			return nil, fmt.Errorf("tier %q: invalid threshold %q", name, threshold)
+		}
+		names[name] = true
+		tiers = append(tiers, Tier{Name: name, Threshold: amount})
+	}
+
+	sort.SliceStable(tiers, func(i, j int) bool {
+		return tiers[i].Threshold < tiers[j].Threshold
+	})
+	for i := 1; i < len(tiers); i++ {
+		if tiers[i].Threshold == tiers[i-1].Threshold {
+			return nil, fmt.Errorf("tiers %q and %q share threshold %s", tiers[i-1].Name, tiers[i].Name, tiers[i].Threshold)
+		}
+	}
+	return tiers, nil
+}
+
+// For returns the highest tier reached by total and the tier after it, if any.
+func (t Tiers) For(total Money) (*Tier, *Tier) {
+	var current, next *Tier
+	for i := range t {
+		if t[i].Threshold <= total {
+			current = &t[i]
+			continue
+		}
+		next = &t[i]
+		break
+	}
+	return current, next
+}
+
+type Profile struct {
+	Login        string    `json:"login"`
+	Roles        []Role    `json:"roles"`
+	Tier         string    `json:"tier,omitempty"`
+	AccruedTotal Money     `json:"accrued_total"`
+	NextTier     *NextTier `json:"next_tier,omitempty"`
+}
+
+type NextTier struct {
+	Name      string `json:"name"`
+	Remaining Money  `json:"remaining"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestParseTiers(t *testing.T) {
+	tiers, err := ParseTiers(" gold:5000, bronze:0 ,silver:1000.50,")
+	require.NoError(t, err)
+	assert.Equal(t, Tiers{{"bronze", 0}, {"silver", 100050}, {"gold", 500000}}, tiers)
+
+	for _, spec := range []string{"gold", ":10", "gold:x", "gold:-1", "gold:1,gold:2", "silver:10,gold:10"} {
+		_, err = ParseTiers(spec)
+		assert.Error(t, err, spec)
+	}
+
+	tiers, err = ParseTiers("")
+	require.NoError(t, err)
+	assert.Empty(t, tiers)
+}
+
+func TestTiers_For(t *testing.T) {
+	tiers := Tiers{{"silver", 1000}, {"gold", 5000}}
+
+	current, next := tiers.For(999)
+	assert.Nil(t, current)
+	assert.Equal(t, "silver", next.Name)
+
+	current, next = tiers.For(1000)
+	assert.Equal(t, "silver", current.Name)
+	assert.Equal(t, "gold", next.Name)
+
+	current, next = tiers.For(7000)
+	assert.Equal(t, "gold", current.Name)
+	assert.Nil(t, next)
+}
@@ -1,6 +1,40 @@
 package models
 
+import "time"
+
+// User.ReferralCode is the user's own code, InviteCode is the code of another user given at registration.
 type User struct {
-	Login    string `json:"login"`
-	Password string `json:"password"`
+	Login        string     `json:"login"`
+	Password     string     `json:"password"`
+	LockedUntil  *time.Time `json:"-"`
+	Roles        []Role     `json:"-"`
+	BlockedAt    *time.Time `json:"-"`
+	Tier         string     `json:"-"`
+	ReferralCode string     `json:"-"`
+	InviteCode   string     `json:"referral_code,omitempty"`
+	ReferredBy   string     `json:"-"`
+}
+
+func (u *User) HasRole(role Role) bool {
+	for _, userRole := range u.Roles {
+		if userRole == role {
+			return true
+		}
+	}
+	return false
+}
+
+type UserInfo struct {
+	Login     string      `json:"login"`
+	Roles     []Role      `json:"roles"`
+	BlockedAt *CustomTime `json:"blocked_at,omitempty"`
+	Tier      string      `json:"tier,omitempty"`
+}
+
+func (u *User) Info() *UserInfo {
+	info := &UserInfo{Login: u.Login, Roles: u.Roles, Tier: u.Tier}
+	if u.BlockedAt != nil {
+		info.BlockedAt = &CustomTime{Time: *u.BlockedAt}
+	}
+	return info
 }
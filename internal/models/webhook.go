@@ -0,0 +1,145 @@
+package models
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/Aleksei-D/go-loyalty-system/internal/utils/common"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const webhookSecretMinLength = 16
+
+var KnownEventTypes = []EventType{
+	EventOrderAccepted,
+	EventOrderStatusChanged,
+	EventOrderProcessed,
+	EventOrderInvalid,
+	EventOrderParked,
+	EventPointsCredited,
+	EventWithdrawalMade,
+	EventWithdrawalRefunded,
+}
+
+// WebhookSubscription receives the events of EventTypes, or every event when it is empty.
+type WebhookSubscription struct {
+	ID         string      `json:"id"`
+	URL        string      `json:"url"`
+	Secret     string      `json:"secret,omitempty"`
+	EventTypes []EventType `json:"event_types"`
+	CreatedAt  CustomTime  `json:"created_at"`
+}
+
+func (w *WebhookSubscription) Validate() error {
+	target, err := url.Parse(w.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return fmt.Errorf("%w: url must be an absolute http(s) URL", common.ErrInvalidWebhook)
+	}
+	if len(w.Secret) < webhookSecretMinLength {
+		return fmt.Errorf("%w: secret must be at least %d characters", common.ErrInvalidWebhook, webhookSecretMinLength)
+	}
+	for _, eventType := range w.EventTypes {
+		if !slices.Contains(KnownEventTypes, eventType) {
+			return fmt.Errorf("%w: unknown event type %q", common.ErrInvalidWebhook, eventType)
+		}
+	}
+	return nil
+}
+
+func (w *WebhookSubscription) Accepts(eventType EventType) bool {
+	return len(w.EventTypes) == 0 || slices.Contains(w.EventTypes, eventType)
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "PENDING"
+	WebhookDeliveryDelivered WebhookDeliveryStatus = "DELIVERED"
+	WebhookDeliveryDead      WebhookDeliveryStatus = "DEAD"
+)
+
+func (s WebhookDeliveryStatus) IsValid() bool {
+	switch s {
+	case WebhookDeliveryPending, WebhookDeliveryDelivered, WebhookDeliveryDead:
+		return true
+	}
+	return false
+}
+
+// WebhookDelivery is one event sent to one subscription; DEAD deliveries form the dead-letter list.
+type WebhookDelivery struct {
+	ID             int64                 `json:"id"`
+	SubscriptionID string                `json:"subscription_id"`
+	EventID        int64                 `json:"event_id"`
+	EventType      EventType             `json:"event_type"`
+	Payload        json.RawMessage       `json:"payload"`
+	Status         WebhookDeliveryStatus `json:"status"`
+	Attempts       uint                  `json:"attempts"`
+	LastStatusCode int                   `json:"last_status_code,omitempty"`
+	LastError      string                `json:"last_error,omitempty"`
+	CreatedAt      CustomTime            `json:"created_at"`
+	NextAttemptAt  *CustomTime           `json:"next_attempt_at,omitempty"`
+	DeliveredAt    *CustomTime           `json:"delivered_at,omitempty"`
+	URL            string                `json:"-"`
+	Secret         string                `json:"-"`
+}
+
+// NewWebhookDelivery wraps the event the way it is posted to the subscriber.
+func NewWebhookDelivery(subscriptionID string, event *Event) (*WebhookDelivery, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+	return &WebhookDelivery{
+		SubscriptionID: subscriptionID,
+		EventID:        event.ID,
+		EventType:      event.Type,
+		Payload:        payload,
+		Status:         WebhookDeliveryPending,
+	}, nil
+}
+
+// SignWebhook signs "<timestamp>.<body>" with HMAC-SHA256, the timestamp lets receivers reject replays.
+func SignWebhook(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignatureHeader formats the X-Webhook-Signature value "t=<timestamp>,v1=<signature>".
+func SignatureHeader(secret string, timestamp int64, body []byte) string {
+	return fmt.Sprintf("t=%d,v1=%s", timestamp, SignWebhook(secret, timestamp, body))
+}
+
+// VerifySignature checks a SignatureHeader value and rejects timestamps further than tolerance from now.
+func VerifySignature(secret, header string, body []byte, now time.Time, tolerance time.Duration) error {
+	var timestamp int64
+	var signature string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			timestamp, _ = strconv.ParseInt(value, 10, 64)
+		case "v1":
+			signature = value
+		}
+	}
+	if timestamp == 0 || signature == "" {
+		return fmt.Errorf("%w: malformed signature header", common.ErrInvalidSignature)
+	}
+	if age := now.Sub(time.Unix(timestamp, 0)); age > tolerance || age < -tolerance {
+		return fmt.Errorf("%w: timestamp outside of tolerance", common.ErrInvalidSignature)
+	}
+	if !hmac.Equal([]byte(signature), []byte(SignWebhook(secret, timestamp, body))) {
+		return common.ErrInvalidSignature
+	}
+	return nil
+}
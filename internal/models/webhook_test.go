@@ -0,0 +1,68 @@
+package models
+
+import (
+	"errors"
+	"github.com/Aleksei-D/go-loyalty-system/internal/utils/common"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestWebhookSubscription_Validate(t *testing.T) {
+	tests := []struct {
+		name         string
+		subscription WebhookSubscription
+		wantErr      bool
+	}{
+		{"valid", WebhookSubscription{URL: "https://example.com/hook", Secret: "0123456789abcdef"}, false},
+		{"with types", WebhookSubscription{URL: "http://example.com", Secret: "0123456789abcdef", EventTypes: []EventType{EventOrderProcessed}}, false},
+		{"relative url", WebhookSubscription{URL: "/hook", Secret: "0123456789abcdef"}, true},
+		{"ftp url", WebhookSubscription{URL: "ftp://example.com", Secret: "0123456789abcdef"}, true},
+		{"short secret", WebhookSubscription{URL: "https://example.com", Secret: "short"}, true},
+		{"unknown type", WebhookSubscription{URL: "https://example.com", Secret: "0123456789abcdef", EventTypes: []EventType{"order.lost"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.subscription.Validate()
+			assert.Equal(t, tt.wantErr, err != nil)
+			if tt.wantErr {
+				assert.True(t, errors.Is(err, common.ErrInvalidWebhook))
+			}
+		})
+	}
+}
+
+func TestWebhookSubscription_Accepts(t *testing.T) {
+	all := WebhookSubscription{}
+	assert.True(t, all.Accepts(EventWithdrawalMade))
+
+	processed := WebhookSubscription{EventTypes: []EventType{EventOrderProcessed}}
+	assert.True(t, processed.Accepts(EventOrderProcessed))
+	assert.False(t, processed.Accepts(EventOrderAccepted))
+}
+
+func TestSignWebhook(t *testing.T) {
+	// echo -n '1700000000.{"id":1}' | openssl dgst -sha256 -hmac 0123456789abcdef
+	assert.Equal(t,
+		"4bcaced68dfea90a68df035b89cb7fb26692d899d32a1ccb1b0616cf48e4d1ed",
+		SignWebhook("0123456789abcdef", 1700000000, []byte(`{"id":1}`)),
+	)
+	assert.NotEqual(t,
+		SignWebhook("0123456789abcdef", 1700000000, []byte(`{"id":1}`)),
+		SignWebhook("0123456789abcdef", 1700000001, []byte(`{"id":1}`)),
+	)
+}
+
+func TestVerifySignature(t *testing.T) {
+	secret := "0123456789abcdef"
+	body := []byte(`{"order":"12345678903","status":"PROCESSED"}`)
+	now := time.Unix(1700000000, 0)
+	header := SignatureHeader(secret, now.Unix(), body)
+
+	assert.NoError(t, VerifySignature(secret, header, body, now.Add(time.Minute), 5*time.Minute))
+	assert.ErrorIs(t, VerifySignature(secret, header, body, now.Add(10*time.Minute), 5*time.Minute), common.ErrInvalidSignature)
+	assert.ErrorIs(t, VerifySignature("another-secret-value", header, body, now, 5*time.Minute), common.ErrInvalidSignature)
+	assert.ErrorIs(t, VerifySignature(secret, header, []byte(`{}`), now, 5*time.Minute), common.ErrInvalidSignature)
+	assert.ErrorIs(t, VerifySignature(secret, "", body, now, 5*time.Minute), common.ErrInvalidSignature)
+	assert.ErrorIs(t, VerifySignature(secret, "v1=abc", body, now, 5*time.Minute), common.ErrInvalidSignature)
+}
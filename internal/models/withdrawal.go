@@ -1,8 +1,21 @@
 package models
 
+type WithdrawalStatus string
+
+const (
+	WithdrawalStatusCompleted WithdrawalStatus = "COMPLETED"
+	WithdrawalStatusRefunded  WithdrawalStatus = "REFUNDED"
+)
+
+func (s WithdrawalStatus) IsValid() bool {
+	return s == WithdrawalStatusCompleted || s == WithdrawalStatusRefunded
+}
+
 type Withdrawal struct {
-	Login       string     `json:"-"`
-	OrderNumber string     `json:"order"`
-	Sum         float64    `json:"sum"`
-	ProcessedAt CustomTime `json:"processed_at"`
+	Login       string           `json:"-"`
+	OrderNumber string           `json:"order"`
+	Sum         Money            `json:"sum"`
+	Status      WithdrawalStatus `json:"status,omitempty"`
+	ProcessedAt CustomTime       `json:"processed_at"`
+	RefundedAt  *CustomTime      `json:"refunded_at,omitempty"`
 }
@@ -0,0 +1,78 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/Aleksei-D/go-loyalty-system/internal/logger"
+	"go.uber.org/zap"
+	"os"
+	"sync"
+	"time"
+)
+
+type Notifier interface {
+	SendPasswordReset(ctx context.Context, login, token string, expiresAt time.Time) error
+}
+
+type LogNotifier struct{}
+
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{}
+}
+
+func (l *LogNotifier) SendPasswordReset(_ context.Context, login, token string, expiresAt time.Time) error {
+	logger.Log.Info(
+		"password reset requested",
+		zap.String("login", login),
+		zap.String("token", token),
+		zap.Time("expires_at", expiresAt),
+	)
+	return nil
+}
+
+type message struct {
+	Kind      string    `json:"kind"`
+	Login     string    `json:"login"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+	SentAt    time.Time `json:"sent_at"`
+}
+
+// FileNotifier appends every notification as a JSON line to a local file.
+type FileNotifier struct {
+	mu   sync.Mutex
+	path string
+}
+
+func NewFileNotifier(path string) *FileNotifier {
+	return &FileNotifier{path: path}
+}
+
+func (f *FileNotifier) SendPasswordReset(_ context.Context, login, token string, expiresAt time.Time) error {
+	return f.write(&message{
+		Kind:      "password_reset",
+		Login:     login,
+		Token:     token,
+		ExpiresAt: expiresAt,
+		SentAt:    time.Now(),
+	})
+}
+
+func (f *FileNotifier) write(msg *message) error {
+	buf, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	file, err := os.OpenFile(f.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(buf, '\n'))
+	return err
+}
@@ -0,0 +1,42 @@
+package notifier
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileNotifier_SendPasswordReset(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.jsonl")
+	fileNotifier := NewFileNotifier(path)
+	expiresAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+
+	require.NoError(t, fileNotifier.SendPasswordReset(context.Background(), "alice", "token-1", expiresAt))
+	require.NoError(t, fileNotifier.SendPasswordReset(context.Background(), "bob", "token-2", expiresAt))
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	messages := make([]message, 0)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var msg message
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &msg))
+		messages = append(messages, msg)
+	}
+	require.NoError(t, scanner.Err())
+
+	require.Len(t, messages, 2)
+	assert.Equal(t, "password_reset", messages[0].Kind)
+	assert.Equal(t, "alice", messages[0].Login)
+	assert.Equal(t, "token-1", messages[0].Token)
+	assert.True(t, expiresAt.Equal(messages[0].ExpiresAt))
+	assert.Equal(t, "bob", messages[1].Login)
+}
@@ -2,21 +2,32 @@ package agent
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"github.com/Aleksei-D/go-loyalty-system/internal/config"
 	"github.com/Aleksei-D/go-loyalty-system/internal/logger"
 	"github.com/Aleksei-D/go-loyalty-system/internal/models"
 	"github.com/Aleksei-D/go-loyalty-system/internal/service"
 	"go.uber.org/zap"
+	"os"
+	"sync"
 	"time"
 )
 
 const orderChunkSizeDefault = 5
 
+// OrdersAgent polls the accrual system with a fixed pool of workers. Claimed orders are handed to the
+// workers one by one, so nothing is claimed while every worker is busy, and all requests share one
+// rate limiter that also holds the whole pool when the accrual system answers 429.
 type OrdersAgent struct {
 	orderService   *service.OrderService
 	httpClient     *StatusUpdaterClient
 	config         *config.Config
+	limiter        *tokenBucket
+	workers        uint
+	pollInterval   time.Duration
 	orderChunkSize uint
+	workerID       string
 }
 
 func NewOrdersAgent(orderService *service.OrderService, config *config.Config) *OrdersAgent {
@@ -24,107 +35,111 @@ func NewOrdersAgent(orderService *service.OrderService, config *config.Config) *
 		orderService:   orderService,
 		config:         config,
 		httpClient:     NewClientAgent(*config.AccrualSystemAddress),
+		limiter:        newTokenBucket(*config.RateLimit),
+		workers:        max(*config.Workers, 1),
+		pollInterval:   time.Duration(*config.PollInterval) * time.Second,
 		orderChunkSize: orderChunkSizeDefault,
+		workerID:       newWorkerID(),
 	}
 }
 
-func (o *OrdersAgent) Run(ctx context.Context) {
-	doneCh := make(chan struct{})
-	defer close(doneCh)
-	pollTicker := time.NewTicker(time.Duration(*o.config.PollInterval) * time.Second)
-	defer pollTicker.Stop()
-
-	errorCh := make(chan error)
-	defer close(errorCh)
+// newWorkerID names the claims of this process, so a lease can be traced back to the instance holding it.
+func newWorkerID() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "gophermart"
+	}
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+}
 
-	orderNumberCh := o.orderGenerator(ctx, doneCh, errorCh, pollTicker)
-	updateDataOrderCh := o.OrdersStatusGenerator(ctx, doneCh, orderNumberCh, errorCh)
-	go o.updateStatusOrder(ctx, doneCh, updateDataOrderCh, errorCh)
+// Run returns once the context is done and every worker has stopped. Orders claimed but not checked
+// by then keep their lease and are claimed again after it expires.
+func (o *OrdersAgent) Run(ctx context.Context) {
+	defer o.httpClient.CloseIdleConnections()
 
-	for err := range errorCh {
-		logger.Log.Warn(err.Error(), zap.Error(err))
+	orderCh := make(chan *models.Order)
+	var wg sync.WaitGroup
+	for i := uint(0); i < o.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			o.worker(ctx, orderCh)
+		}()
 	}
+
+	o.claimOrders(ctx, orderCh)
+	wg.Wait()
+	logger.Log.Info("Orders agent: Context done, exiting.")
 }
 
-func (o *OrdersAgent) updateStatusOrder(ctx context.Context, doneCh chan struct{}, updateDataOrderCh <-chan *models.Order, errorCh chan<- error) {
+func (o *OrdersAgent) claimOrders(ctx context.Context, orderCh chan<- *models.Order) {
+	defer close(orderCh)
+	pollTicker := time.NewTicker(o.pollInterval)
+	defer pollTicker.Stop()
+
 	for {
 		select {
 		case <-ctx.Done():
-			logger.Log.Info("Worker: Context done, exiting.")
 			return
-		case <-doneCh:
-			return
-		case order := <-updateDataOrderCh:
-			err := o.orderService.UpdateStatus(ctx, order)
-			if err != nil {
-				errorCh <- err
-			}
+		case <-pollTicker.C:
 		}
-	}
-}
-
-func (o *OrdersAgent) orderGenerator(ctx context.Context, doneCh chan struct{}, errorCh chan<- error, pollTicker *time.Ticker) <-chan *models.Order {
-	orderCh := make(chan *models.Order, o.orderChunkSize)
-	go func() {
-		defer close(orderCh)
-	newOrderLoop:
-		for {
-			select {
-			case <-ctx.Done():
-				logger.Log.Info("Worker: Context done, exiting.")
-				return
-			case <-doneCh:
-				return
-			case <-pollTicker.C:
-				orders, err := o.orderService.GetNotAcceptedOrderNumbers(ctx, o.orderChunkSize, *o.config.UpdateTimeout)
-				if err != nil {
-					errorCh <- err
-					continue newOrderLoop
-				}
 
-				for _, order := range orders {
-					orderCh <- order
-				}
-			}
+		orders, err := o.orderService.ClaimOrders(ctx, o.claim())
+		if err != nil {
+			logger.Log.Warn(err.Error(), zap.Error(err))
+			continue
 		}
-	}()
-	return orderCh
-}
-
-func (o *OrdersAgent) OrdersStatusGenerator(ctx context.Context, doneCh chan struct{}, orderCh <-chan *models.Order, errorCh chan<- error) <-chan *models.Order {
-	orderNewStatusCh := make(chan *models.Order, o.orderChunkSize)
-	go func() {
-		defer close(orderNewStatusCh)
-		for {
+		for _, order := range orders {
 			select {
 			case <-ctx.Done():
-				logger.Log.Info("Worker: Context done, exiting.")
-				return
-			case <-doneCh:
 				return
-			default:
-				for w := 1; w <= int(*o.config.RateLimit); w++ {
-					go o.getOrdersStatus(ctx, doneCh, orderCh, orderNewStatusCh, errorCh)
-				}
+			case orderCh <- order:
 			}
 		}
-	}()
-	return orderNewStatusCh
+	}
 }
 
-func (o *OrdersAgent) getOrdersStatus(ctx context.Context, doneCh chan struct{}, orderCh <-chan *models.Order, orderNewStatusCh chan<- *models.Order, errorCh chan<- error) {
-	select {
-	case <-ctx.Done():
-		logger.Log.Info("Worker: Context done, exiting.")
-		return
-	case <-doneCh:
-		return
-	case newOrder := <-orderCh:
-		order, err := o.httpClient.getOrderStatus(newOrder.Number)
-		if err != nil {
-			errorCh <- err
+func (o *OrdersAgent) worker(ctx context.Context, orderCh <-chan *models.Order) {
+	for order := range orderCh {
+		if err := o.limiter.Wait(ctx); err != nil {
 			return
 		}
-		orderNewStatusCh <- order.ToOrder()
+		if err := o.checkOrder(ctx, order.Number); err != nil && ctx.Err() == nil {
+			logger.Log.Warn(err.Error(), zap.String("order", order.Number), zap.Error(err))
+		}
+	}
+}
+
+func (o *OrdersAgent) checkOrder(ctx context.Context, orderNumber string) error {
+	response, err := o.httpClient.getOrderStatus(ctx, orderNumber)
+	var rateLimited *RateLimitedError
+	if errors.As(err, &rateLimited) {
+		o.limiter.Pause(rateLimited.RetryAfter)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	order, err := response.ToOrder()
+	if err != nil {
+		return err
+	}
+	return o.orderService.UpdateStatus(ctx, order)
+}
+
+// claim asks for orders not checked within the update timeout. With accrual callbacks enabled polling
+// only reconciles orders whose callback got lost, so it waits for the longer reconcile timeout.
+func (o *OrdersAgent) claim() *models.OrderClaim {
+	updateTimeout := *o.config.UpdateTimeout
+	if *o.config.AccrualCallbackKey != "" {
+		updateTimeout = max(updateTimeout, *o.config.ReconcileTimeout)
+	}
+	return &models.OrderClaim{
+		Worker:        o.workerID,
+		Limit:         o.orderChunkSize,
+		UpdateTimeout: time.Duration(updateTimeout) * time.Second,
+		Lease:         time.Duration(*o.config.OrderLease) * time.Second,
+		MaxAttempts:   *o.config.OrderMaxAttempts,
 	}
 }
@@ -0,0 +1,204 @@
+package agent
+
+import (
+	"context"
+	stub "github.com/Aleksei-D/go-loyalty-system/internal/accrual_stub"
+	"github.com/Aleksei-D/go-loyalty-system/internal/config"
+	"github.com/Aleksei-D/go-loyalty-system/internal/domain/memory"
+	"github.com/Aleksei-D/go-loyalty-system/internal/models"
+	"github.com/Aleksei-D/go-loyalty-system/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+func uintPtr(v uint) *uint {
+	return &v
+}
+
+func stringPtr(v string) *string {
+	return &v
+}
+
+func newTestAgent(t *testing.T, accrualURL string, rateLimit uint, orderNumbers ...string) (*OrdersAgent, *service.OrderService) {
+	t.Helper()
+	ctx := context.Background()
+	storage := memory.NewStorage()
+	_, err := memory.NewUserRepository(storage).Create(ctx, &models.User{Login: "alice"})
+	require.NoError(t, err)
+	orderService := service.NewOrderService(memory.NewOrderRepository(storage), nil, nil, nil)
+	for _, number := range orderNumbers {
+		_, err = orderService.AddOrder(ctx, number, "alice")
+		require.NoError(t, err)
+	}
+
+	cfg := &config.Config{
+		AccrualSystemAddress: stringPtr(accrualURL),
+		PollInterval:         uintPtr(1),
+		RateLimit:            uintPtr(rateLimit),
+		Workers:              uintPtr(3),
+		UpdateTimeout:        uintPtr(0),
+		AccrualCallbackKey:   stringPtr(""),
+		ReconcileTimeout:     uintPtr(0),
+		OrderLease:           uintPtr(0),
+		OrderMaxAttempts:     uintPtr(0),
+	}
+	agent := NewOrdersAgent(orderService, cfg)
+	agent.pollInterval = 10 * time.Millisecond
+	return agent, orderService
+}
+
+// runAgent starts the agent and returns a stop function that fails the test if the agent
+// does not return or leaves goroutines behind.
+func runAgent(t *testing.T, agent *OrdersAgent) func() {
+	t.Helper()
+	before := runtime.NumGoroutine()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		agent.Run(ctx)
+	}()
+
+	return func() {
+		t.Helper()
+		cancel()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("orders agent did not stop")
+		}
+		// Polled by hand: assert.Eventually runs the condition in a goroutine of its own.
+		deadline := time.Now().Add(2 * time.Second)
+		for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+		}
+		if runtime.NumGoroutine() > before {
+			buf := make([]byte, 1<<16)
+			t.Errorf("orders agent leaked goroutines:\n%s", buf[:runtime.Stack(buf, true)])
+		}
+	}
+}
+
+func orderStatus(t *testing.T, orderService *service.OrderService, number string) models.OrderStatus {
+	t.Helper()
+	order, err := orderService.GetOrder(context.Background(), "alice", number)
+	require.NoError(t, err)
+	return order.Status
+}
+
+func TestOrdersAgent_Run(t *testing.T) {
+	accrual := models.Money(50000)
+	rules := stub.Rules{
+		{Prefix: "1", Status: string(models.OrderStatusProcessed), Accrual: &accrual},
+		{Prefix: "8", Status: string(models.OrderStatusInvalid)},
+	}
+	accrualServer := httptest.NewServer(stub.NewAccrualStub(rules, 0, 0).Router())
+	defer accrualServer.Close()
+
+	agent, orderService := newTestAgent(t, accrualServer.URL, 100, "12345678903", "83", "9278923470")
+	stop := runAgent(t, agent)
+	assert.Eventually(t, func() bool {
+		return orderStatus(t, orderService, "12345678903") == models.OrderStatusProcessed &&
+			orderStatus(t, orderService, "83") == models.OrderStatusInvalid
+	}, 5*time.Second, 20*time.Millisecond)
+	stop()
+
+	assert.Equal(t, models.OrderStatusNew, orderStatus(t, orderService, "9278923470"), "unregistered order keeps waiting")
+}
+
+func TestOrdersAgent_RateLimit(t *testing.T) {
+	var mu sync.Mutex
+	requests := make([]time.Time, 0)
+	accrualServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, time.Now())
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer accrualServer.Close()
+
+	agent, _ := newTestAgent(t, accrualServer.URL, 5, "12345678903", "9278923470", "346436439")
+	stop := runAgent(t, agent)
+	time.Sleep(time.Second)
+	stop()
+
+	mu.Lock()
+	defer mu.Unlock()
+	// A full bucket of 5 plus 5 refilled tokens within the second.
+	assert.LessOrEqual(t, len(requests), 11)
+	assert.GreaterOrEqual(t, len(requests), 5)
+}
+
+func TestOrdersAgent_RetryAfter(t *testing.T) {
+	var mu sync.Mutex
+	requests := make([]time.Time, 0)
+	accrualServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests = append(requests, time.Now())
+		if len(requests) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"INVALID"}`))
+	}))
+	defer accrualServer.Close()
+
+	agent, orderService := newTestAgent(t, accrualServer.URL, 0, "12345678903", "9278923470")
+	stop := runAgent(t, agent)
+	assert.Eventually(t, func() bool {
+		return orderStatus(t, orderService, "12345678903") == models.OrderStatusInvalid &&
+			orderStatus(t, orderService, "9278923470") == models.OrderStatusInvalid
+	}, 5*time.Second, 20*time.Millisecond)
+	stop()
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.GreaterOrEqual(t, len(requests), 2)
+	// Other workers may have had a request in flight when the 429 arrived, but nothing new was sent during the pause.
+	for _, at := range requests[1:] {
+		if at.Sub(requests[0]) > 100*time.Millisecond {
+			assert.GreaterOrEqual(t, at.Sub(requests[0]), 900*time.Millisecond)
+		}
+	}
+}
+
+func TestOrdersAgent_StopsWhilePaused(t *testing.T) {
+	accrualServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer accrualServer.Close()
+
+	agent, orderService := newTestAgent(t, accrualServer.URL, 0, "12345678903", "9278923470", "346436439", "18")
+	stop := runAgent(t, agent)
+	time.Sleep(200 * time.Millisecond)
+	stop()
+
+	assert.Equal(t, models.OrderStatusNew, orderStatus(t, orderService, "12345678903"))
+}
+
+func TestOrdersAgent_StopsWithSlowAccrualSystem(t *testing.T) {
+	release := make(chan struct{})
+	accrualServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer accrualServer.Close()
+	defer close(release)
+
+	agent, _ := newTestAgent(t, accrualServer.URL, 0, "12345678903", "9278923470", "346436439", "18", "26")
+	stop := runAgent(t, agent)
+	time.Sleep(200 * time.Millisecond)
+	stop()
+}
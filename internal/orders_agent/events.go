@@ -0,0 +1,54 @@
+package agent
+
+import (
+	"context"
+	"github.com/Aleksei-D/go-loyalty-system/internal/logger"
+	"github.com/Aleksei-D/go-loyalty-system/internal/service"
+	"go.uber.org/zap"
+	"time"
+)
+
+const eventChunkSizeDefault = 100
+
+// EventsAgent drains the outbox into the configured event sinks.
+type EventsAgent struct {
+	eventService   *service.EventService
+	interval       time.Duration
+	eventChunkSize uint
+}
+
+func NewEventsAgent(eventService *service.EventService, interval time.Duration) *EventsAgent {
+	return &EventsAgent{
+		eventService:   eventService,
+		interval:       interval,
+		eventChunkSize: eventChunkSizeDefault,
+	}
+}
+
+func (e *EventsAgent) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Log.Info("Events agent: Context done, exiting.")
+			return
+		case <-ticker.C:
+			e.dispatch(ctx)
+		}
+	}
+}
+
+func (e *EventsAgent) dispatch(ctx context.Context) {
+	for ctx.Err() == nil {
+		claimed, err := e.eventService.Dispatch(ctx, e.eventChunkSize)
+		if err != nil {
+			logger.Log.Warn(err.Error(), zap.Error(err))
+			return
+		}
+		if claimed < e.eventChunkSize {
+			return
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package agent
+
+import (
+	"context"
+	"github.com/Aleksei-D/go-loyalty-system/internal/logger"
+	"github.com/Aleksei-D/go-loyalty-system/internal/service"
+	"go.uber.org/zap"
+	"time"
+)
+
+const holdChunkSizeDefault = 100
+
+// HoldsAgent releases the points of holds that were neither captured nor released before they expired.
+type HoldsAgent struct {
+	withdrawalService *service.WithdrawalService
+	interval          time.Duration
+	holdChunkSize     uint
+}
+
+func NewHoldsAgent(withdrawalService *service.WithdrawalService, interval time.Duration) *HoldsAgent {
+	return &HoldsAgent{
+		withdrawalService: withdrawalService,
+		interval:          interval,
+		holdChunkSize:     holdChunkSizeDefault,
+	}
+}
+
+func (h *HoldsAgent) Run(ctx context.Context) {
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Log.Info("Holds agent: Context done, exiting.")
+			return
+		case <-ticker.C:
+			h.expireHolds(ctx)
+		}
+	}
+}
+
+func (h *HoldsAgent) expireHolds(ctx context.Context) {
+	for {
+		expired, err := h.withdrawalService.ExpireHolds(ctx, h.holdChunkSize)
+		if err != nil {
+			logger.Log.Warn(err.Error(), zap.Error(err))
+			return
+		}
+		if expired > 0 {
+			logger.Log.Info("holds expired", zap.Uint("count", expired))
+		}
+		if expired < h.holdChunkSize {
+			return
+		}
+	}
+}
@@ -1,99 +1,91 @@
 package agent
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"github.com/Aleksei-D/go-loyalty-system/internal/logger"
 	"github.com/Aleksei-D/go-loyalty-system/internal/models"
-	"github.com/Aleksei-D/go-loyalty-system/internal/utils/delay"
 	"io"
 	"net/http"
 	"strconv"
 	"time"
 )
 
-const acceptedOrderURL = "/api/orders/"
+const (
+	acceptedOrderURL      = "/api/orders/"
+	accrualRequestTimeout = 10 * time.Second
+	defaultRetryAfter     = 60 * time.Second
+)
+
+var errOrderNotRegistered = errors.New("order is not registered in the accrual system")
+
+// RateLimitedError is returned when the accrual system answers 429; nothing should be sent before RetryAfter passes.
+type RateLimitedError struct {
+	RetryAfter time.Duration
+}
 
+func (e *RateLimitedError) Error() string {
+	return fmt.Sprintf("accrual system rate limit exceeded, retry after %s", e.RetryAfter)
+}
+
+// StatusUpdaterClient sends a single request per call; a failed check is retried once the order's lease expires.
 type StatusUpdaterClient struct {
 	*http.Client
 	url string
 }
 
-type retryRoundTripper struct {
-	next       http.RoundTripper
-	maxRetries uint
-}
-
 func NewClientAgent(url string) *StatusUpdaterClient {
 	return &StatusUpdaterClient{
-		Client: &http.Client{
-			Transport: &retryRoundTripper{
-				maxRetries: 3,
-				next:       http.DefaultTransport,
-			},
-		},
-		url: url,
+		Client: &http.Client{Timeout: accrualRequestTimeout},
+		url:    url,
 	}
 }
 
-func (rr retryRoundTripper) RoundTrip(r *http.Request) (*http.Response, error) {
-	var resp *http.Response
-	var err error
-	var seconds time.Duration
-	newDelay := delay.NewDelay()
-	for attempts := 0; attempts < int(rr.maxRetries); attempts++ {
-		resp, err = rr.next.RoundTrip(r)
-		if resp != nil {
-			switch resp.StatusCode {
-			case http.StatusTooManyRequests:
-				retryAfter := resp.Header.Get("Retry-After")
-				if retryAfter != "" {
-					retryAfterSeconds, err := strconv.Atoi(retryAfter)
-					if err != nil {
-						logger.Log.Warn("Could not parse Retry-After header.")
-						seconds = newDelay()
-					} else {
-						seconds = time.Duration(retryAfterSeconds) * time.Second
-					}
-
-				}
-			default:
-				seconds = newDelay()
-			}
-		}
-
-		select {
-		case <-r.Context().Done():
-
-			return resp, r.Context().Err()
-		case <-time.After(seconds):
-		}
-	}
-	return resp, err
-}
-
-func (s *StatusUpdaterClient) getOrderStatus(orderNumber string) (*models.OrderStatusResponse, error) {
-	var order models.OrderStatusResponse
+func (s *StatusUpdaterClient) getOrderStatus(ctx context.Context, orderNumber string) (*models.OrderStatusResponse, error) {
 	url := fmt.Sprintf("%s%s%s", s.url, acceptedOrderURL, orderNumber)
-	req, err := http.NewRequest(http.MethodGet, url, bytes.NewBuffer(nil))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return &order, err
+		return nil, err
 	}
 
 	response, err := s.Do(req)
 	if err != nil {
-		return &order, err
+		return nil, err
 	}
+	defer response.Body.Close()
 
 	buf, err := io.ReadAll(response.Body)
-	defer response.Body.Close()
 	if err != nil {
-		return &order, err
+		return nil, err
+	}
+
+	switch response.StatusCode {
+	case http.StatusOK:
+	case http.StatusNoContent:
+		return nil, fmt.Errorf("%w: %s", errOrderNotRegistered, orderNumber)
+	case http.StatusTooManyRequests:
+		return nil, &RateLimitedError{RetryAfter: parseRetryAfter(response.Header.Get("Retry-After"))}
+	default:
+		return nil, fmt.Errorf("accrual system answered %s for order %s", response.Status, orderNumber)
 	}
 
+	var order models.OrderStatusResponse
 	if err = json.Unmarshal(buf, &order); err != nil {
-		return &order, err
+		return nil, err
+	}
+	if order.Order == "" {
+		order.Order = orderNumber
 	}
 	return &order, nil
 }
+
+func parseRetryAfter(value string) time.Duration {
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(time.Until(at), 0)
+	}
+	return defaultRetryAfter
+}
@@ -0,0 +1,73 @@
+package agent
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// tokenBucket lets through rate requests per second with bursts of up to rate, and holds every caller
+// while the accrual system asked to back off. A zero rate only honors the pauses.
+type tokenBucket struct {
+	mu          sync.Mutex
+	rate        float64
+	tokens      float64
+	updatedAt   time.Time
+	pausedUntil time.Time
+}
+
+func newTokenBucket(rate uint) *tokenBucket {
+	return &tokenBucket{rate: float64(rate), tokens: float64(rate), updatedAt: time.Now()}
+}
+
+// Wait blocks until a request may be sent or the context is done.
+func (b *tokenBucket) Wait(ctx context.Context) error {
+	for {
+		wait := b.reserve(time.Now())
+		if wait <= 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Pause stops all requests for d; the bucket starts empty afterwards so the pause is not followed by a burst.
+func (b *tokenBucket) Pause(d time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if until := time.Now().Add(d); until.After(b.pausedUntil) {
+		b.pausedUntil = until
+		b.tokens = 0
+		b.updatedAt = until
+	}
+}
+
+func (b *tokenBucket) reserve(now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if now.Before(b.pausedUntil) {
+		return b.pausedUntil.Sub(now)
+	}
+	if b.rate == 0 {
+		return 0
+	}
+
+	if now.After(b.updatedAt) {
+		b.tokens = min(b.rate, b.tokens+now.Sub(b.updatedAt).Seconds()*b.rate)
+		b.updatedAt = now
+	}
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+}
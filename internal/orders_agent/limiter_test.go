@@ -0,0 +1,48 @@
+package agent
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestTokenBucket_Wait(t *testing.T) {
+	ctx := context.Background()
+	bucket := newTokenBucket(10)
+
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		require.NoError(t, bucket.Wait(ctx))
+	}
+	assert.Less(t, time.Since(start), 50*time.Millisecond, "a full bucket allows a burst")
+
+	start = time.Now()
+	for i := 0; i < 5; i++ {
+		require.NoError(t, bucket.Wait(ctx))
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 450*time.Millisecond, "an empty bucket refills at the rate")
+}
+
+func TestTokenBucket_Pause(t *testing.T) {
+	ctx := context.Background()
+	for _, rate := range []uint{0, 100} {
+		bucket := newTokenBucket(rate)
+		bucket.Pause(200 * time.Millisecond)
+		bucket.Pause(50 * time.Millisecond)
+
+		start := time.Now()
+		require.NoError(t, bucket.Wait(ctx))
+		assert.GreaterOrEqual(t, time.Since(start), 190*time.Millisecond, "a shorter pause does not cut a longer one")
+	}
+}
+
+func TestTokenBucket_WaitCanceled(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	bucket := newTokenBucket(1)
+	bucket.Pause(time.Minute)
+
+	assert.ErrorIs(t, bucket.Wait(ctx), context.DeadlineExceeded)
+}
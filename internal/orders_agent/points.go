@@ -0,0 +1,36 @@
+package agent
+
+import (
+	"context"
+	"github.com/Aleksei-D/go-loyalty-system/internal/logger"
+	"github.com/Aleksei-D/go-loyalty-system/internal/service"
+	"go.uber.org/zap"
+	"time"
+)
+
+// PointsAgent periodically expires accrued points that were not spent in time.
+type PointsAgent struct {
+	balanceService *service.BalanceService
+	interval       time.Duration
+}
+
+func NewPointsAgent(balanceService *service.BalanceService, interval time.Duration) *PointsAgent {
+	return &PointsAgent{balanceService: balanceService, interval: interval}
+}
+
+func (p *PointsAgent) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Log.Info("Points agent: Context done, exiting.")
+			return
+		case <-ticker.C:
+			if _, err := p.balanceService.ExpirePoints(ctx); err != nil {
+				logger.Log.Warn(err.Error(), zap.Error(err))
+			}
+		}
+	}
+}
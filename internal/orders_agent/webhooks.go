@@ -0,0 +1,54 @@
+package agent
+
+import (
+	"context"
+	"github.com/Aleksei-D/go-loyalty-system/internal/logger"
+	"github.com/Aleksei-D/go-loyalty-system/internal/service"
+	"go.uber.org/zap"
+	"time"
+)
+
+const webhookChunkSizeDefault = 50
+
+// WebhooksAgent sends queued webhook deliveries to the subscribers.
+type WebhooksAgent struct {
+	webhookService   *service.WebhookService
+	interval         time.Duration
+	webhookChunkSize uint
+}
+
+func NewWebhooksAgent(webhookService *service.WebhookService, interval time.Duration) *WebhooksAgent {
+	return &WebhooksAgent{
+		webhookService:   webhookService,
+		interval:         interval,
+		webhookChunkSize: webhookChunkSizeDefault,
+	}
+}
+
+func (wa *WebhooksAgent) Run(ctx context.Context) {
+	ticker := time.NewTicker(wa.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Log.Info("Webhooks agent: Context done, exiting.")
+			return
+		case <-ticker.C:
+			wa.deliver(ctx)
+		}
+	}
+}
+
+func (wa *WebhooksAgent) deliver(ctx context.Context) {
+	for ctx.Err() == nil {
+		claimed, err := wa.webhookService.DeliverPending(ctx, wa.webhookChunkSize)
+		if err != nil {
+			logger.Log.Warn(err.Error(), zap.Error(err))
+			return
+		}
+		if claimed < wa.webhookChunkSize {
+			return
+		}
+	}
+}
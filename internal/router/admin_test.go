@@ -0,0 +1,232 @@
+package router
+
+import (
+	"encoding/json"
+	"github.com/Aleksei-D/go-loyalty-system/internal/config"
+	"github.com/Aleksei-D/go-loyalty-system/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+)
+
+func TestRouter_Admin(t *testing.T) {
+	server := newTestServer(t, func(cfg *config.Config) {
+		admins := "root"
+		cfg.AdminLogins = &admins
+	})
+	api := server.URL + "/api/user"
+	admin := server.URL + "/api/admin"
+
+	register := func(login string) (string, string) {
+		resp, body := doRequest(t, http.MethodPost, api+"/register", "", "application/json", `{"login":"`+login+`","password":"Correct-Horse-7"}`)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var pair models.TokenPair
+		require.NoError(t, json.Unmarshal([]byte(body), &pair))
+		return resp.Header.Get("Authorization"), pair.RefreshToken
+	}
+	rootToken, _ := register("root")
+	aliceToken, aliceRefresh := register("alice")
+
+	resp, _ := doRequest(t, http.MethodGet, admin+"/users", aliceToken, "", "")
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	resp, _ = doRequest(t, http.MethodGet, admin+"/users", "", "", "")
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	resp, body := doRequest(t, http.MethodGet, admin+"/users", rootToken, "", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.JSONEq(t, `[{"login":"alice","roles":["user"]},{"login":"root","roles":["user","admin"]}]`, body)
+
+	resp, _ = doRequest(t, http.MethodPost, api+"/orders", aliceToken, "text/plain", "12345678903")
+	require.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+	resp, body = doRequest(t, http.MethodGet, admin+"/users/alice/orders", rootToken, "", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var orders []models.Order
+	require.NoError(t, json.Unmarshal([]byte(body), &orders))
+	require.Len(t, orders, 1)
+	assert.Equal(t, "12345678903", orders[0].Number)
+
+	resp, body = doRequest(t, http.MethodGet, admin+"/users/alice/balance", rootToken, "", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.JSONEq(t, `{"current":0,"withdrawn":0}`, body)
+
+	resp, _ = doRequest(t, http.MethodGet, admin+"/users/alice/withdrawals", rootToken, "", "")
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	resp, _ = doRequest(t, http.MethodPost, admin+"/users/alice/balance/adjustments", aliceToken, "application/json", `{"amount":10,"reason":"self"}`)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	resp, _ = doRequest(t, http.MethodPost, admin+"/users/alice/balance/adjustments", rootToken, "application/json", `{"amount":10}`)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, _ = doRequest(t, http.MethodPost, admin+"/users/alice/balance/adjustments", rootToken, "application/json", `{"amount":-10,"reason":"fraud"}`)
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+
+	resp, body = doRequest(t, http.MethodPost, admin+"/users/alice/balance/adjustments", rootToken, "application/json", `{"amount":25.5,"reason":"goodwill"}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, body, `"operator":"root"`)
+
+	resp, body = doRequest(t, http.MethodGet, api+"/balance", aliceToken, "", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.JSONEq(t, `{"current":25.5,"withdrawn":0}`, body)
+
+	resp, body = doRequest(t, http.MethodGet, api+"/balance/history", aliceToken, "", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, body, `"comment":"goodwill"`)
+
+	resp, _ = doRequest(t, http.MethodPost, api+"/balance/withdraw", aliceToken, "application/json", `{"order":"2377225624","sum":20}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, _ = doRequest(t, http.MethodPost, admin+"/users/alice/withdrawals/79927398713/refund", rootToken, "", "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp, body = doRequest(t, http.MethodPost, admin+"/users/alice/withdrawals/2377225624/refund", rootToken, "", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, body, `"status":"REFUNDED"`)
+
+	resp, _ = doRequest(t, http.MethodPost, api+"/withdrawals/2377225624/cancel", aliceToken, "", "")
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+
+	resp, body = doRequest(t, http.MethodGet, api+"/balance", aliceToken, "", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.JSONEq(t, `{"current":25.5,"withdrawn":0}`, body)
+
+	resp, body = doRequest(t, http.MethodPost, api+"/balance/holds", aliceToken, "application/json", `{"order":"79927398713","sum":5.5}`)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	var hold models.Hold
+	require.NoError(t, json.Unmarshal([]byte(body), &hold))
+	assert.Equal(t, models.HoldStatusHeld, hold.Status)
+
+	resp, body = doRequest(t, http.MethodGet, api+"/balance", aliceToken, "", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.JSONEq(t, `{"current":20,"withdrawn":0,"held":5.5}`, body)
+
+	resp, _ = doRequest(t, http.MethodPost, api+"/balance/holds/unknown/capture", aliceToken, "", "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp, body = doRequest(t, http.MethodPost, api+"/balance/holds/"+hold.ID+"/capture", aliceToken, "", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, body, `"status":"CAPTURED"`)
+
+	resp, _ = doRequest(t, http.MethodPost, api+"/balance/holds/"+hold.ID+"/release", aliceToken, "", "")
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+
+	resp, body = doRequest(t, http.MethodGet, api+"/balance", aliceToken, "", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.JSONEq(t, `{"current":20,"withdrawn":5.5}`, body)
+
+	resp, _ = doRequest(t, http.MethodGet, admin+"/users/bob/orders", rootToken, "", "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp, _ = doRequest(t, http.MethodPost, admin+"/users/root/block", rootToken, "", "")
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+
+	resp, _ = doRequest(t, http.MethodPost, admin+"/users/alice/block", rootToken, "", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, _ = doRequest(t, http.MethodGet, api+"/orders", aliceToken, "", "")
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode, "blocking revokes issued tokens")
+
+	resp, _ = doRequest(t, http.MethodPost, api+"/token/refresh", "", "application/json", `{"refresh_token":"`+aliceRefresh+`"}`)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	resp, _ = doRequest(t, http.MethodPost, api+"/login", "", "application/json", `{"login":"alice","password":"Correct-Horse-7"}`)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	resp, body = doRequest(t, http.MethodGet, admin+"/users/alice", rootToken, "", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, body, `"blocked_at"`)
+
+	resp, _ = doRequest(t, http.MethodDelete, admin+"/users/alice/block", rootToken, "", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, _ = doRequest(t, http.MethodPost, api+"/login", "", "application/json", `{"login":"alice","password":"Correct-Horse-7"}`)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestRouter_AdminBonusRules(t *testing.T) {
+	server := newTestServer(t, func(cfg *config.Config) {
+		admins := "root"
+		cfg.AdminLogins = &admins
+	})
+	resp, _ := doRequest(t, http.MethodPost, server.URL+"/api/user/register", "", "application/json", `{"login":"root","password":"Correct-Horse-7"}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	rootToken := resp.Header.Get("Authorization")
+	rules := server.URL + "/api/admin/bonus-rules"
+
+	resp, body := doRequest(t, http.MethodGet, rules, rootToken, "", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.JSONEq(t, `{"rules":[]}`, body)
+
+	resp, _ = doRequest(t, http.MethodPut, rules, rootToken, "application/json", `{"rules":[{"name":"welcome"}]}`)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	update := `{"daily_cap":500,"rules":[{"name":"welcome","first_order":true,"amount":100}]}`
+	resp, _ = doRequest(t, http.MethodPut, rules, rootToken, "application/json", update)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, body = doRequest(t, http.MethodGet, rules, rootToken, "", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.JSONEq(t, update, body)
+}
+
+func TestRouter_AdminWebhooks(t *testing.T) {
+	server := newTestServer(t, func(cfg *config.Config) {
+		admins := "root"
+		cfg.AdminLogins = &admins
+	})
+	resp, _ := doRequest(t, http.MethodPost, server.URL+"/api/user/register", "", "application/json", `{"login":"root","password":"Correct-Horse-7"}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	rootToken := resp.Header.Get("Authorization")
+	webhooks := server.URL + "/api/admin/webhooks"
+
+	resp, _ = doRequest(t, http.MethodPost, webhooks, rootToken, "application/json", `{"url":"ftp://example.com"}`)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, body := doRequest(t, http.MethodPost, webhooks, rootToken, "application/json", `{"url":"https://example.com/hook","event_types":["order.processed"]}`)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	var subscription models.WebhookSubscription
+	require.NoError(t, json.Unmarshal([]byte(body), &subscription))
+	assert.NotEmpty(t, subscription.ID)
+	assert.NotEmpty(t, subscription.Secret)
+
+	resp, body = doRequest(t, http.MethodGet, webhooks, rootToken, "", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.NotContains(t, body, subscription.Secret)
+
+	resp, body = doRequest(t, http.MethodGet, webhooks+"/"+subscription.ID+"/deliveries?status=DEAD", rootToken, "", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.JSONEq(t, `[]`, body)
+
+	resp, _ = doRequest(t, http.MethodPost, webhooks+"/"+subscription.ID+"/deliveries/1/retry", rootToken, "", "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp, _ = doRequest(t, http.MethodDelete, webhooks+"/"+subscription.ID, rootToken, "", "")
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	resp, _ = doRequest(t, http.MethodGet, webhooks+"/"+subscription.ID+"/deliveries", rootToken, "", "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestRouter_AdminParkedOrders(t *testing.T) {
+	server := newTestServer(t, func(cfg *config.Config) {
+		admins := "root"
+		cfg.AdminLogins = &admins
+	})
+	resp, _ := doRequest(t, http.MethodPost, server.URL+"/api/user/register", "", "application/json", `{"login":"root","password":"Correct-Horse-7"}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	rootToken := resp.Header.Get("Authorization")
+	resp, _ = doRequest(t, http.MethodPost, server.URL+"/api/user/orders", rootToken, "text/plain", "12345678903")
+	require.Equal(t, http.StatusAccepted, resp.StatusCode)
+	orders := server.URL + "/api/admin/orders"
+
+	resp, body := doRequest(t, http.MethodGet, orders+"/parked", rootToken, "", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.JSONEq(t, `[]`, body)
+
+	resp, _ = doRequest(t, http.MethodPost, orders+"/12345678903/retry", rootToken, "", "")
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	resp, _ = doRequest(t, http.MethodPost, orders+"/9278923470/retry", rootToken, "", "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
@@ -3,38 +3,99 @@ package router
 import (
 	"github.com/Aleksei-D/go-loyalty-system/internal/handlers"
 	"github.com/Aleksei-D/go-loyalty-system/internal/middleware"
+	"github.com/Aleksei-D/go-loyalty-system/internal/models"
 	"github.com/Aleksei-D/go-loyalty-system/internal/service"
 	"github.com/go-chi/chi/v5"
 )
 
-func NewRouter(service *service.Service, secretKey string) chi.Router {
-	userAPIHandlers := handlers.NewUserHandlers(service.UserService, secretKey)
+func NewRouter(service *service.Service) chi.Router {
+	userAPIHandlers := handlers.NewUserHandlers(service.UserService, service.TokenService)
 	orderAPIHandlers := handlers.NewOrderHandler(service.OrderService)
 	withdrawHandlers := handlers.NewWithdrawHandler(service.WithdrawalService)
 	balanceHandlers := handlers.NewBalanceHandler(service.BalanceService)
+	passwordHandlers := handlers.NewPasswordHandlers(service.PasswordService, service.TokenService)
+	keysHandler := handlers.NewKeysHandler(service.TokenService)
+	adminHandlers := handlers.NewAdminHandlers(service.AdminService)
+	profileHandler := handlers.NewProfileHandler(service.TierService)
+	bonusHandler := handlers.NewBonusHandler(service.BonusService)
+	referralHandler := handlers.NewReferralHandler(service.ReferralService)
+	webhookHandler := handlers.NewWebhookHandler(service.WebhookService)
+	accrualHandler := handlers.NewAccrualHandler(service.AccrualService)
+	authMiddleware := middleware.AuthMiddleware(service.TokenService)
 
 	r := chi.NewRouter()
 
+	r.Get("/.well-known/jwks.json", keysHandler.APIGetJWKSHandler())
+	// Authenticated by the shared key signature rather than a user token.
+	r.Post("/internal/accrual/callback", accrualHandler.APIAccrualCallbackHandler())
+
 	r.Route("/api/user", func(r chi.Router) {
 		r.Use(middleware.CompressMiddleware)
 		r.Post("/register", userAPIHandlers.APIUserRegisterHandler())
 		r.Post("/login", userAPIHandlers.APIUserLoginHandler())
+		r.Post("/token/refresh", userAPIHandlers.APITokenRefreshHandler())
+		r.With(authMiddleware).Post("/logout", userAPIHandlers.APIUserLogoutHandler())
+		r.With(authMiddleware).Post("/password", passwordHandlers.APIChangePasswordHandler())
+		r.With(authMiddleware).Get("/profile", profileHandler.APIGetProfileHandler())
+		r.With(authMiddleware).Get("/referrals", referralHandler.APIGetReferralsHandler())
+		r.Post("/password/reset", passwordHandlers.APIPasswordResetHandler())
+		r.Post("/password/reset/confirm", passwordHandlers.APIPasswordResetConfirmHandler())
 
 		r.Route("/orders", func(r chi.Router) {
-			r.Use(middleware.AuthMiddleware(secretKey))
+			r.Use(authMiddleware)
 			r.Get("/", orderAPIHandlers.APIGetOrdersHandler())
 			r.Post("/", orderAPIHandlers.APIAddOrdersHandler())
+			r.Get("/{number}", orderAPIHandlers.APIGetOrderHandler())
+			r.Get("/{number}/history", orderAPIHandlers.APIGetOrderHistoryHandler())
 		})
 
 		r.Route("/balance", func(r chi.Router) {
-			r.Use(middleware.AuthMiddleware(secretKey))
+			r.Use(authMiddleware)
 			r.Get("/", balanceHandlers.APIGetBalanceHandler())
+			r.Get("/history", balanceHandlers.APIGetBalanceHistoryHandler())
 			r.Post("/withdraw", withdrawHandlers.APIWithdrawHandler())
+			r.Get("/holds", withdrawHandlers.APIGetHoldsHandler())
+			r.Post("/holds", withdrawHandlers.APIHoldHandler())
+			r.Post("/holds/{id}/capture", withdrawHandlers.APICaptureHoldHandler())
+			r.Post("/holds/{id}/release", withdrawHandlers.APIReleaseHoldHandler())
 		})
 
 		r.Route("/withdrawals", func(r chi.Router) {
-			r.Use(middleware.AuthMiddleware(secretKey))
+			r.Use(authMiddleware)
 			r.Get("/", withdrawHandlers.APIGetWithdrawalsHandler())
+			r.Post("/{order}/cancel", withdrawHandlers.APIRefundWithdrawalHandler())
+		})
+	})
+
+	r.Route("/api/admin", func(r chi.Router) {
+		r.Use(middleware.CompressMiddleware)
+		r.Use(authMiddleware)
+		r.Use(middleware.RequireRole(string(models.RoleAdmin)))
+		r.Get("/users", adminHandlers.APIGetUsersHandler())
+		r.Get("/bonus-rules", bonusHandler.APIGetBonusRulesHandler())
+		r.Put("/bonus-rules", bonusHandler.APIUpdateBonusRulesHandler())
+		r.Get("/orders/parked", orderAPIHandlers.APIGetParkedOrdersHandler())
+		r.Post("/orders/{number}/retry", orderAPIHandlers.APIUnparkOrderHandler())
+
+		r.Route("/webhooks", func(r chi.Router) {
+			r.Get("/", webhookHandler.APIGetWebhooksHandler())
+			r.Post("/", webhookHandler.APICreateWebhookHandler())
+			r.Delete("/{id}", webhookHandler.APIDeleteWebhookHandler())
+			r.Get("/{id}/deliveries", webhookHandler.APIGetDeliveriesHandler())
+			r.Post("/{id}/deliveries/{delivery}/retry", webhookHandler.APIRetryDeliveryHandler())
+		})
+
+		r.Route("/users/{login}", func(r chi.Router) {
+			r.Use(adminHandlers.UserContext)
+			r.Get("/", adminHandlers.APIGetUserHandler())
+			r.Get("/orders", orderAPIHandlers.APIGetOrdersHandler())
+			r.Get("/withdrawals", withdrawHandlers.APIGetWithdrawalsHandler())
+			r.Post("/withdrawals/{order}/refund", withdrawHandlers.APIRefundWithdrawalHandler())
+			r.Get("/balance", balanceHandlers.APIGetBalanceHandler())
+			r.Get("/balance/history", balanceHandlers.APIGetBalanceHistoryHandler())
+			r.Post("/balance/adjustments", balanceHandlers.APIAdjustBalanceHandler())
+			r.Post("/block", adminHandlers.APIBlockUserHandler())
+			r.Delete("/block", adminHandlers.APIUnblockUserHandler())
 		})
 	})
 	return r
@@ -0,0 +1,281 @@
+package router
+
+import (
+	"encoding/json"
+	"github.com/Aleksei-D/go-loyalty-system/internal/config"
+	"github.com/Aleksei-D/go-loyalty-system/internal/domain/memory"
+	"github.com/Aleksei-D/go-loyalty-system/internal/handlers"
+	"github.com/Aleksei-D/go-loyalty-system/internal/models"
+	"github.com/Aleksei-D/go-loyalty-system/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestServer(t *testing.T, configure ...func(cfg *config.Config)) *httptest.Server {
+	t.Helper()
+	require.NoError(t, config.InitDefaultEnv())
+	cfg, err := config.InitConfig()
+	require.NoError(t, err)
+	// Empty defaults are left unset by the env parser and are filled from flags in NewServerConfig.
+	fields := reflect.ValueOf(cfg).Elem()
+	for i := 0; i < fields.NumField(); i++ {
+		if field := fields.Field(i); field.IsNil() && field.Type().Elem().Kind() == reflect.String {
+			field.Set(reflect.New(field.Type().Elem()))
+		}
+	}
+	for _, fn := range configure {
+		fn(cfg)
+	}
+
+	storage := memory.NewStorage()
+	repos := &service.Repositories{
+		Balance:       memory.NewBalanceRepository(storage),
+		Order:         memory.NewOrderRepository(storage),
+		User:          memory.NewUserRepository(storage),
+		Withdrawal:    memory.NewWithdrawalRepository(storage),
+		Ledger:        memory.NewLedgerRepository(storage),
+		Token:         memory.NewTokenRepository(storage),
+		PasswordReset: memory.NewPasswordResetRepository(storage),
+		Hold:          memory.NewHoldRepository(storage),
+		Referral:      memory.NewReferralRepository(storage),
+		Outbox:        memory.NewOutboxRepository(storage),
+		Webhook:       memory.NewWebhookRepository(storage),
+	}
+	serviceApp, err := service.NewService(repos, cfg)
+	require.NoError(t, err)
+
+	server := httptest.NewServer(NewRouter(serviceApp))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func doRequest(t *testing.T, method, url, token, contentType, body string) (*http.Response, string) {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	require.NoError(t, err)
+	if token != "" {
+		req.Header.Set("Authorization", token)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	buf, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp, string(buf)
+}
+
+func TestRouter_MemoryStorage(t *testing.T) {
+	server := newTestServer(t)
+	api := server.URL + "/api/user"
+
+	resp, _ := doRequest(t, http.MethodPost, api+"/register", "", "application/json", `{"login":"alice","password":"Correct-Horse-7"}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	token := resp.Header.Get("Authorization")
+	require.NotEmpty(t, token)
+
+	resp, _ = doRequest(t, http.MethodPost, api+"/register", "", "application/json", `{"login":"alice","password":"Correct-Horse-7"}`)
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+
+	resp, _ = doRequest(t, http.MethodPost, api+"/orders", "", "text/plain", "12345678903")
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	resp, _ = doRequest(t, http.MethodPost, api+"/orders", token, "text/plain", "12345678903")
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+	resp, _ = doRequest(t, http.MethodPost, api+"/orders", token, "text/plain", "12345678903")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, _ = doRequest(t, http.MethodPost, api+"/orders", token, "text/plain", "12345678904")
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+
+	resp, body := doRequest(t, http.MethodGet, api+"/orders", token, "", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var orders []models.Order
+	require.NoError(t, json.Unmarshal([]byte(body), &orders))
+	require.Len(t, orders, 1)
+	assert.Equal(t, "12345678903", orders[0].Number)
+	assert.Equal(t, models.OrderStatusNew, orders[0].Status)
+
+	resp, _ = doRequest(t, http.MethodPost, api+"/balance/withdraw", token, "application/json", `{"order":"2377225624","sum":751}`)
+	assert.Equal(t, http.StatusPaymentRequired, resp.StatusCode)
+
+	resp, _ = doRequest(t, http.MethodGet, api+"/withdrawals", token, "", "")
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	resp, _ = doRequest(t, http.MethodPost, api+"/withdrawals/2377225624/cancel", token, "", "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp, _ = doRequest(t, http.MethodGet, api+"/withdrawals?status=PENDING", token, "", "")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, body = doRequest(t, http.MethodGet, api+"/balance", token, "", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.JSONEq(t, `{"current":0,"withdrawn":0}`, body)
+}
+
+func luhnNumber(prefix int) string {
+	digits := strconv.Itoa(prefix)
+	sum := 0
+	for i := len(digits) - 1; i >= 0; i-- {
+		digit := int(digits[i] - '0')
+		if (len(digits)-i)%2 == 1 {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+	}
+	return digits + strconv.Itoa((10-sum%10)%10)
+}
+
+func TestRouter_OrdersPagination(t *testing.T) {
+	server := newTestServer(t)
+	api := server.URL + "/api/user"
+
+	resp, _ := doRequest(t, http.MethodPost, api+"/register", "", "application/json", `{"login":"alice","password":"Correct-Horse-7"}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	token := resp.Header.Get("Authorization")
+
+	const ordersCount = 150
+	for i := 0; i < ordersCount; i++ {
+		resp, _ = doRequest(t, http.MethodPost, api+"/orders", token, "text/plain", luhnNumber(1000000+i))
+		require.Equal(t, http.StatusAccepted, resp.StatusCode)
+	}
+
+	resp, body := doRequest(t, http.MethodGet, api+"/orders", token, "", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var orders []models.Order
+	require.NoError(t, json.Unmarshal([]byte(body), &orders))
+	assert.Len(t, orders, ordersCount, "list without limit must not be truncated")
+	assert.Empty(t, resp.Header.Get("X-Next-Cursor"))
+
+	seen := make(map[string]bool)
+	next := api + "/orders?limit=100"
+	for pages := 0; next != ""; pages++ {
+		require.Less(t, pages, 2)
+		resp, body = doRequest(t, http.MethodGet, next, token, "", "")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var page []models.Order
+		require.NoError(t, json.Unmarshal([]byte(body), &page))
+		for _, order := range page {
+			assert.False(t, seen[order.Number], "order %s returned twice", order.Number)
+			seen[order.Number] = true
+		}
+
+		next = ""
+		if cursor := resp.Header.Get("X-Next-Cursor"); cursor != "" {
+			assert.Len(t, page, 100)
+			next = api + "/orders?limit=100&cursor=" + cursor
+		}
+	}
+	assert.Len(t, seen, ordersCount)
+}
+
+func TestRouter_Profile(t *testing.T) {
+	server := newTestServer(t, func(cfg *config.Config) {
+		tiers := "silver:0,gold:100"
+		cfg.Tiers = &tiers
+	})
+	api := server.URL + "/api/user"
+
+	resp, _ := doRequest(t, http.MethodGet, api+"/profile", "", "", "")
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	resp, _ = doRequest(t, http.MethodPost, api+"/register", "", "application/json", `{"login":"alice","password":"Correct-Horse-7"}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	token := resp.Header.Get("Authorization")
+
+	resp, body := doRequest(t, http.MethodGet, api+"/profile", token, "", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.JSONEq(t, `{"login":"alice","roles":["user"],"tier":"silver","accrued_total":0,"next_tier":{"name":"gold","remaining":100}}`, body)
+}
+
+func TestRouter_Referrals(t *testing.T) {
+	server := newTestServer(t)
+	api := server.URL + "/api/user"
+
+	resp, _ := doRequest(t, http.MethodPost, api+"/register", "", "application/json", `{"login":"alice","password":"Correct-Horse-7"}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	token := resp.Header.Get("Authorization")
+
+	resp, body := doRequest(t, http.MethodGet, api+"/referrals", token, "", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var stats models.ReferralStats
+	require.NoError(t, json.Unmarshal([]byte(body), &stats))
+	require.NotEmpty(t, stats.Code)
+	assert.Empty(t, stats.Referrals)
+
+	resp, _ = doRequest(t, http.MethodPost, api+"/register", "", "application/json", `{"login":"bob","password":"Correct-Horse-7","referral_code":"NOPE"}`)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, _ = doRequest(t, http.MethodPost, api+"/register", "", "application/json", `{"login":"bob","password":"Correct-Horse-7","referral_code":"`+stats.Code+`"}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, body = doRequest(t, http.MethodGet, api+"/referrals", token, "", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.NoError(t, json.Unmarshal([]byte(body), &stats))
+	assert.Equal(t, 1, stats.Invited)
+	assert.Equal(t, 0, stats.Rewarded)
+	require.Len(t, stats.Referrals, 1)
+	assert.Equal(t, "bob", stats.Referrals[0].Referee)
+}
+
+func TestRouter_AccrualCallback(t *testing.T) {
+	key := "0123456789abcdef"
+	server := newTestServer(t, func(cfg *config.Config) {
+		cfg.AccrualCallbackKey = &key
+	})
+	api := server.URL + "/api/user"
+
+	resp, _ := doRequest(t, http.MethodPost, api+"/register", "", "application/json", `{"login":"alice","password":"Correct-Horse-7"}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	token := resp.Header.Get("Authorization")
+	resp, _ = doRequest(t, http.MethodPost, api+"/orders", token, "text/plain", "12345678903")
+	require.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+	callback := func(signature, body string) int {
+		req, err := http.NewRequest(http.MethodPost, server.URL+"/internal/accrual/callback", strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		if signature != "" {
+			req.Header.Set(handlers.AccrualSignatureHeader, signature)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	body := `{"order":"12345678903","status":"PROCESSED","accrual":500}`
+	assert.Equal(t, http.StatusUnauthorized, callback("", body))
+	assert.Equal(t, http.StatusUnauthorized, callback(models.SignatureHeader(key, time.Now().Add(-time.Hour).Unix(), []byte(body)), body))
+	assert.Equal(t, http.StatusOK, callback(models.SignatureHeader(key, time.Now().Unix(), []byte(body)), body))
+
+	stale := `{"order":"12345678903","status":"PROCESSING"}`
+	assert.Equal(t, http.StatusConflict, callback(models.SignatureHeader(key, time.Now().Unix(), []byte(stale)), stale))
+
+	resp, respBody := doRequest(t, http.MethodGet, api+"/balance", token, "", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.JSONEq(t, `{"current":500,"withdrawn":0}`, respBody)
+}
+
+func TestRouter_AccrualCallbackDisabled(t *testing.T) {
+	server := newTestServer(t)
+	resp, _ := doRequest(t, http.MethodPost, server.URL+"/internal/accrual/callback", "", "application/json", `{}`)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/Aleksei-D/go-loyalty-system/internal/models"
+	"github.com/Aleksei-D/go-loyalty-system/internal/utils/common"
+	"time"
+)
+
+const AccrualSignatureTolerance = 5 * time.Minute
+
+// AccrualCallbackService applies order statuses pushed by the accrual system. The payload is an
+// OrderStatusResponse signed with the shared key the same way outgoing webhooks are signed.
+type AccrualCallbackService struct {
+	orderService *OrderService
+	key          string
+}
+
+func NewAccrualCallbackService(orderService *OrderService, key string) *AccrualCallbackService {
+	return &AccrualCallbackService{orderService: orderService, key: key}
+}
+
+// Enabled reports whether a shared key is configured; without one polling is the only source of statuses.
+func (a *AccrualCallbackService) Enabled() bool {
+	return a.key != ""
+}
+
+func (a *AccrualCallbackService) Handle(ctx context.Context, signature string, body []byte) (*models.Order, error) {
+	if !a.Enabled() {
+		return nil, common.ErrCallbackDisabled
+	}
+	if err := models.VerifySignature(a.key, signature, body, time.Now(), AccrualSignatureTolerance); err != nil {
+		return nil, err
+	}
+
+	var response models.OrderStatusResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("%w: %s", common.ErrInvalidCallback, err)
+	}
+	order, err := response.ToOrder()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", common.ErrInvalidCallback, err)
+	}
+	if err = a.orderService.UpdateStatus(ctx, order); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
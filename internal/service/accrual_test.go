@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"github.com/Aleksei-D/go-loyalty-system/internal/domain/memory"
+	"github.com/Aleksei-D/go-loyalty-system/internal/models"
+	"github.com/Aleksei-D/go-loyalty-system/internal/utils/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestAccrualCallbackService_Handle(t *testing.T) {
+	ctx := context.Background()
+	key := "0123456789abcdef"
+	storage := memory.NewStorage()
+	_, err := memory.NewUserRepository(storage).Create(ctx, &models.User{Login: "alice"})
+	require.NoError(t, err)
+	orderService := NewOrderService(memory.NewOrderRepository(storage), nil, nil, nil)
+	_, err = orderService.AddOrder(ctx, "12345678903", "alice")
+	require.NoError(t, err)
+	callbackService := NewAccrualCallbackService(orderService, key)
+
+	sign := func(body string) string {
+		return models.SignatureHeader(key, time.Now().Unix(), []byte(body))
+	}
+
+	tests := []struct {
+		name      string
+		body      string
+		signature string
+		wantErr   error
+	}{
+		{"unsigned", `{"order":"12345678903","status":"PROCESSING"}`, "", common.ErrInvalidSignature},
+		{"signed with another key", `{"order":"12345678903","status":"PROCESSING"}`,
+			models.SignatureHeader("another-secret-value", time.Now().Unix(), []byte(`{"order":"12345678903","status":"PROCESSING"}`)),
+			common.ErrInvalidSignature},
+		{"malformed", `{"order":`, sign(`{"order":`), common.ErrInvalidCallback},
+		{"unknown status", `{"order":"12345678903","status":"LOST"}`, sign(`{"order":"12345678903","status":"LOST"}`), common.ErrInvalidCallback},
+		{"unknown order", `{"order":"2377225624","status":"PROCESSING"}`, sign(`{"order":"2377225624","status":"PROCESSING"}`), common.ErrOrderNotFound},
+		{"processing", `{"order":"12345678903","status":"PROCESSING"}`, sign(`{"order":"12345678903","status":"PROCESSING"}`), nil},
+		{"processed", `{"order":"12345678903","status":"PROCESSED","accrual":729.98}`,
+			sign(`{"order":"12345678903","status":"PROCESSED","accrual":729.98}`), nil},
+		{"repeated", `{"order":"12345678903","status":"PROCESSED","accrual":729.98}`,
+			sign(`{"order":"12345678903","status":"PROCESSED","accrual":729.98}`), nil},
+		{"stale", `{"order":"12345678903","status":"PROCESSING"}`, sign(`{"order":"12345678903","status":"PROCESSING"}`), common.ErrIllegalStatusTransition},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := callbackService.Handle(ctx, tt.signature, []byte(tt.body))
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+
+	order, err := orderService.GetOrder(ctx, "alice", "12345678903")
+	require.NoError(t, err)
+	assert.Equal(t, models.OrderStatusProcessed, order.Status)
+	require.NotNil(t, order.Accrual)
+	assert.Equal(t, models.Money(72998), *order.Accrual)
+	assert.NotNil(t, order.CheckedAt, "a callback counts as a check, so polling backs off")
+
+	balance, err := memory.NewBalanceRepository(storage).Get(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, models.Money(72998), balance.Current, "the repeated callback is not credited twice")
+}
+
+func TestAccrualCallbackService_Disabled(t *testing.T) {
+	callbackService := NewAccrualCallbackService(nil, "")
+	assert.False(t, callbackService.Enabled())
+	_, err := callbackService.Handle(context.Background(), "", []byte(`{}`))
+	assert.ErrorIs(t, err, common.ErrCallbackDisabled)
+}
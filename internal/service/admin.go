@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/Aleksei-D/go-loyalty-system/internal/domain"
+	"github.com/Aleksei-D/go-loyalty-system/internal/logger"
+	"github.com/Aleksei-D/go-loyalty-system/internal/models"
+	"github.com/Aleksei-D/go-loyalty-system/internal/utils/common"
+	"go.uber.org/zap"
+)
+
+type AdminService struct {
+	userRepo  domain.UserRepository
+	tokenRepo domain.TokenRepository
+}
+
+func NewAdminService(userRepo domain.UserRepository, tokenRepo domain.TokenRepository) *AdminService {
+	return &AdminService{userRepo: userRepo, tokenRepo: tokenRepo}
+}
+
+func (a *AdminService) ListUsers(ctx context.Context) ([]*models.UserInfo, error) {
+	users, err := a.userRepo.GetAll(ctx)
+	if err != nil {
+		logger.Log.Warn(err.Error(), zap.Error(err))
+		return nil, err
+	}
+
+	infos := make([]*models.UserInfo, 0, len(users))
+	for _, user := range users {
+		infos = append(infos, user.Info())
+	}
+	return infos, nil
+}
+
+func (a *AdminService) GetUser(ctx context.Context, login string) (*models.UserInfo, error) {
+	exists, err := a.userRepo.IsExist(ctx, login)
+	if err != nil {
+		logger.Log.Warn(err.Error(), zap.Error(err))
+		return nil, err
+	}
+	if !exists {
+		return nil, common.ErrUserNotFound
+	}
+
+	user, err := a.userRepo.GetByLogin(ctx, login)
+	if err != nil {
+		logger.Log.Warn(err.Error(), zap.Error(err))
+		return nil, err
+	}
+	return user.Info(), nil
+}
+
+// Block stops the user from logging in and revokes every session they already have.
+func (a *AdminService) Block(ctx context.Context, actor, login string) error {
+	if actor == login {
+		return common.ErrSelfBlock
+	}
+
+	if err := a.userRepo.SetBlocked(ctx, login, true); err != nil {
+		return err
+	}
+	if err := a.tokenRepo.RevokeUserSessions(ctx, login); err != nil {
+		logger.Log.Warn(err.Error(), zap.Error(err))
+		return err
+	}
+	logger.Log.Info("user blocked", zap.String("login", login), zap.String("by", actor))
+	return nil
+}
+
+func (a *AdminService) Unblock(ctx context.Context, actor, login string) error {
+	if err := a.userRepo.SetBlocked(ctx, login, false); err != nil {
+		return err
+	}
+	logger.Log.Info("user unblocked", zap.String("login", login), zap.String("by", actor))
+	return nil
+}
+
+// GrantAdmins gives the admin role to the configured logins that are already registered.
+func (a *AdminService) GrantAdmins(ctx context.Context, logins []string) error {
+	for _, login := range logins {
+		err := a.userRepo.GrantRole(ctx, login, models.RoleAdmin)
+		switch {
+		case err == nil:
+		case errors.Is(err, common.ErrUserNotFound):
+			logger.Log.Warn("admin login is not registered", zap.String("login", login))
+		default:
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"github.com/Aleksei-D/go-loyalty-system/internal/domain/memory"
+	"github.com/Aleksei-D/go-loyalty-system/internal/models"
+	"github.com/Aleksei-D/go-loyalty-system/internal/utils/common"
+	crypto2 "github.com/Aleksei-D/go-loyalty-system/internal/utils/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestAdminService(t *testing.T) {
+	ctx := context.Background()
+	storage := memory.NewStorage()
+	userRepo := memory.NewUserRepository(storage)
+	tokenRepo := memory.NewTokenRepository(storage)
+	adminService := NewAdminService(userRepo, tokenRepo)
+	tokenService := NewTokenService(tokenRepo, userRepo, crypto2.NewHMACKeySet("test"), time.Minute, time.Hour)
+
+	for _, login := range []string{"bob", "alice"} {
+		_, err := userRepo.Create(ctx, &models.User{Login: login, Password: "hash"})
+		require.NoError(t, err)
+	}
+	require.NoError(t, adminService.GrantAdmins(ctx, []string{"alice", "alice", "unknown"}))
+
+	users, err := adminService.ListUsers(ctx)
+	require.NoError(t, err)
+	require.Len(t, users, 2)
+	assert.Equal(t, "alice", users[0].Login)
+	assert.Equal(t, []models.Role{models.RoleUser, models.RoleAdmin}, users[0].Roles)
+	assert.Equal(t, []models.Role{models.RoleUser}, users[1].Roles)
+
+	_, err = adminService.GetUser(ctx, "unknown")
+	assert.ErrorIs(t, err, common.ErrUserNotFound)
+
+	pair, err := tokenService.Issue(ctx, "alice")
+	require.NoError(t, err)
+	claims, err := tokenService.Verify(ctx, pair.AccessToken)
+	require.NoError(t, err)
+	assert.True(t, claims.HasRole(string(models.RoleAdmin)))
+
+	pair, err = tokenService.Issue(ctx, "bob")
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, adminService.Block(ctx, "alice", "alice"), common.ErrSelfBlock)
+	assert.ErrorIs(t, adminService.Block(ctx, "alice", "unknown"), common.ErrUserNotFound)
+	require.NoError(t, adminService.Block(ctx, "alice", "bob"))
+
+	_, err = tokenService.Verify(ctx, pair.AccessToken)
+	assert.ErrorIs(t, err, common.ErrTokenRevoked)
+	_, err = tokenService.Issue(ctx, "bob")
+	assert.ErrorIs(t, err, common.ErrUserBlocked)
+
+	info, err := adminService.GetUser(ctx, "bob")
+	require.NoError(t, err)
+	assert.NotNil(t, info.BlockedAt)
+
+	require.NoError(t, adminService.Unblock(ctx, "alice", "bob"))
+	_, err = tokenService.Issue(ctx, "bob")
+	assert.NoError(t, err)
+}
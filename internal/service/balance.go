@@ -3,17 +3,99 @@ package service
 import (
 	"context"
 	"github.com/Aleksei-D/go-loyalty-system/internal/domain"
+	"github.com/Aleksei-D/go-loyalty-system/internal/logger"
 	"github.com/Aleksei-D/go-loyalty-system/internal/models"
+	"github.com/Aleksei-D/go-loyalty-system/internal/utils/common"
+	"go.uber.org/zap"
+	"strings"
+	"time"
 )
 
 type BalanceService struct {
 	balanceRepo domain.BalanceRepository
+	ledgerRepo  domain.LedgerRepository
+	pointsTTL   time.Duration
 }
 
-func NewBalanceService(repo domain.BalanceRepository) *BalanceService {
-	return &BalanceService{balanceRepo: repo}
+// NewBalanceService makes accrued points expire after pointsTTL; zero keeps them forever.
+func NewBalanceService(repo domain.BalanceRepository, ledgerRepo domain.LedgerRepository, pointsTTL time.Duration) *BalanceService {
+	return &BalanceService{balanceRepo: repo, ledgerRepo: ledgerRepo, pointsTTL: pointsTTL}
 }
 
 func (b *BalanceService) Get(ctx context.Context, login string) (*models.Balance, error) {
-	return b.balanceRepo.Get(ctx, login)
+	balance, err := b.balanceRepo.Get(ctx, login)
+	if err != nil || b.pointsTTL == 0 {
+		return balance, err
+	}
+
+	entries, err := b.ledgerRepo.GetEntriesByLogin(ctx, login)
+	if err != nil {
+		return nil, err
+	}
+	if expiring := models.ExpiringBreakdown(models.PointLots(entries, b.pointsTTL), time.Now()); len(expiring) > 0 {
+		balance.Expiring = expiring
+	}
+	return balance, nil
+}
+
+func (b *BalanceService) GetHistory(ctx context.Context, login string) ([]*models.LedgerEntry, error) {
+	entries, err := b.ledgerRepo.GetEntriesByLogin(ctx, login)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, common.ErrNoContent
+	}
+	return entries, nil
+}
+
+// Adjust credits a positive amount or debits a negative one; a debit may not overdraw the balance.
+func (b *BalanceService) Adjust(ctx context.Context, operator, login string, request *models.AdjustmentRequest) (*models.Adjustment, error) {
+	reason := strings.TrimSpace(request.Reason)
+	if request.Amount == 0 || reason == "" || operator == "" {
+		return nil, common.ErrInvalidAdjustment
+	}
+
+	adjustment := &models.Adjustment{
+		Login:    login,
+		Amount:   request.Amount,
+		Reason:   reason,
+		Operator: operator,
+	}
+	if err := b.ledgerRepo.Adjust(ctx, adjustment); err != nil {
+		return nil, err
+	}
+	logger.Log.Info(
+		"balance adjusted",
+		zap.String("login", login),
+		zap.String("operator", operator),
+		zap.String("amount", adjustment.Amount.String()),
+		zap.String("reason", reason),
+	)
+	return adjustment, nil
+}
+
+// ExpirePoints records an expiry posting for every user whose accrued points have outlived the ttl.
+func (b *BalanceService) ExpirePoints(ctx context.Context) (models.Money, error) {
+	if b.pointsTTL == 0 {
+		return 0, nil
+	}
+
+	logins, err := b.ledgerRepo.GetExpiryCandidates(ctx, time.Now().Add(-b.pointsTTL))
+	if err != nil {
+		return 0, err
+	}
+
+	var total models.Money
+	for _, login := range logins {
+		expired, err := b.ledgerRepo.ExpirePoints(ctx, login, b.pointsTTL)
+		if err != nil {
+			return total, err
+		}
+		if expired > 0 {
+			logger.Log.Info("points expired", zap.String("login", login), zap.String("amount", expired.String()))
+		}
+		total += expired
+	}
+	return total, nil
 }
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"github.com/Aleksei-D/go-loyalty-system/internal/domain/memory"
+	"github.com/Aleksei-D/go-loyalty-system/internal/models"
+	"github.com/Aleksei-D/go-loyalty-system/internal/utils/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestBalanceService_Adjust(t *testing.T) {
+	ctx := context.Background()
+	storage := memory.NewStorage()
+	_, err := memory.NewUserRepository(storage).Create(ctx, &models.User{Login: "alice", Password: "hash"})
+	require.NoError(t, err)
+	balanceService := NewBalanceService(memory.NewBalanceRepository(storage), memory.NewLedgerRepository(storage), 0)
+
+	tests := []struct {
+		name     string
+		operator string
+		request  models.AdjustmentRequest
+		wantErr  error
+	}{
+		{name: "zero amount", operator: "root", request: models.AdjustmentRequest{Reason: "goodwill"}, wantErr: common.ErrInvalidAdjustment},
+		{name: "blank reason", operator: "root", request: models.AdjustmentRequest{Amount: 100, Reason: "  "}, wantErr: common.ErrInvalidAdjustment},
+		{name: "no operator", request: models.AdjustmentRequest{Amount: 100, Reason: "goodwill"}, wantErr: common.ErrInvalidAdjustment},
+		{name: "overdraw", operator: "root", request: models.AdjustmentRequest{Amount: -100, Reason: "fraud"}, wantErr: common.ErrNegativeBalance},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := balanceService.Adjust(ctx, tt.operator, "alice", &tt.request)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+
+	credit, err := balanceService.Adjust(ctx, "root", "alice", &models.AdjustmentRequest{Amount: 1500, Reason: " goodwill "})
+	require.NoError(t, err)
+	assert.Equal(t, "goodwill", credit.Reason)
+	assert.Equal(t, "root", credit.Operator)
+
+	_, err = balanceService.Adjust(ctx, "root", "alice", &models.AdjustmentRequest{Amount: -1501, Reason: "fraud"})
+	assert.ErrorIs(t, err, common.ErrNegativeBalance)
+
+	debit, err := balanceService.Adjust(ctx, "root", "alice", &models.AdjustmentRequest{Amount: -500, Reason: "fraud"})
+	require.NoError(t, err)
+
+	balance, err := balanceService.Get(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, models.Money(1000), balance.Current)
+	assert.Equal(t, models.Money(0), balance.Withdrawn)
+
+	history, err := balanceService.GetHistory(ctx, "alice")
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, models.PostingKindAdjustment, history[0].Kind)
+	assert.Equal(t, debit.Reference(), history[0].Reference)
+	assert.Equal(t, models.Money(-500), history[0].Amount)
+	assert.Equal(t, "fraud", history[0].Comment)
+	assert.Equal(t, credit.Reference(), history[1].Reference)
+	assert.Equal(t, models.Money(1500), history[1].Amount)
+}
+
+func TestBalanceService_ExpirePoints(t *testing.T) {
+	const ttl = 200 * time.Millisecond
+	ctx := context.Background()
+	storage := memory.NewStorage()
+	_, err := memory.NewUserRepository(storage).Create(ctx, &models.User{Login: "alice"})
+	require.NoError(t, err)
+	orderService := NewOrderService(memory.NewOrderRepository(storage), nil, nil, nil)
+	balanceService := NewBalanceService(memory.NewBalanceRepository(storage), memory.NewLedgerRepository(storage), ttl)
+	withdrawalService := NewWithdrawalService(memory.NewWithdrawalRepository(storage), memory.NewHoldRepository(storage), time.Minute)
+
+	accrue := func(number string, amount models.Money) {
+		_, err := orderService.AddOrder(ctx, number, "alice")
+		require.NoError(t, err)
+		require.NoError(t, orderService.UpdateStatus(ctx, &models.Order{Number: number, Status: models.OrderStatusProcessed, Accrual: &amount}))
+	}
+
+	accrue("12345678903", 1000)
+	require.NoError(t, withdrawalService.Withdraw(ctx, &models.Withdrawal{Login: "alice", OrderNumber: "2377225624", Sum: 400}))
+
+	balance, err := balanceService.Get(ctx, "alice")
+	require.NoError(t, err)
+	require.Len(t, balance.Expiring, 1)
+	assert.Equal(t, models.Money(600), balance.Expiring[0].Amount)
+
+	expired, err := balanceService.ExpirePoints(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, models.Money(0), expired, "nothing expires before the ttl")
+
+	time.Sleep(ttl)
+	accrue("79927398713", 300)
+
+	expired, err = balanceService.ExpirePoints(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, models.Money(600), expired)
+	expired, err = balanceService.ExpirePoints(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, models.Money(0), expired, "expired points are only posted once")
+
+	balance, err = balanceService.Get(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, models.Money(300), balance.Current)
+	assert.Equal(t, models.Money(400), balance.Withdrawn)
+	require.Len(t, balance.Expiring, 1)
+	assert.Equal(t, models.Money(300), balance.Expiring[0].Amount)
+
+	history, err := balanceService.GetHistory(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, models.PostingKindExpiry, history[0].Kind)
+	assert.Equal(t, models.Money(-600), history[0].Amount)
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Aleksei-D/go-loyalty-system/internal/domain"
+	"github.com/Aleksei-D/go-loyalty-system/internal/models"
+	"github.com/Aleksei-D/go-loyalty-system/internal/utils/common"
+	"os"
+	"sync"
+	"time"
+)
+
+type BonusService struct {
+	ledgerRepo domain.LedgerRepository
+	orderRepo  domain.OrderRepository
+	userRepo   domain.UserRepository
+	rulesFile  string
+
+	mu    sync.RWMutex
+	rules *models.BonusRules
+}
+
+// NewBonusService loads the rules from rulesFile, which is also where rules changed through the admin API are saved.
+// Without a file the rules start empty and only live in memory.
+func NewBonusService(
+	ledgerRepo domain.LedgerRepository,
+	orderRepo domain.OrderRepository,
+	userRepo domain.UserRepository,
+	rulesFile string,
+) (*BonusService, error) {
+	rules, err := loadBonusRules(rulesFile)
+	if err != nil {
+		return nil, err
+	}
+	return &BonusService{
+		ledgerRepo: ledgerRepo,
+		orderRepo:  orderRepo,
+		userRepo:   userRepo,
+		rulesFile:  rulesFile,
+		rules:      rules,
+	}, nil
+}
+
+func loadBonusRules(path string) (*models.BonusRules, error) {
+	rules := &models.BonusRules{Rules: make([]*models.BonusRule, 0)}
+	if path == "" {
+		return rules, nil
+	}
+
+	buf, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return rules, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(buf, rules); err != nil {
+		return nil, fmt.Errorf("bonus rules %s: %w", path, err)
+	}
+	if err = rules.Validate(); err != nil {
+		return nil, fmt.Errorf("bonus rules %s: %w", path, err)
+	}
+	return rules, nil
+}
+
+func (b *BonusService) Rules() *models.BonusRules {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.rules
+}
+
+func (b *BonusService) SetRules(rules *models.BonusRules) error {
+	if rules.Rules == nil {
+		rules.Rules = make([]*models.BonusRule, 0)
+	}
+	if err := rules.Validate(); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.rulesFile != "" {
+		buf, err := json.MarshalIndent(rules, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err = os.WriteFile(b.rulesFile, buf, 0o644); err != nil {
+			return err
+		}
+	}
+	b.rules = rules
+	return nil
+}
+
+// Apply awards the bonus of a processed order; awarding the same order again is a no-op.
+func (b *BonusService) Apply(ctx context.Context, order *models.Order) (models.Money, error) {
+	rules := b.Rules()
+	if len(rules.Rules) == 0 {
+		return 0, nil
+	}
+
+	user, err := b.userRepo.GetByLogin(ctx, order.Login)
+	if err != nil {
+		return 0, err
+	}
+	processed, err := b.orderRepo.GetAllByLogin(ctx, order.Login, &models.ListFilter{
+		Limit:  2,
+		Status: string(models.OrderStatusProcessed),
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	bonusOrder := &models.BonusOrder{
+		Login:       order.Login,
+		Number:      order.Number,
+		Tier:        user.Tier,
+		FirstOrder:  len(processed) == 1 && processed[0].Number == order.Number,
+		ProcessedAt: time.Now(),
+	}
+	if order.Accrual != nil {
+		bonusOrder.Accrual = *order.Accrual
+	}
+
+	award := rules.Evaluate(bonusOrder)
+	if award == nil {
+		return 0, nil
+	}
+	err = b.ledgerRepo.AwardBonus(ctx, award, rules.DailyCap)
+	if errors.Is(err, common.ErrBonusAwarded) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return award.Amount, nil
+}
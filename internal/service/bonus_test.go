@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"github.com/Aleksei-D/go-loyalty-system/internal/domain/memory"
+	"github.com/Aleksei-D/go-loyalty-system/internal/models"
+	"github.com/Aleksei-D/go-loyalty-system/internal/utils/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBonusService_AwardedOnProcessed(t *testing.T) {
+	ctx := context.Background()
+	storage := memory.NewStorage()
+	userRepo := memory.NewUserRepository(storage)
+	orderRepo := memory.NewOrderRepository(storage)
+	ledgerRepo := memory.NewLedgerRepository(storage)
+	_, err := userRepo.Create(ctx, &models.User{Login: "alice"})
+	require.NoError(t, err)
+
+	tiers, err := models.ParseTiers("bronze:0,gold:100")
+	require.NoError(t, err)
+	tierService := NewTierService(orderRepo, userRepo, tiers, time.Hour)
+	bonusService, err := NewBonusService(ledgerRepo, orderRepo, userRepo, "")
+	require.NoError(t, err)
+	require.NoError(t, bonusService.SetRules(&models.BonusRules{DailyCap: 20000, Rules: []*models.BonusRule{
+		{Name: "gold", Tier: "gold", Percent: 50},
+		{Name: "welcome", FirstOrder: true, Amount: 5000},
+	}}))
+	orderService := NewOrderService(orderRepo, tierService, bonusService, nil)
+
+	process := func(number string, accrual models.Money) {
+		_, err := orderService.AddOrder(ctx, number, "alice")
+		require.NoError(t, err)
+		require.NoError(t, orderService.UpdateStatus(ctx, &models.Order{Number: number, Status: models.OrderStatusProcessed, Accrual: &accrual}))
+	}
+	bonuses := func() map[string]models.Money {
+		entries, err := ledgerRepo.GetEntriesByLogin(ctx, "alice")
+		require.NoError(t, err)
+		awarded := make(map[string]models.Money)
+		for _, entry := range entries {
+			if entry.Kind == models.PostingKindBonus {
+				awarded[entry.Reference] = entry.Amount
+			}
+		}
+		return awarded
+	}
+
+	process("12345678903", 5000)
+	assert.Equal(t, map[string]models.Money{"12345678903": 5000}, bonuses(), "first order in bronze gets only the welcome bonus")
+
+	accrual := models.Money(5000)
+	require.NoError(t, orderService.UpdateStatus(ctx, &models.Order{Number: "12345678903", Status: models.OrderStatusProcessed, Accrual: &accrual}))
+	assert.Len(t, bonuses(), 1, "repeated completion does not award again")
+
+	process("79927398713", 20000)
+	assert.Equal(t, models.Money(10000), bonuses()["79927398713"], "gold multiplier after reaching the tier")
+
+	process("2377225624", 20000)
+	assert.Equal(t, models.Money(5000), bonuses()["2377225624"], "trimmed to the daily cap")
+
+	balance, err := memory.NewBalanceRepository(storage).Get(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, models.Money(45000+20000), balance.Current)
+}
+
+func TestBonusService_RulesFile(t *testing.T) {
+	storage := memory.NewStorage()
+	path := filepath.Join(t.TempDir(), "bonus.json")
+	newBonusService := func() (*BonusService, error) {
+		return NewBonusService(
+			memory.NewLedgerRepository(storage),
+			memory.NewOrderRepository(storage),
+			memory.NewUserRepository(storage),
+			path,
+		)
+	}
+
+	bonusService, err := newBonusService()
+	require.NoError(t, err, "a missing file starts without rules")
+	assert.Empty(t, bonusService.Rules().Rules)
+
+	err = bonusService.SetRules(&models.BonusRules{Rules: []*models.BonusRule{{Name: "empty"}}})
+	assert.ErrorIs(t, err, common.ErrInvalidBonusRules)
+	require.NoError(t, bonusService.SetRules(&models.BonusRules{Rules: []*models.BonusRule{{Name: "welcome", FirstOrder: true, Amount: 100}}}))
+
+	reloaded, err := newBonusService()
+	require.NoError(t, err)
+	assert.Equal(t, bonusService.Rules(), reloaded.Rules())
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"rules":[{"name":"broken"}]}`), 0o644))
+	_, err = newBonusService()
+	assert.ErrorIs(t, err, common.ErrInvalidBonusRules)
+}
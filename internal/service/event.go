@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/Aleksei-D/go-loyalty-system/internal/domain"
+	"github.com/Aleksei-D/go-loyalty-system/internal/events"
+	"github.com/Aleksei-D/go-loyalty-system/internal/logger"
+	"github.com/Aleksei-D/go-loyalty-system/internal/utils/delay"
+	"go.uber.org/zap"
+	"time"
+)
+
+const (
+	eventLease        = 30 * time.Second
+	eventRetryBackoff = time.Second
+	eventRetryLimit   = 10 * time.Minute
+)
+
+type EventService struct {
+	outboxRepo domain.OutboxRepository
+	sinks      []events.Sink
+}
+
+func NewEventService(outboxRepo domain.OutboxRepository, sinks []events.Sink) *EventService {
+	return &EventService{outboxRepo: outboxRepo, sinks: sinks}
+}
+
+// Dispatch delivers a batch of due outbox events to every sink and returns how many events were claimed.
+// An event that any sink fails to take is retried later with exponential backoff.
+func (e *EventService) Dispatch(ctx context.Context, limit uint) (uint, error) {
+	claimed, err := e.outboxRepo.Claim(ctx, limit, eventLease)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, event := range claimed {
+		var deliveryErr error
+		for _, sink := range e.sinks {
+			deliveryErr = errors.Join(deliveryErr, sink.Deliver(ctx, event))
+		}
+
+		if deliveryErr == nil {
+			err = e.outboxRepo.MarkDelivered(ctx, event.ID)
+		} else {
+			logger.Log.Warn("event delivery failed", zap.Int64("id", event.ID), zap.Error(deliveryErr))
+			retryAt := time.Now().Add(delay.Backoff(event.Attempts+1, eventRetryBackoff, eventRetryLimit))
+			err = e.outboxRepo.MarkFailed(ctx, event.ID, retryAt, deliveryErr.Error())
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+	return uint(len(claimed)), nil
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/Aleksei-D/go-loyalty-system/internal/domain/memory"
+	"github.com/Aleksei-D/go-loyalty-system/internal/events"
+	"github.com/Aleksei-D/go-loyalty-system/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+type recordingSink struct {
+	events []*models.Event
+	fail   bool
+}
+
+func (r *recordingSink) Deliver(_ context.Context, event *models.Event) error {
+	if r.fail {
+		return errors.New("sink is down")
+	}
+	r.events = append(r.events, event)
+	return nil
+}
+
+func TestEventService_Dispatch(t *testing.T) {
+	ctx := context.Background()
+	storage := memory.NewStorage()
+	_, err := memory.NewUserRepository(storage).Create(ctx, &models.User{Login: "alice"})
+	require.NoError(t, err)
+
+	orderService := NewOrderService(memory.NewOrderRepository(storage), nil, nil, nil)
+	withdrawalService := NewWithdrawalService(memory.NewWithdrawalRepository(storage), memory.NewHoldRepository(storage), time.Minute)
+	_, err = orderService.AddOrder(ctx, "12345678903", "alice")
+	require.NoError(t, err)
+	accrual := models.Money(1000)
+	require.NoError(t, orderService.UpdateStatus(ctx, &models.Order{Number: "12345678903", Status: models.OrderStatusProcessed, Accrual: &accrual}))
+	require.NoError(t, orderService.UpdateStatus(ctx, &models.Order{Number: "12345678903", Status: models.OrderStatusProcessed, Accrual: &accrual}))
+	require.NoError(t, withdrawalService.Withdraw(ctx, &models.Withdrawal{Login: "alice", OrderNumber: "2377225624", Sum: 400}))
+
+	sink := &recordingSink{fail: true}
+	eventService := NewEventService(memory.NewOutboxRepository(storage), []events.Sink{sink})
+
+	claimed, err := eventService.Dispatch(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, uint(5), claimed)
+	claimed, err = eventService.Dispatch(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, uint(0), claimed, "failed events wait for their retry")
+
+	sink.fail = false
+	time.Sleep(eventRetryBackoff)
+	claimed, err = eventService.Dispatch(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, uint(5), claimed)
+
+	types := make([]models.EventType, 0)
+	for _, event := range sink.events {
+		types = append(types, event.Type)
+		assert.Equal(t, "alice", event.Login)
+	}
+	assert.Equal(t, []models.EventType{
+		models.EventOrderAccepted,
+		models.EventOrderStatusChanged,
+		models.EventOrderProcessed,
+		models.EventPointsCredited,
+		models.EventWithdrawalMade,
+	}, types, "a repeated completion publishes nothing")
+	assert.JSONEq(t, `{"order":"12345678903","amount":10}`, string(sink.events[3].Payload))
+
+	claimed, err = eventService.Dispatch(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, uint(0), claimed, "delivered events are not sent again")
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"github.com/Aleksei-D/go-loyalty-system/internal/domain/memory"
+	"github.com/Aleksei-D/go-loyalty-system/internal/models"
+	"github.com/Aleksei-D/go-loyalty-system/internal/utils/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func newTestHoldServices(t *testing.T, holdTTL time.Duration) (*BalanceService, *WithdrawalService) {
+	t.Helper()
+	ctx := context.Background()
+	storage := memory.NewStorage()
+	_, err := memory.NewUserRepository(storage).Create(ctx, &models.User{Login: "alice", Password: "hash"})
+	require.NoError(t, err)
+
+	balanceService := NewBalanceService(memory.NewBalanceRepository(storage), memory.NewLedgerRepository(storage), 0)
+	withdrawalService := NewWithdrawalService(memory.NewWithdrawalRepository(storage), memory.NewHoldRepository(storage), holdTTL)
+	_, err = balanceService.Adjust(ctx, "root", "alice", &models.AdjustmentRequest{Amount: 10000, Reason: "opening"})
+	require.NoError(t, err)
+	return balanceService, withdrawalService
+}
+
+func assertBalance(t *testing.T, balanceService *BalanceService, current, withdrawn, held models.Money) {
+	t.Helper()
+	balance, err := balanceService.Get(context.Background(), "alice")
+	require.NoError(t, err)
+	assert.Equal(t, models.Balance{Login: "alice", Current: current, Withdrawn: withdrawn, Held: held}, *balance)
+}
+
+func TestWithdrawalService_HoldCaptureRelease(t *testing.T) {
+	ctx := context.Background()
+	balanceService, withdrawalService := newTestHoldServices(t, time.Minute)
+
+	_, err := withdrawalService.Hold(ctx, "alice", &models.HoldRequest{OrderNumber: "12345678904", Sum: 100})
+	assert.ErrorIs(t, err, common.ErrInvalidOrderNumber)
+	_, err = withdrawalService.Hold(ctx, "alice", &models.HoldRequest{OrderNumber: "2377225624", Sum: 0})
+	assert.ErrorIs(t, err, common.ErrInvalidSum)
+	_, err = withdrawalService.Hold(ctx, "alice", &models.HoldRequest{OrderNumber: "2377225624", Sum: 10001})
+	assert.ErrorIs(t, err, common.ErrPaymentInsufficient)
+
+	captured, err := withdrawalService.Hold(ctx, "alice", &models.HoldRequest{OrderNumber: "2377225624", Sum: 3000})
+	require.NoError(t, err)
+	assert.Equal(t, models.HoldStatusHeld, captured.Status)
+	_, err = withdrawalService.Hold(ctx, "alice", &models.HoldRequest{OrderNumber: "2377225624", Sum: 100})
+	assert.ErrorIs(t, err, common.ErrOrderAlreadyAdded)
+
+	released, err := withdrawalService.Hold(ctx, "alice", &models.HoldRequest{OrderNumber: "12345678903", Sum: 2000})
+	require.NoError(t, err)
+	assertBalance(t, balanceService, 5000, 0, 5000)
+
+	_, err = withdrawalService.Hold(ctx, "alice", &models.HoldRequest{OrderNumber: "79927398713", Sum: 5001})
+	assert.ErrorIs(t, err, common.ErrPaymentInsufficient, "held points cannot be spent twice")
+
+	_, err = withdrawalService.CaptureHold(ctx, "bob", captured.ID)
+	assert.ErrorIs(t, err, common.ErrHoldNotFound)
+
+	hold, err := withdrawalService.CaptureHold(ctx, "alice", captured.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.HoldStatusCaptured, hold.Status)
+	_, err = withdrawalService.ReleaseHold(ctx, "alice", captured.ID)
+	assert.ErrorIs(t, err, common.ErrHoldNotActive)
+
+	hold, err = withdrawalService.ReleaseHold(ctx, "alice", released.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.HoldStatusReleased, hold.Status)
+	_, err = withdrawalService.CaptureHold(ctx, "alice", released.ID)
+	assert.ErrorIs(t, err, common.ErrHoldNotActive)
+	assertBalance(t, balanceService, 7000, 3000, 0)
+
+	withdrawals, _, err := withdrawalService.GetAllByLogin(ctx, "alice", &models.ListFilter{})
+	require.NoError(t, err)
+	require.Len(t, withdrawals, 1)
+	assert.Equal(t, "2377225624", withdrawals[0].OrderNumber)
+	assert.Equal(t, models.Money(3000), withdrawals[0].Sum)
+
+	_, err = withdrawalService.Refund(ctx, "alice", "2377225624")
+	require.NoError(t, err)
+	assertBalance(t, balanceService, 10000, 0, 0)
+
+	holds, err := withdrawalService.GetHolds(ctx, "alice")
+	require.NoError(t, err)
+	assert.Len(t, holds, 2)
+}
+
+func TestWithdrawalService_ExpireHolds(t *testing.T) {
+	ctx := context.Background()
+	balanceService, withdrawalService := newTestHoldServices(t, 0)
+
+	for _, number := range []string{"2377225624", "12345678903", "79927398713"} {
+		_, err := withdrawalService.Hold(ctx, "alice", &models.HoldRequest{OrderNumber: number, Sum: 1000})
+		require.NoError(t, err)
+	}
+	assertBalance(t, balanceService, 7000, 0, 3000)
+
+	holds, err := withdrawalService.GetHolds(ctx, "alice")
+	require.NoError(t, err)
+	_, err = withdrawalService.CaptureHold(ctx, "alice", holds[0].ID)
+	assert.ErrorIs(t, err, common.ErrHoldNotActive, "an expired hold cannot be captured before the agent releases it")
+
+	expired, err := withdrawalService.ExpireHolds(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, uint(2), expired)
+	expired, err = withdrawalService.ExpireHolds(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, uint(1), expired)
+	expired, err = withdrawalService.ExpireHolds(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, uint(0), expired)
+	assertBalance(t, balanceService, 10000, 0, 0)
+
+	holds, err = withdrawalService.GetHolds(ctx, "alice")
+	require.NoError(t, err)
+	for _, hold := range holds {
+		assert.Equal(t, models.HoldStatusExpired, hold.Status)
+	}
+}
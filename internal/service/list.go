@@ -0,0 +1,26 @@
+package service
+
+import "github.com/Aleksei-D/go-loyalty-system/internal/models"
+
+// pageFilter fetches one extra item to detect the next page; a zero limit means an unbounded list.
+func pageFilter(filter *models.ListFilter) *models.ListFilter {
+	if filter.Limit == 0 {
+		return filter
+	}
+	page := *filter
+	page.Limit = filter.Limit + 1
+	return &page
+}
+
+func nextPage[T any](items []T, limit uint, cursorOf func(T) *models.ListCursor) ([]T, string, error) {
+	if limit == 0 || uint(len(items)) <= limit {
+		return items, "", nil
+	}
+
+	items = items[:limit]
+	nextCursor, err := cursorOf(items[limit-1]).Encode()
+	if err != nil {
+		return nil, "", err
+	}
+	return items, nextCursor, nil
+}
@@ -2,29 +2,116 @@ package service
 
 import (
 	"context"
+	"fmt"
 	"github.com/Aleksei-D/go-loyalty-system/internal/domain"
+	"github.com/Aleksei-D/go-loyalty-system/internal/logger"
 	"github.com/Aleksei-D/go-loyalty-system/internal/models"
 	"github.com/Aleksei-D/go-loyalty-system/internal/utils/common"
+	"go.uber.org/zap"
 )
 
 type OrderService struct {
-	orderRepo domain.OrderRepository
+	orderRepo       domain.OrderRepository
+	tierService     *TierService
+	bonusService    *BonusService
+	referralService *ReferralService
 }
 
-func NewOrderService(repo domain.OrderRepository) *OrderService {
-	return &OrderService{orderRepo: repo}
+// NewOrderService recalculates the owner's tier, awards bonuses and pays referrals once an order is processed;
+// any of the services may be nil.
+func NewOrderService(
+	repo domain.OrderRepository,
+	tierService *TierService,
+	bonusService *BonusService,
+	referralService *ReferralService,
+) *OrderService {
+	return &OrderService{
+		orderRepo:       repo,
+		tierService:     tierService,
+		bonusService:    bonusService,
+		referralService: referralService,
+	}
+}
+
+func (o *OrderService) GetAllByLogin(ctx context.Context, login string, filter *models.ListFilter) ([]*models.Order, string, error) {
+	if filter.Status != "" && !models.OrderStatus(filter.Status).IsValid() {
+		return nil, "", fmt.Errorf("%w: unknown status %q", common.ErrInvalidListFilter, filter.Status)
+	}
+
+	orders, err := o.orderRepo.GetAllByLogin(ctx, login, pageFilter(filter))
+	if err != nil {
+		return nil, "", err
+	}
+	return nextPage(orders, filter.Limit, func(order *models.Order) *models.ListCursor {
+		return &models.ListCursor{Time: order.UploadedAt.Time, Key: order.Number}
+	})
+}
+
+func (o *OrderService) ClaimOrders(ctx context.Context, claim *models.OrderClaim) ([]*models.Order, error) {
+	return o.orderRepo.ClaimOrders(ctx, claim)
 }
 
-func (o *OrderService) GetAllByLogin(ctx context.Context, login string) ([]*models.Order, error) {
-	return o.orderRepo.GetAllByLogin(ctx, login)
+func (o *OrderService) GetParkedOrders(ctx context.Context) ([]*models.ParkedOrder, error) {
+	return o.orderRepo.GetParkedOrders(ctx)
 }
 
-func (o *OrderService) GetNotAcceptedOrderNumbers(ctx context.Context, limit, updateTimeout uint) ([]*models.Order, error) {
-	return o.orderRepo.GetNotAcceptedOrderNumbers(ctx, limit, updateTimeout)
+func (o *OrderService) UnparkOrder(ctx context.Context, orderNumber string) error {
+	return o.orderRepo.UnparkOrder(ctx, orderNumber)
 }
 
 func (o *OrderService) UpdateStatus(ctx context.Context, order *models.Order) error {
-	return o.orderRepo.UpdateStatus(ctx, order)
+	if err := o.orderRepo.UpdateStatus(ctx, order); err != nil {
+		return err
+	}
+	if order.Status != models.OrderStatusProcessed || (o.tierService == nil && o.bonusService == nil && o.referralService == nil) {
+		return nil
+	}
+
+	// The credit is already committed, so follow-up failures are logged rather than returned:
+	// the tier catches up on the next profile read.
+	processed, err := o.orderRepo.GetOrderByNumber(ctx, order.Number)
+	if err != nil {
+		logger.Log.Warn("processed order lookup failed", zap.String("order", order.Number), zap.Error(err))
+		return nil
+	}
+	if o.tierService != nil && processed.Accrual != nil {
+		if _, err = o.tierService.Recalculate(ctx, processed.Login); err != nil {
+			logger.Log.Warn("tier recalculation failed", zap.String("order", order.Number), zap.Error(err))
+		}
+	}
+	if o.bonusService != nil {
+		if _, err = o.bonusService.Apply(ctx, processed); err != nil {
+			logger.Log.Error("bonus award failed", zap.String("order", order.Number), zap.Error(err))
+		}
+	}
+	if o.referralService != nil {
+		if _, err = o.referralService.Reward(ctx, processed); err != nil {
+			logger.Log.Error("referral reward failed", zap.String("order", order.Number), zap.Error(err))
+		}
+	}
+	return nil
+}
+
+func (o *OrderService) GetOrder(ctx context.Context, login, orderNumber string) (*models.Order, error) {
+	return o.getUserOrder(ctx, login, orderNumber)
+}
+
+func (o *OrderService) GetStatusHistory(ctx context.Context, login, orderNumber string) ([]*models.OrderStatusChange, error) {
+	if _, err := o.getUserOrder(ctx, login, orderNumber); err != nil {
+		return nil, err
+	}
+	return o.orderRepo.GetStatusHistory(ctx, orderNumber)
+}
+
+func (o *OrderService) getUserOrder(ctx context.Context, login, orderNumber string) (*models.Order, error) {
+	order, err := o.orderRepo.GetOrderByNumber(ctx, orderNumber)
+	if err != nil {
+		return nil, err
+	}
+	if order.Login != login {
+		return nil, common.ErrOrderBelongAnotherUser
+	}
+	return order, nil
 }
 
 func (o *OrderService) AddOrder(ctx context.Context, orderNumber, login string) (*models.Order, error) {
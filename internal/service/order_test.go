@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"github.com/Aleksei-D/go-loyalty-system/internal/domain/memory"
+	"github.com/Aleksei-D/go-loyalty-system/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+)
+
+func TestOrderService_UpdateStatus_ConcurrentUpdaters(t *testing.T) {
+	const updaters = 32
+	ctx := context.Background()
+	storage := memory.NewStorage()
+	_, err := memory.NewUserRepository(storage).Create(ctx, &models.User{Login: "alice"})
+	require.NoError(t, err)
+
+	orderService := NewOrderService(memory.NewOrderRepository(storage), nil, nil, nil)
+	_, err = orderService.AddOrder(ctx, "12345678903", "alice")
+	require.NoError(t, err)
+
+	accrual := models.Money(50050)
+	processed := func() *models.Order {
+		return &models.Order{Number: "12345678903", Status: models.OrderStatusProcessed, Accrual: &accrual}
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, updaters)
+	start := make(chan struct{})
+	for i := 0; i < updaters; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			errs <- orderService.UpdateStatus(ctx, processed())
+		}()
+	}
+	close(start)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, orderService.UpdateStatus(ctx, processed()), "repeated completion must be a no-op")
+
+	entries, err := memory.NewLedgerRepository(storage).GetEntriesByLogin(ctx, "alice")
+	require.NoError(t, err)
+	accruals := 0
+	for _, entry := range entries {
+		if entry.Kind == models.PostingKindAccrual {
+			accruals++
+			assert.Equal(t, accrual, entry.Amount)
+		}
+	}
+	assert.Equal(t, 1, accruals)
+
+	balance, err := memory.NewBalanceRepository(storage).Get(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, accrual, balance.Current)
+
+	history, err := orderService.GetStatusHistory(ctx, "alice", "12345678903")
+	require.NoError(t, err)
+	assert.Len(t, history, 2)
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"github.com/Aleksei-D/go-loyalty-system/internal/domain"
+	"github.com/Aleksei-D/go-loyalty-system/internal/logger"
+	"github.com/Aleksei-D/go-loyalty-system/internal/models"
+	"github.com/Aleksei-D/go-loyalty-system/internal/notifier"
+	"github.com/Aleksei-D/go-loyalty-system/internal/utils/common"
+	"github.com/Aleksei-D/go-loyalty-system/internal/utils/credentials"
+	crypto2 "github.com/Aleksei-D/go-loyalty-system/internal/utils/crypto"
+	"go.uber.org/zap"
+	"time"
+)
+
+type PasswordService struct {
+	userRepo  domain.UserRepository
+	resetRepo domain.PasswordResetRepository
+	tokenRepo domain.TokenRepository
+	policy    *credentials.Policy
+	notifier  notifier.Notifier
+	resetTTL  time.Duration
+}
+
+func NewPasswordService(
+	repos *Repositories,
+	policy *credentials.Policy,
+	notifier notifier.Notifier,
+	resetTTL time.Duration,
+) *PasswordService {
+	return &PasswordService{
+		userRepo:  repos.User,
+		resetRepo: repos.PasswordReset,
+		tokenRepo: repos.Token,
+		policy:    policy,
+		notifier:  notifier,
+		resetTTL:  resetTTL,
+	}
+}
+
+func (p *PasswordService) ChangePassword(ctx context.Context, login string, request *models.PasswordChangeRequest) error {
+	user, err := p.userRepo.GetByLogin(ctx, login)
+	if err != nil {
+		logger.Log.Warn(err.Error(), zap.Error(err))
+		return err
+	}
+	if user == nil || user.Login == "" || !crypto2.CheckPasswordHash(request.OldPassword, user.Password) {
+		return common.ErrInvalidCredentials
+	}
+	return p.setPassword(ctx, login, request.NewPassword)
+}
+
+// RequestReset sends a single-use reset token; unknown logins are ignored so the
+// response does not reveal which logins exist.
+func (p *PasswordService) RequestReset(ctx context.Context, login string) error {
+	ok, err := p.userRepo.IsExist(ctx, login)
+	if err != nil {
+		logger.Log.Warn(err.Error(), zap.Error(err))
+		return err
+	}
+	if !ok {
+		logger.Log.Info("password reset requested for unknown login", zap.String("login", login))
+		return nil
+	}
+
+	resetToken, err := crypto2.NewPasswordResetToken()
+	if err != nil {
+		return err
+	}
+
+	token := &models.PasswordResetToken{
+		Hash:      crypto2.HashPasswordResetToken(resetToken),
+		Login:     login,
+		ExpiresAt: time.Now().Add(p.resetTTL),
+	}
+	if err = p.resetRepo.SaveResetToken(ctx, token); err != nil {
+		logger.Log.Warn(err.Error(), zap.Error(err))
+		return err
+	}
+	return p.notifier.SendPasswordReset(ctx, login, resetToken, token.ExpiresAt)
+}
+
+func (p *PasswordService) ConfirmReset(ctx context.Context, request *models.PasswordResetConfirmRequest) error {
+	if request.Token == "" {
+		return common.ErrInvalidResetToken
+	}
+
+	token, err := p.resetRepo.ConsumeResetToken(ctx, crypto2.HashPasswordResetToken(request.Token))
+	if err != nil {
+		return err
+	}
+	return p.setPassword(ctx, token.Login, request.NewPassword)
+}
+
+func (p *PasswordService) setPassword(ctx context.Context, login, password string) error {
+	if err := p.policy.ValidatePassword(login, password); err != nil {
+		return err
+	}
+
+	hashPassword, err := crypto2.HashPassword(password)
+	if err != nil {
+		logger.Log.Warn(err.Error(), zap.Error(err))
+		return err
+	}
+
+	if err = p.userRepo.UpdatePassword(ctx, login, hashPassword); err != nil {
+		logger.Log.Warn(err.Error(), zap.Error(err))
+		return err
+	}
+	return p.tokenRepo.RevokeUserSessions(ctx, login)
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"github.com/Aleksei-D/go-loyalty-system/internal/domain/memory"
+	"github.com/Aleksei-D/go-loyalty-system/internal/models"
+	"github.com/Aleksei-D/go-loyalty-system/internal/utils/common"
+	"github.com/Aleksei-D/go-loyalty-system/internal/utils/credentials"
+	crypto2 "github.com/Aleksei-D/go-loyalty-system/internal/utils/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+type sentReset struct {
+	login string
+	token string
+}
+
+type recordingNotifier struct {
+	resets []sentReset
+}
+
+func (r *recordingNotifier) SendPasswordReset(_ context.Context, login, token string, _ time.Time) error {
+	r.resets = append(r.resets, sentReset{login: login, token: token})
+	return nil
+}
+
+type passwordFixture struct {
+	users     *UserService
+	tokens    *TokenService
+	passwords *PasswordService
+	notifier  *recordingNotifier
+}
+
+func newPasswordFixture(t *testing.T, resetTTL time.Duration) *passwordFixture {
+	t.Helper()
+	storage := memory.NewStorage()
+	repos := &Repositories{
+		User:          memory.NewUserRepository(storage),
+		Token:         memory.NewTokenRepository(storage),
+		PasswordReset: memory.NewPasswordResetRepository(storage),
+	}
+	policy, err := credentials.NewPolicy(`^[a-z]{3,16}$`, 8)
+	require.NoError(t, err)
+
+	fixture := &passwordFixture{
+		users:    NewUserService(repos.User, policy, 5, time.Minute, nil),
+		tokens:   NewTokenService(repos.Token, repos.User, crypto2.NewHMACKeySet("test"), time.Minute, time.Hour),
+		notifier: &recordingNotifier{},
+	}
+	fixture.passwords = NewPasswordService(repos, policy, fixture.notifier, resetTTL)
+
+	_, err = fixture.users.CreateUser(context.Background(), &models.User{Login: "alice", Password: "Correct-Horse-7"})
+	require.NoError(t, err)
+	return fixture
+}
+
+func (f *passwordFixture) login(password string) error {
+	return f.users.Login(context.Background(), &models.User{Login: "alice", Password: password})
+}
+
+func TestPasswordService_ChangePassword(t *testing.T) {
+	ctx := context.Background()
+	f := newPasswordFixture(t, time.Hour)
+	session, err := f.tokens.Issue(ctx, "alice")
+	require.NoError(t, err)
+
+	err = f.passwords.ChangePassword(ctx, "alice", &models.PasswordChangeRequest{OldPassword: "wrong", NewPassword: "Battery-Staple-9"})
+	assert.ErrorIs(t, err, common.ErrInvalidCredentials)
+
+	err = f.passwords.ChangePassword(ctx, "alice", &models.PasswordChangeRequest{OldPassword: "Correct-Horse-7", NewPassword: "short"})
+	assert.ErrorIs(t, err, common.ErrWeakPassword)
+
+	_, err = f.tokens.Verify(ctx, session.AccessToken)
+	require.NoError(t, err, "failed attempts must keep sessions")
+
+	err = f.passwords.ChangePassword(ctx, "alice", &models.PasswordChangeRequest{OldPassword: "Correct-Horse-7", NewPassword: "Battery-Staple-9"})
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, f.login("Correct-Horse-7"), common.ErrInvalidCredentials)
+	assert.NoError(t, f.login("Battery-Staple-9"))
+
+	_, err = f.tokens.Verify(ctx, session.AccessToken)
+	assert.ErrorIs(t, err, common.ErrTokenRevoked)
+	_, err = f.tokens.Refresh(ctx, session.RefreshToken)
+	assert.Error(t, err)
+}
+
+func TestPasswordService_ResetFlow(t *testing.T) {
+	ctx := context.Background()
+	f := newPasswordFixture(t, time.Hour)
+	session, err := f.tokens.Issue(ctx, "alice")
+	require.NoError(t, err)
+	rotated, err := f.tokens.Refresh(ctx, session.RefreshToken)
+	require.NoError(t, err)
+
+	require.NoError(t, f.passwords.RequestReset(ctx, "nobody"))
+	assert.Empty(t, f.notifier.resets, "unknown login must not be notified")
+
+	require.NoError(t, f.passwords.RequestReset(ctx, "alice"))
+	require.NoError(t, f.passwords.RequestReset(ctx, "alice"))
+	require.Len(t, f.notifier.resets, 2)
+	superseded, latest := f.notifier.resets[0].token, f.notifier.resets[1].token
+
+	err = f.passwords.ConfirmReset(ctx, &models.PasswordResetConfirmRequest{Token: superseded, NewPassword: "Battery-Staple-9"})
+	assert.ErrorIs(t, err, common.ErrInvalidResetToken, "a newer request supersedes older tokens")
+
+	err = f.passwords.ConfirmReset(ctx, &models.PasswordResetConfirmRequest{Token: latest, NewPassword: "Battery-Staple-9"})
+	require.NoError(t, err)
+	assert.NoError(t, f.login("Battery-Staple-9"))
+
+	err = f.passwords.ConfirmReset(ctx, &models.PasswordResetConfirmRequest{Token: latest, NewPassword: "Another-Pass-11"})
+	assert.ErrorIs(t, err, common.ErrInvalidResetToken, "reset tokens are single-use")
+
+	for _, accessToken := range []string{session.AccessToken, rotated.AccessToken} {
+		_, err = f.tokens.Verify(ctx, accessToken)
+		assert.ErrorIs(t, err, common.ErrTokenRevoked)
+	}
+}
+
+func TestPasswordService_ResetTokenExpires(t *testing.T) {
+	ctx := context.Background()
+	f := newPasswordFixture(t, 20*time.Millisecond)
+	require.NoError(t, f.passwords.RequestReset(ctx, "alice"))
+	require.Len(t, f.notifier.resets, 1)
+
+	time.Sleep(50 * time.Millisecond)
+	err := f.passwords.ConfirmReset(ctx, &models.PasswordResetConfirmRequest{
+		Token:       f.notifier.resets[0].token,
+		NewPassword: "Battery-Staple-9",
+	})
+	assert.ErrorIs(t, err, common.ErrInvalidResetToken)
+	assert.NoError(t, f.login("Correct-Horse-7"))
+}
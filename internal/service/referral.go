@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/Aleksei-D/go-loyalty-system/internal/domain"
+	"github.com/Aleksei-D/go-loyalty-system/internal/logger"
+	"github.com/Aleksei-D/go-loyalty-system/internal/models"
+	"github.com/Aleksei-D/go-loyalty-system/internal/utils/common"
+	"go.uber.org/zap"
+	"time"
+)
+
+type ReferralService struct {
+	referralRepo   domain.ReferralRepository
+	userRepo       domain.UserRepository
+	referrerReward models.Money
+	refereeReward  models.Money
+}
+
+func NewReferralService(
+	referralRepo domain.ReferralRepository,
+	userRepo domain.UserRepository,
+	referrerReward models.Money,
+	refereeReward models.Money,
+) *ReferralService {
+	return &ReferralService{
+		referralRepo:   referralRepo,
+		userRepo:       userRepo,
+		referrerReward: referrerReward,
+		refereeReward:  refereeReward,
+	}
+}
+
+func (r *ReferralService) Stats(ctx context.Context, login string) (*models.ReferralStats, error) {
+	user, err := r.userRepo.GetByLogin(ctx, login)
+	if err != nil {
+		return nil, err
+	}
+	referrals, err := r.referralRepo.GetAllByReferrer(ctx, login)
+	if err != nil {
+		return nil, err
+	}
+	return models.NewReferralStats(user.ReferralCode, referrals), nil
+}
+
+// Reward pays a referral for the referee's first processed order with an accrual.
+// Orders of users who were not referred, or whose referral is already paid, are ignored.
+func (r *ReferralService) Reward(ctx context.Context, order *models.Order) (bool, error) {
+	if order.Accrual == nil || !order.Accrual.IsPositive() {
+		return false, nil
+	}
+
+	referral, err := r.referralRepo.GetByReferee(ctx, order.Login)
+	if errors.Is(err, common.ErrReferralNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if referral.RewardedAt != nil {
+		return false, nil
+	}
+
+	referrer, err := r.userRepo.GetByLogin(ctx, referral.Referrer)
+	if err != nil {
+		return false, err
+	}
+	if referrer.BlockedAt != nil {
+		logger.Log.Info("referral reward skipped for blocked referrer", zap.String("referrer", referral.Referrer))
+		return false, nil
+	}
+
+	err = r.referralRepo.Reward(ctx, &models.ReferralReward{
+		Referrer:       referral.Referrer,
+		Referee:        referral.Referee,
+		OrderNumber:    order.Number,
+		ReferrerAmount: r.referrerReward,
+		RefereeAmount:  r.refereeReward,
+		RewardedAt:     time.Now(),
+	})
+	if errors.Is(err, common.ErrReferralRewarded) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"github.com/Aleksei-D/go-loyalty-system/internal/domain/memory"
+	"github.com/Aleksei-D/go-loyalty-system/internal/models"
+	"github.com/Aleksei-D/go-loyalty-system/internal/utils/common"
+	"github.com/Aleksei-D/go-loyalty-system/internal/utils/credentials"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestReferralService_RewardOnFirstProcessedOrder(t *testing.T) {
+	ctx := context.Background()
+	storage := memory.NewStorage()
+	userRepo := memory.NewUserRepository(storage)
+	orderRepo := memory.NewOrderRepository(storage)
+	policy, err := credentials.NewPolicy(`^[a-z]{3,16}$`, 8)
+	require.NoError(t, err)
+	userService := NewUserService(userRepo, policy, 3, time.Minute, nil)
+	referralService := NewReferralService(memory.NewReferralRepository(storage), userRepo, 10000, 5000)
+	orderService := NewOrderService(orderRepo, nil, nil, referralService)
+
+	register := func(login, inviteCode string) error {
+		_, err := userService.CreateUser(ctx, &models.User{Login: login, Password: "Correct-Horse-7", InviteCode: inviteCode})
+		return err
+	}
+	require.NoError(t, register("alice", ""))
+	stats, err := referralService.Stats(ctx, "alice")
+	require.NoError(t, err)
+	require.NotEmpty(t, stats.Code)
+
+	assert.ErrorIs(t, register("bob", "UNKNOWN"), common.ErrInvalidReferralCode)
+	require.NoError(t, register("bob", stats.Code))
+
+	process := func(number string, accrual *models.Money) {
+		_, err := orderService.AddOrder(ctx, number, "bob")
+		require.NoError(t, err)
+		require.NoError(t, orderService.UpdateStatus(ctx, &models.Order{Number: number, Status: models.OrderStatusProcessed, Accrual: accrual}))
+	}
+	balance := func(login string) models.Money {
+		balance, err := memory.NewBalanceRepository(storage).Get(ctx, login)
+		require.NoError(t, err)
+		return balance.Current
+	}
+
+	process("12345678903", nil)
+	assert.Equal(t, models.Money(0), balance("alice"), "orders without accrual are not rewarded")
+
+	accrual := models.Money(2000)
+	process("79927398713", &accrual)
+	process("2377225624", &accrual)
+	assert.Equal(t, models.Money(10000), balance("alice"), "the referrer is paid once")
+	assert.Equal(t, models.Money(5000+2*2000), balance("bob"))
+
+	stats, err = referralService.Stats(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, 1, stats.Invited)
+	assert.Equal(t, 1, stats.Rewarded)
+	assert.Equal(t, models.Money(10000), stats.Earned)
+	require.Len(t, stats.Referrals, 1)
+	assert.Equal(t, "bob", stats.Referrals[0].Referee)
+	assert.Equal(t, "79927398713", stats.Referrals[0].OrderNumber)
+}
+
+func TestReferralService_BlockedReferrer(t *testing.T) {
+	ctx := context.Background()
+	storage := memory.NewStorage()
+	userRepo := memory.NewUserRepository(storage)
+	referralRepo := memory.NewReferralRepository(storage)
+	_, err := userRepo.Create(ctx, &models.User{Login: "alice", ReferralCode: "ALICE"})
+	require.NoError(t, err)
+	_, err = userRepo.Create(ctx, &models.User{Login: "bob", ReferredBy: "alice"})
+	require.NoError(t, err)
+	require.NoError(t, userRepo.SetBlocked(ctx, "alice", true))
+
+	referralService := NewReferralService(referralRepo, userRepo, 100, 100)
+	accrual := models.Money(100)
+	rewarded, err := referralService.Reward(ctx, &models.Order{Login: "bob", Number: "12345678903", Accrual: &accrual})
+	require.NoError(t, err)
+	assert.False(t, rewarded)
+
+	referral, err := referralRepo.GetByReferee(ctx, "bob")
+	require.NoError(t, err)
+	assert.Nil(t, referral.RewardedAt)
+}
@@ -1,19 +1,170 @@
 package service
 
-import "github.com/Aleksei-D/go-loyalty-system/internal/domain"
+import (
+	"context"
+	"fmt"
+	"github.com/Aleksei-D/go-loyalty-system/internal/config"
+	"github.com/Aleksei-D/go-loyalty-system/internal/domain"
+	"github.com/Aleksei-D/go-loyalty-system/internal/events"
+	"github.com/Aleksei-D/go-loyalty-system/internal/models"
+	"github.com/Aleksei-D/go-loyalty-system/internal/notifier"
+	"github.com/Aleksei-D/go-loyalty-system/internal/utils/credentials"
+	crypto2 "github.com/Aleksei-D/go-loyalty-system/internal/utils/crypto"
+	"strings"
+	"time"
+)
+
+type Repositories struct {
+	Balance       domain.BalanceRepository
+	Order         domain.OrderRepository
+	User          domain.UserRepository
+	Withdrawal    domain.WithdrawalRepository
+	Ledger        domain.LedgerRepository
+	Token         domain.TokenRepository
+	PasswordReset domain.PasswordResetRepository
+	Hold          domain.HoldRepository
+	Referral      domain.ReferralRepository
+	Outbox        domain.OutboxRepository
+	Webhook       domain.WebhookRepository
+}
 
 type Service struct {
 	BalanceService    *BalanceService
 	UserService       *UserService
 	OrderService      *OrderService
 	WithdrawalService *WithdrawalService
+	TokenService      *TokenService
+	PasswordService   *PasswordService
+	AdminService      *AdminService
+	TierService       *TierService
+	BonusService      *BonusService
+	ReferralService   *ReferralService
+	EventService      *EventService
+	WebhookService    *WebhookService
+	AccrualService    *AccrualCallbackService
 }
 
-func NewService(balanceRepo domain.BalanceRepository, orderRepo domain.OrderRepository, userRepo domain.UserRepository, withdrawalRepo domain.WithdrawalRepository) *Service {
+func NewService(repos *Repositories, cfg *config.Config) (*Service, error) {
+	keySet, err := newKeySet(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	policy, err := credentials.NewPolicy(*cfg.LoginPattern, *cfg.PasswordMinLength)
+	if err != nil {
+		return nil, err
+	}
+
+	tiers, err := models.ParseTiers(*cfg.Tiers)
+	if err != nil {
+		return nil, err
+	}
+	tierService := NewTierService(
+		repos.Order,
+		repos.User,
+		tiers,
+		time.Duration(*cfg.TierWindowDays)*24*time.Hour,
+	)
+
+	bonusService, err := NewBonusService(repos.Ledger, repos.Order, repos.User, *cfg.BonusRulesFile)
+	if err != nil {
+		return nil, err
+	}
+
+	referrerReward, err := models.ParseMoney(*cfg.ReferrerReward)
+	if err != nil {
+		return nil, fmt.Errorf("referrer reward: %w", err)
+	}
+	refereeReward, err := models.ParseMoney(*cfg.RefereeReward)
+	if err != nil {
+		return nil, fmt.Errorf("referee reward: %w", err)
+	}
+	referralService := NewReferralService(repos.Referral, repos.User, referrerReward, refereeReward)
+	orderService := NewOrderService(repos.Order, tierService, bonusService, referralService)
+
+	sinks, err := events.ParseSinks(*cfg.EventSinks)
+	if err != nil {
+		return nil, err
+	}
+	webhookService := NewWebhookService(repos.Webhook, nil, *cfg.WebhookMaxAttempts)
+	sinks = append(sinks, webhookService)
+
+	adminLogins := splitList(*cfg.AdminLogins)
+	adminService := NewAdminService(repos.User, repos.Token)
+	if err = adminService.GrantAdmins(context.Background(), adminLogins); err != nil {
+		return nil, err
+	}
+
 	return &Service{
-		BalanceService:    NewBalanceService(balanceRepo),
-		UserService:       NewUserService(userRepo),
-		OrderService:      NewOrderService(orderRepo),
-		WithdrawalService: NewWithdrawalService(withdrawalRepo),
+		BalanceService: NewBalanceService(
+			repos.Balance,
+			repos.Ledger,
+			time.Duration(*cfg.PointsExpiryDays)*24*time.Hour,
+		),
+		UserService: NewUserService(
+			repos.User,
+			policy,
+			*cfg.MaxFailedLogins,
+			time.Duration(*cfg.LockoutDuration)*time.Second,
+			adminLogins,
+		),
+		OrderService: orderService,
+		WithdrawalService: NewWithdrawalService(
+			repos.Withdrawal,
+			repos.Hold,
+			time.Duration(*cfg.HoldTTL)*time.Second,
+		),
+		TokenService: NewTokenService(
+			repos.Token,
+			repos.User,
+			keySet,
+			time.Duration(*cfg.AccessTokenTTL)*time.Second,
+			time.Duration(*cfg.RefreshTokenTTL)*time.Second,
+		),
+		PasswordService: NewPasswordService(
+			repos,
+			policy,
+			newNotifier(cfg),
+			time.Duration(*cfg.PasswordResetTTL)*time.Second,
+		),
+		AdminService:    adminService,
+		TierService:     tierService,
+		BonusService:    bonusService,
+		ReferralService: referralService,
+		EventService:    NewEventService(repos.Outbox, sinks),
+		WebhookService:  webhookService,
+		AccrualService:  NewAccrualCallbackService(orderService, *cfg.AccrualCallbackKey),
+	}, nil
+}
+
+func newNotifier(cfg *config.Config) notifier.Notifier {
+	if *cfg.NotifierFile == "" {
+		return notifier.NewLogNotifier()
+	}
+	return notifier.NewFileNotifier(*cfg.NotifierFile)
+}
+
+func newKeySet(cfg *config.Config) (*crypto2.KeySet, error) {
+	if *cfg.JWTSigningKey == "" {
+		return crypto2.NewHMACKeySet(*cfg.SecretKey), nil
+	}
+
+	keySet, err := crypto2.LoadKeySet(*cfg.JWTSigningKey, splitList(*cfg.JWTVerificationKeys))
+	if err != nil {
+		return nil, err
+	}
+	if *cfg.JWTAcceptHMAC {
+		keySet.AcceptHMAC(*cfg.SecretKey)
+	}
+	return keySet, nil
+}
+
+func splitList(value string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
 	}
+	return items
 }
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"github.com/Aleksei-D/go-loyalty-system/internal/domain"
+	"github.com/Aleksei-D/go-loyalty-system/internal/models"
+	"time"
+)
+
+type TierService struct {
+	orderRepo domain.OrderRepository
+	userRepo  domain.UserRepository
+	tiers     models.Tiers
+	window    time.Duration
+}
+
+// NewTierService ranks users by the accruals of orders processed within the last window.
+func NewTierService(orderRepo domain.OrderRepository, userRepo domain.UserRepository, tiers models.Tiers, window time.Duration) *TierService {
+	return &TierService{orderRepo: orderRepo, userRepo: userRepo, tiers: tiers, window: window}
+}
+
+// Recalculate stores the tier the user has reached and returns it with the accrued total it is based on.
+func (t *TierService) Recalculate(ctx context.Context, login string) (*models.Profile, error) {
+	user, err := t.userRepo.GetByLogin(ctx, login)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := t.orderRepo.GetAccruedTotal(ctx, login, time.Now().Add(-t.window))
+	if err != nil {
+		return nil, err
+	}
+
+	profile := &models.Profile{Login: user.Login, Roles: user.Roles, AccruedTotal: total}
+	current, next := t.tiers.For(total)
+	if current != nil {
+		profile.Tier = current.Name
+	}
+	if next != nil {
+		profile.NextTier = &models.NextTier{Name: next.Name, Remaining: next.Threshold - total}
+	}
+
+	if profile.Tier != user.Tier {
+		if err = t.userRepo.SetTier(ctx, login, profile.Tier); err != nil {
+			return nil, err
+		}
+	}
+	return profile, nil
+}
+
+// Profile recalculates on read as well, so a tier drops once old accruals leave the window.
+func (t *TierService) Profile(ctx context.Context, login string) (*models.Profile, error) {
+	return t.Recalculate(ctx, login)
+}
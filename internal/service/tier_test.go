@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"github.com/Aleksei-D/go-loyalty-system/internal/domain/memory"
+	"github.com/Aleksei-D/go-loyalty-system/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestTierService_RecalculatedOnCredit(t *testing.T) {
+	ctx := context.Background()
+	storage := memory.NewStorage()
+	userRepo := memory.NewUserRepository(storage)
+	orderRepo := memory.NewOrderRepository(storage)
+	_, err := userRepo.Create(ctx, &models.User{Login: "alice"})
+	require.NoError(t, err)
+
+	tiers, err := models.ParseTiers("bronze:0,silver:1000,gold:5000")
+	require.NoError(t, err)
+	tierService := NewTierService(orderRepo, userRepo, tiers, 24*time.Hour)
+	orderService := NewOrderService(orderRepo, tierService, nil, nil)
+
+	profile, err := tierService.Profile(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, "bronze", profile.Tier)
+	assert.Equal(t, &models.NextTier{Name: "silver", Remaining: 100000}, profile.NextTier)
+
+	accrual := models.Money(120000)
+	_, err = orderService.AddOrder(ctx, "12345678903", "alice")
+	require.NoError(t, err)
+	require.NoError(t, orderService.UpdateStatus(ctx, &models.Order{Number: "12345678903", Status: models.OrderStatusProcessed, Accrual: &accrual}))
+
+	user, err := userRepo.GetByLogin(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, "silver", user.Tier, "tier is stored when the order is credited")
+
+	profile, err = tierService.Profile(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, accrual, profile.AccruedTotal)
+	assert.Equal(t, &models.NextTier{Name: "gold", Remaining: 380000}, profile.NextTier)
+
+	windowed := NewTierService(orderRepo, userRepo, tiers, -time.Hour)
+	profile, err = windowed.Profile(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, "bronze", profile.Tier, "accruals outside the window do not count")
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/Aleksei-D/go-loyalty-system/internal/domain"
+	"github.com/Aleksei-D/go-loyalty-system/internal/logger"
+	"github.com/Aleksei-D/go-loyalty-system/internal/models"
+	"github.com/Aleksei-D/go-loyalty-system/internal/utils/common"
+	crypto2 "github.com/Aleksei-D/go-loyalty-system/internal/utils/crypto"
+	"go.uber.org/zap"
+	"time"
+)
+
+type TokenService struct {
+	tokenRepo  domain.TokenRepository
+	userRepo   domain.UserRepository
+	keySet     *crypto2.KeySet
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+}
+
+func NewTokenService(
+	tokenRepo domain.TokenRepository,
+	userRepo domain.UserRepository,
+	keySet *crypto2.KeySet,
+	accessTTL, refreshTTL time.Duration,
+) *TokenService {
+	return &TokenService{
+		tokenRepo:  tokenRepo,
+		userRepo:   userRepo,
+		keySet:     keySet,
+		accessTTL:  accessTTL,
+		refreshTTL: refreshTTL,
+	}
+}
+
+func (t *TokenService) Issue(ctx context.Context, login string) (*models.TokenPair, error) {
+	user, err := t.activeUser(ctx, login)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, newToken, err := t.newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	newToken.Login = login
+	if err = t.tokenRepo.SaveRefreshToken(ctx, newToken); err != nil {
+		logger.Log.Warn(err.Error(), zap.Error(err))
+		return nil, err
+	}
+	return t.tokenPair(newToken, refreshToken, user.Roles)
+}
+
+func (t *TokenService) Refresh(ctx context.Context, refreshToken string) (*models.TokenPair, error) {
+	if refreshToken == "" {
+		return nil, common.ErrInvalidRefreshToken
+	}
+
+	nextRefreshToken, newToken, err := t.newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	newToken, err = t.tokenRepo.RotateRefreshToken(ctx, crypto2.HashRefreshToken(refreshToken), newToken)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := t.activeUser(ctx, newToken.Login)
+	if err != nil {
+		return nil, err
+	}
+	return t.tokenPair(newToken, nextRefreshToken, user.Roles)
+}
+
+func (t *TokenService) Logout(ctx context.Context, claims *crypto2.Claims) error {
+	return t.tokenRepo.RevokeSession(ctx, claims.ID, claims.ExpiresAt.Time)
+}
+
+func (t *TokenService) Verify(ctx context.Context, tokenString string) (*crypto2.Claims, error) {
+	claims, err := crypto2.ParseToken(tokenString, t.keySet)
+	if err != nil {
+		return nil, err
+	}
+
+	revoked, err := t.tokenRepo.IsRevoked(ctx, claims.ID)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, common.ErrTokenRevoked
+	}
+	return claims, nil
+}
+
+func (t *TokenService) JWKS() *crypto2.JWKS {
+	return t.keySet.JWKS()
+}
+
+func (t *TokenService) newRefreshToken() (string, *models.RefreshToken, error) {
+	refreshToken, err := crypto2.NewRefreshToken()
+	if err != nil {
+		return "", nil, err
+	}
+
+	accessID, err := crypto2.NewTokenID()
+	if err != nil {
+		return "", nil, err
+	}
+
+	return refreshToken, &models.RefreshToken{
+		Hash:      crypto2.HashRefreshToken(refreshToken),
+		AccessID:  accessID,
+		ExpiresAt: time.Now().Add(t.refreshTTL),
+	}, nil
+}
+
+// activeUser loads the user whose roles go into the access token and refuses blocked ones.
+func (t *TokenService) activeUser(ctx context.Context, login string) (*models.User, error) {
+	exists, err := t.userRepo.IsExist(ctx, login)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, common.ErrUserNotFound
+	}
+
+	user, err := t.userRepo.GetByLogin(ctx, login)
+	if err != nil {
+		return nil, err
+	}
+	if user.BlockedAt != nil {
+		return nil, common.ErrUserBlocked
+	}
+	return user, nil
+}
+
+func (t *TokenService) tokenPair(token *models.RefreshToken, refreshToken string, roles []models.Role) (*models.TokenPair, error) {
+	accessToken, err := crypto2.CreateToken(token.Login, token.AccessID, models.RolesToStrings(roles), t.keySet, t.accessTTL)
+	if err != nil {
+		logger.Log.Warn(err.Error(), zap.Error(err))
+		return nil, fmt.Errorf("cannot sign access token: %w", err)
+	}
+
+	return &models.TokenPair{
+		AccessToken:  accessToken.Token,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(t.accessTTL.Seconds()),
+	}, nil
+}
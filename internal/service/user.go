@@ -2,20 +2,40 @@ package service
 
 import (
 	"context"
+	"errors"
 	"github.com/Aleksei-D/go-loyalty-system/internal/domain"
 	"github.com/Aleksei-D/go-loyalty-system/internal/logger"
 	"github.com/Aleksei-D/go-loyalty-system/internal/models"
 	"github.com/Aleksei-D/go-loyalty-system/internal/utils/common"
+	"github.com/Aleksei-D/go-loyalty-system/internal/utils/credentials"
 	crypto2 "github.com/Aleksei-D/go-loyalty-system/internal/utils/crypto"
 	"go.uber.org/zap"
+	"slices"
+	"time"
 )
 
 type UserService struct {
-	userRepo domain.UserRepository
+	userRepo        domain.UserRepository
+	policy          *credentials.Policy
+	maxFailedLogins uint
+	lockoutDuration time.Duration
+	adminLogins     []string
 }
 
-func NewUserService(userRepository domain.UserRepository) *UserService {
-	return &UserService{userRepository}
+func NewUserService(
+	userRepository domain.UserRepository,
+	policy *credentials.Policy,
+	maxFailedLogins uint,
+	lockoutDuration time.Duration,
+	adminLogins []string,
+) *UserService {
+	return &UserService{
+		userRepo:        userRepository,
+		policy:          policy,
+		maxFailedLogins: maxFailedLogins,
+		lockoutDuration: lockoutDuration,
+		adminLogins:     adminLogins,
+	}
 }
 
 func (u *UserService) Login(ctx context.Context, user *models.User) error {
@@ -24,14 +44,50 @@ func (u *UserService) Login(ctx context.Context, user *models.User) error {
 		logger.Log.Warn(err.Error(), zap.Error(err))
 		return err
 	}
-	if existUser == nil || !crypto2.CheckPasswordHash(user.Password, existUser.Password) {
+	if existUser == nil || existUser.Login == "" {
+		return common.ErrInvalidCredentials
+	}
+
+	if existUser.LockedUntil != nil && existUser.LockedUntil.After(time.Now()) {
+		return &common.AccountLockedError{Until: *existUser.LockedUntil}
+	}
+
+	if !crypto2.CheckPasswordHash(user.Password, existUser.Password) {
+		if u.maxFailedLogins == 0 {
+			return common.ErrInvalidCredentials
+		}
+
+		lockedUntil, err := u.userRepo.RegisterFailedLogin(ctx, user.Login, u.maxFailedLogins, u.lockoutDuration)
+		if err != nil {
+			logger.Log.Warn(err.Error(), zap.Error(err))
+			return err
+		}
+		if lockedUntil != nil && lockedUntil.After(time.Now()) {
+			logger.Log.Info("account locked", zap.String("login", user.Login), zap.Time("until", *lockedUntil))
+			return &common.AccountLockedError{Until: *lockedUntil}
+		}
 		return common.ErrInvalidCredentials
 	}
 
+	if err = u.userRepo.ResetFailedLogins(ctx, user.Login); err != nil {
+		logger.Log.Warn(err.Error(), zap.Error(err))
+		return err
+	}
+
+	if existUser.BlockedAt != nil {
+		return common.ErrUserBlocked
+	}
 	return nil
 }
 
 func (u *UserService) CreateUser(ctx context.Context, newUser *models.User) (*models.User, error) {
+	if err := u.policy.ValidateLogin(newUser.Login); err != nil {
+		return nil, err
+	}
+	if err := u.policy.ValidatePassword(newUser.Login, newUser.Password); err != nil {
+		return nil, err
+	}
+
 	ok, err := u.userRepo.IsExist(ctx, newUser.Login)
 	if err != nil {
 		logger.Log.Warn(err.Error(), zap.Error(err))
@@ -42,6 +98,24 @@ func (u *UserService) CreateUser(ctx context.Context, newUser *models.User) (*mo
 		return nil, common.ErrUserAlreadyExists
 	}
 
+	if newUser.InviteCode != "" {
+		referrer, err := u.userRepo.GetByReferralCode(ctx, newUser.InviteCode)
+		if errors.Is(err, common.ErrUserNotFound) || (err == nil && (referrer.Login == newUser.Login || referrer.BlockedAt != nil)) {
+			return nil, common.ErrInvalidReferralCode
+		}
+		if err != nil {
+			logger.Log.Warn(err.Error(), zap.Error(err))
+			return nil, err
+		}
+		newUser.ReferredBy = referrer.Login
+	}
+
+	referralCode, err := crypto2.NewReferralCode()
+	if err != nil {
+		return nil, err
+	}
+	newUser.ReferralCode = referralCode
+
 	hashPassword, err := crypto2.HashPassword(newUser.Password)
 	if err != nil {
 		logger.Log.Warn(err.Error(), zap.Error(err))
@@ -49,6 +123,10 @@ func (u *UserService) CreateUser(ctx context.Context, newUser *models.User) (*mo
 	}
 
 	newUser.Password = hashPassword
+	newUser.Roles = []models.Role{models.RoleUser}
+	if slices.Contains(u.adminLogins, newUser.Login) {
+		newUser.Roles = append(newUser.Roles, models.RoleAdmin)
+	}
 	user, err := u.userRepo.Create(ctx, newUser)
 	if err != nil {
 		logger.Log.Warn("User Create Error", zap.Error(err))
@@ -56,13 +134,3 @@ func (u *UserService) CreateUser(ctx context.Context, newUser *models.User) (*mo
 	}
 	return user, nil
 }
-
-func (u *UserService) GetToken(login, secretKey string) (string, error) {
-	var tokenString string
-	tokenString, err := crypto2.CreateToken(login, secretKey)
-	if err != nil {
-		logger.Log.Warn(err.Error(), zap.Error(err))
-		return tokenString, err
-	}
-	return tokenString, nil
-}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/Aleksei-D/go-loyalty-system/internal/domain/memory"
+	"github.com/Aleksei-D/go-loyalty-system/internal/models"
+	"github.com/Aleksei-D/go-loyalty-system/internal/utils/common"
+	"github.com/Aleksei-D/go-loyalty-system/internal/utils/credentials"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func newTestUserService(t *testing.T, maxFailedLogins uint, lockout time.Duration) *UserService {
+	t.Helper()
+	policy, err := credentials.NewPolicy(`^[a-z]{3,16}$`, 8)
+	require.NoError(t, err)
+	return NewUserService(memory.NewUserRepository(memory.NewStorage()), policy, maxFailedLogins, lockout, nil)
+}
+
+func TestUserService_CreateUser_Validation(t *testing.T) {
+	ctx := context.Background()
+	userService := newTestUserService(t, 3, time.Minute)
+
+	_, err := userService.CreateUser(ctx, &models.User{Login: "", Password: "Correct-Horse-7"})
+	assert.ErrorIs(t, err, common.ErrInvalidLogin)
+
+	_, err = userService.CreateUser(ctx, &models.User{Login: "alice", Password: "12345678"})
+	assert.ErrorIs(t, err, common.ErrWeakPassword)
+
+	_, err = userService.CreateUser(ctx, &models.User{Login: "alice", Password: "Correct-Horse-7"})
+	require.NoError(t, err)
+
+	_, err = userService.CreateUser(ctx, &models.User{Login: "alice", Password: "Correct-Horse-7"})
+	assert.ErrorIs(t, err, common.ErrUserAlreadyExists)
+}
+
+func TestUserService_Login_Lockout(t *testing.T) {
+	ctx := context.Background()
+	userService := newTestUserService(t, 3, time.Minute)
+	_, err := userService.CreateUser(ctx, &models.User{Login: "alice", Password: "Correct-Horse-7"})
+	require.NoError(t, err)
+
+	wrong := func() error {
+		return userService.Login(ctx, &models.User{Login: "alice", Password: "wrong-password"})
+	}
+	right := func() error {
+		return userService.Login(ctx, &models.User{Login: "alice", Password: "Correct-Horse-7"})
+	}
+
+	assert.ErrorIs(t, wrong(), common.ErrInvalidCredentials)
+	assert.ErrorIs(t, wrong(), common.ErrInvalidCredentials)
+	require.NoError(t, right(), "successful login resets the counter")
+
+	assert.ErrorIs(t, wrong(), common.ErrInvalidCredentials)
+	assert.ErrorIs(t, wrong(), common.ErrInvalidCredentials)
+	err = wrong()
+	require.ErrorIs(t, err, common.ErrAccountLocked)
+
+	var lockedErr *common.AccountLockedError
+	require.True(t, errors.As(err, &lockedErr))
+	assert.WithinDuration(t, time.Now().Add(time.Minute), lockedErr.Until, 5*time.Second)
+
+	assert.ErrorIs(t, right(), common.ErrAccountLocked, "correct password is refused while locked")
+	assert.ErrorIs(t, userService.Login(ctx, &models.User{Login: "bob", Password: "Correct-Horse-7"}), common.ErrInvalidCredentials)
+}
+
+func TestUserService_Login_LockoutExpires(t *testing.T) {
+	ctx := context.Background()
+	userService := newTestUserService(t, 1, 50*time.Millisecond)
+	_, err := userService.CreateUser(ctx, &models.User{Login: "alice", Password: "Correct-Horse-7"})
+	require.NoError(t, err)
+
+	err = userService.Login(ctx, &models.User{Login: "alice", Password: "wrong-password"})
+	require.ErrorIs(t, err, common.ErrAccountLocked)
+
+	time.Sleep(100 * time.Millisecond)
+	assert.NoError(t, userService.Login(ctx, &models.User{Login: "alice", Password: "Correct-Horse-7"}))
+}
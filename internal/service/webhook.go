@@ -0,0 +1,197 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/Aleksei-D/go-loyalty-system/internal/domain"
+	"github.com/Aleksei-D/go-loyalty-system/internal/logger"
+	"github.com/Aleksei-D/go-loyalty-system/internal/models"
+	"github.com/Aleksei-D/go-loyalty-system/internal/utils/common"
+	crypto2 "github.com/Aleksei-D/go-loyalty-system/internal/utils/crypto"
+	"github.com/Aleksei-D/go-loyalty-system/internal/utils/delay"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	webhookLease        = time.Minute
+	webhookTimeout      = 10 * time.Second
+	webhookRetryBackoff = 5 * time.Second
+	webhookRetryLimit   = time.Hour
+	webhookErrorLimit   = 512
+)
+
+type WebhookService struct {
+	webhookRepo domain.WebhookRepository
+	client      *http.Client
+	maxAttempts uint
+}
+
+func NewWebhookService(webhookRepo domain.WebhookRepository, client *http.Client, maxAttempts uint) *WebhookService {
+	if client == nil {
+		client = &http.Client{Timeout: webhookTimeout}
+	}
+	return &WebhookService{webhookRepo: webhookRepo, client: client, maxAttempts: max(maxAttempts, 1)}
+}
+
+// Create stores a subscription and generates its secret when none is given;
+// the secret is returned only here.
+func (ws *WebhookService) Create(ctx context.Context, subscription *models.WebhookSubscription) error {
+	var err error
+	if subscription.Secret == "" {
+		if subscription.Secret, err = crypto2.NewWebhookSecret(); err != nil {
+			return err
+		}
+	}
+	if err = subscription.Validate(); err != nil {
+		return err
+	}
+	if subscription.ID, err = crypto2.NewTokenID(); err != nil {
+		return err
+	}
+	if subscription.EventTypes == nil {
+		subscription.EventTypes = []models.EventType{}
+	}
+	return ws.webhookRepo.CreateSubscription(ctx, subscription)
+}
+
+func (ws *WebhookService) List(ctx context.Context) ([]*models.WebhookSubscription, error) {
+	subscriptions, err := ws.webhookRepo.GetSubscriptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, subscription := range subscriptions {
+		subscription.Secret = ""
+	}
+	return subscriptions, nil
+}
+
+func (ws *WebhookService) Delete(ctx context.Context, id string) error {
+	return ws.webhookRepo.DeleteSubscription(ctx, id)
+}
+
+func (ws *WebhookService) Deliveries(
+	ctx context.Context,
+	subscriptionID string,
+	status models.WebhookDeliveryStatus,
+) ([]*models.WebhookDelivery, error) {
+	if status != "" && !status.IsValid() {
+		return nil, fmt.Errorf("%w: unknown delivery status %q", common.ErrInvalidWebhook, status)
+	}
+	if err := ws.subscriptionExists(ctx, subscriptionID); err != nil {
+		return nil, err
+	}
+	return ws.webhookRepo.GetDeliveries(ctx, subscriptionID, status)
+}
+
+// Retry moves a dead delivery back to the queue with a fresh set of attempts.
+func (ws *WebhookService) Retry(ctx context.Context, subscriptionID string, deliveryID int64) error {
+	return ws.webhookRepo.Requeue(ctx, subscriptionID, deliveryID)
+}
+
+// Deliver implements events.Sink: the event is queued for every matching subscription
+// and sent by DeliverPending, so a slow subscriber never holds back the outbox.
+func (ws *WebhookService) Deliver(ctx context.Context, event *models.Event) error {
+	subscriptions, err := ws.webhookRepo.GetSubscriptions(ctx)
+	if err != nil {
+		return err
+	}
+
+	deliveries := make([]*models.WebhookDelivery, 0)
+	for _, subscription := range subscriptions {
+		if !subscription.Accepts(event.Type) {
+			continue
+		}
+		delivery, err := models.NewWebhookDelivery(subscription.ID, event)
+		if err != nil {
+			return err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return ws.webhookRepo.Enqueue(ctx, deliveries)
+}
+
+// DeliverPending sends a batch of due deliveries and returns how many were claimed.
+func (ws *WebhookService) DeliverPending(ctx context.Context, limit uint) (uint, error) {
+	deliveries, err := ws.webhookRepo.ClaimDeliveries(ctx, limit, webhookLease)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, delivery := range deliveries {
+		ws.attempt(ctx, delivery)
+		if err = ws.webhookRepo.SaveAttempt(ctx, delivery); err != nil {
+			return 0, err
+		}
+	}
+	return uint(len(deliveries)), nil
+}
+
+func (ws *WebhookService) attempt(ctx context.Context, delivery *models.WebhookDelivery) {
+	delivery.Attempts++
+	statusCode, err := ws.post(ctx, delivery)
+	delivery.LastStatusCode = statusCode
+	if err == nil {
+		now := models.CustomTime{Time: time.Now()}
+		delivery.Status = models.WebhookDeliveryDelivered
+		delivery.DeliveredAt = &now
+		delivery.LastError = ""
+		return
+	}
+
+	delivery.LastError = err.Error()
+	if len(delivery.LastError) > webhookErrorLimit {
+		delivery.LastError = delivery.LastError[:webhookErrorLimit]
+	}
+	if delivery.Attempts >= ws.maxAttempts {
+		logger.Log.Warn("webhook delivery is dead", zap.Int64("id", delivery.ID), zap.Error(err))
+		delivery.Status = models.WebhookDeliveryDead
+		return
+	}
+	retryAt := models.CustomTime{Time: time.Now().Add(delay.Backoff(delivery.Attempts, webhookRetryBackoff, webhookRetryLimit))}
+	delivery.NextAttemptAt = &retryAt
+}
+
+func (ws *WebhookService) post(ctx context.Context, delivery *models.WebhookDelivery) (int, error) {
+	timestamp := time.Now().Unix()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Webhook-Event", string(delivery.EventType))
+	req.Header.Set("X-Webhook-Delivery", strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set("X-Webhook-Signature", models.SignatureHeader(delivery.Secret, timestamp, delivery.Payload))
+
+	resp, err := ws.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, webhookErrorLimit))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("webhook %s answered %s", delivery.URL, resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+func (ws *WebhookService) subscriptionExists(ctx context.Context, id string) error {
+	subscriptions, err := ws.webhookRepo.GetSubscriptions(ctx)
+	if err != nil {
+		return err
+	}
+	for _, subscription := range subscriptions {
+		if subscription.ID == id {
+			return nil
+		}
+	}
+	return common.ErrWebhookNotFound
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/Aleksei-D/go-loyalty-system/internal/domain/memory"
+	"github.com/Aleksei-D/go-loyalty-system/internal/models"
+	"github.com/Aleksei-D/go-loyalty-system/internal/utils/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+type webhookReceiver struct {
+	mu       sync.Mutex
+	status   int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (wr *webhookReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	wr.mu.Lock()
+	defer wr.mu.Unlock()
+	wr.requests = append(wr.requests, r)
+	wr.bodies = append(wr.bodies, body)
+	w.WriteHeader(wr.status)
+}
+
+func TestWebhookService_DeliverPending(t *testing.T) {
+	ctx := context.Background()
+	receiver := &webhookReceiver{status: http.StatusOK}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	webhookService := NewWebhookService(memory.NewWebhookRepository(memory.NewStorage()), server.Client(), 3)
+	subscription := &models.WebhookSubscription{URL: server.URL, EventTypes: []models.EventType{models.EventOrderProcessed}}
+	require.NoError(t, webhookService.Create(ctx, subscription))
+	require.NotEmpty(t, subscription.ID)
+	require.NotEmpty(t, subscription.Secret, "a secret is generated when none is given")
+
+	processed, err := models.NewEvent(models.EventOrderProcessed, "alice", "12345678903", models.OrderAcceptedPayload{Order: "12345678903"})
+	require.NoError(t, err)
+	processed.ID = 7
+	accepted, err := models.NewEvent(models.EventOrderAccepted, "alice", "12345678903", models.OrderAcceptedPayload{Order: "12345678903"})
+	require.NoError(t, err)
+	accepted.ID = 8
+	require.NoError(t, webhookService.Deliver(ctx, processed))
+	require.NoError(t, webhookService.Deliver(ctx, processed), "a redelivered event is queued once")
+	require.NoError(t, webhookService.Deliver(ctx, accepted))
+
+	claimed, err := webhookService.DeliverPending(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, uint(1), claimed)
+
+	require.Len(t, receiver.requests, 1)
+	req := receiver.requests[0]
+	assert.Equal(t, string(models.EventOrderProcessed), req.Header.Get("X-Webhook-Event"))
+	var timestamp int64
+	var signature string
+	_, err = fmt.Sscanf(req.Header.Get("X-Webhook-Signature"), "t=%d,v1=%s", &timestamp, &signature)
+	require.NoError(t, err)
+	assert.Equal(t, models.SignWebhook(subscription.Secret, timestamp, receiver.bodies[0]), signature)
+
+	deliveries, err := webhookService.Deliveries(ctx, subscription.ID, models.WebhookDeliveryDelivered)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, uint(1), deliveries[0].Attempts)
+	assert.Equal(t, http.StatusOK, deliveries[0].LastStatusCode)
+	assert.NotNil(t, deliveries[0].DeliveredAt)
+
+	subscriptions, err := webhookService.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, subscriptions, 1)
+	assert.Empty(t, subscriptions[0].Secret)
+}
+
+func TestWebhookService_DeadLetter(t *testing.T) {
+	ctx := context.Background()
+	receiver := &webhookReceiver{status: http.StatusInternalServerError}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	webhookService := NewWebhookService(memory.NewWebhookRepository(memory.NewStorage()), server.Client(), 2)
+	subscription := &models.WebhookSubscription{URL: server.URL, Secret: "0123456789abcdef"}
+	require.NoError(t, webhookService.Create(ctx, subscription))
+
+	event, err := models.NewEvent(models.EventWithdrawalMade, "alice", "2377225624", nil)
+	require.NoError(t, err)
+	event.ID = 1
+	require.NoError(t, webhookService.Deliver(ctx, event))
+
+	claimed, err := webhookService.DeliverPending(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, uint(1), claimed)
+	pending, err := webhookService.Deliveries(ctx, subscription.ID, models.WebhookDeliveryPending)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, http.StatusInternalServerError, pending[0].LastStatusCode)
+	assert.True(t, pending[0].NextAttemptAt.After(time.Now()), "the retry is backed off")
+
+	claimed, err = webhookService.DeliverPending(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, uint(0), claimed)
+
+	// Make the retry due instead of waiting for the backoff.
+	pending[0].NextAttemptAt = &models.CustomTime{Time: time.Now()}
+	require.NoError(t, webhookService.webhookRepo.SaveAttempt(ctx, pending[0]))
+	claimed, err = webhookService.DeliverPending(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, uint(1), claimed)
+
+	dead, err := webhookService.Deliveries(ctx, subscription.ID, models.WebhookDeliveryDead)
+	require.NoError(t, err)
+	require.Len(t, dead, 1)
+	assert.Equal(t, uint(2), dead[0].Attempts)
+	assert.Len(t, receiver.requests, 2)
+
+	assert.ErrorIs(t, webhookService.Retry(ctx, subscription.ID, dead[0].ID+1), common.ErrWebhookDeliveryNotFound)
+	require.NoError(t, webhookService.Retry(ctx, subscription.ID, dead[0].ID))
+	receiver.status = http.StatusNoContent
+	claimed, err = webhookService.DeliverPending(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, uint(1), claimed)
+
+	delivered, err := webhookService.Deliveries(ctx, subscription.ID, models.WebhookDeliveryDelivered)
+	require.NoError(t, err)
+	require.Len(t, delivered, 1)
+	assert.Equal(t, uint(1), delivered[0].Attempts)
+
+	_, err = webhookService.Deliveries(ctx, subscription.ID, "LOST")
+	assert.ErrorIs(t, err, common.ErrInvalidWebhook)
+	require.NoError(t, webhookService.Delete(ctx, subscription.ID))
+	_, err = webhookService.Deliveries(ctx, subscription.ID, "")
+	assert.ErrorIs(t, err, common.ErrWebhookNotFound)
+}
@@ -2,35 +2,51 @@ package service
 
 import (
 	"context"
+	"fmt"
 	"github.com/Aleksei-D/go-loyalty-system/internal/domain"
+	"github.com/Aleksei-D/go-loyalty-system/internal/logger"
 	"github.com/Aleksei-D/go-loyalty-system/internal/models"
 	"github.com/Aleksei-D/go-loyalty-system/internal/utils/common"
+	crypto2 "github.com/Aleksei-D/go-loyalty-system/internal/utils/crypto"
+	"go.uber.org/zap"
+	"time"
 )
 
 type WithdrawalService struct {
 	withdrawalRepo domain.WithdrawalRepository
+	holdRepo       domain.HoldRepository
+	holdTTL        time.Duration
 }
 
-func NewWithdrawalService(repository domain.WithdrawalRepository) *WithdrawalService {
-	return &WithdrawalService{repository}
+func NewWithdrawalService(repository domain.WithdrawalRepository, holdRepo domain.HoldRepository, holdTTL time.Duration) *WithdrawalService {
+	return &WithdrawalService{withdrawalRepo: repository, holdRepo: holdRepo, holdTTL: holdTTL}
 }
 
-func (w *WithdrawalService) GetAllByLogin(ctx context.Context, login string) ([]*models.Withdrawal, error) {
-	withdrawals, err := w.withdrawalRepo.GetAllByLogin(ctx, login)
+func (w *WithdrawalService) GetAllByLogin(ctx context.Context, login string, filter *models.ListFilter) ([]*models.Withdrawal, string, error) {
+	if filter.Status != "" && !models.WithdrawalStatus(filter.Status).IsValid() {
+		return nil, "", fmt.Errorf("%w: unknown status %q", common.ErrInvalidListFilter, filter.Status)
+	}
+
+	withdrawals, err := w.withdrawalRepo.GetAllByLogin(ctx, login, pageFilter(filter))
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	if len(withdrawals) == 0 {
-		return nil, common.ErrNoContent
+		return nil, "", common.ErrNoContent
 	}
 
-	return w.withdrawalRepo.GetAllByLogin(ctx, login)
+	return nextPage(withdrawals, filter.Limit, func(withdrawal *models.Withdrawal) *models.ListCursor {
+		return &models.ListCursor{Time: withdrawal.ProcessedAt.Time, Key: withdrawal.OrderNumber}
+	})
 }
 
 func (w *WithdrawalService) Withdraw(ctx context.Context, withdrawal *models.Withdrawal) error {
 	if ok := common.CheckLuhnAlgorithm(withdrawal.OrderNumber); !ok {
 		return common.ErrInvalidOrderNumber
 	}
+	if !withdrawal.Sum.IsPositive() {
+		return common.ErrInvalidSum
+	}
 	ok, err := w.withdrawalRepo.IsExist(ctx, withdrawal)
 	if err != nil {
 		return err
@@ -41,3 +57,63 @@ func (w *WithdrawalService) Withdraw(ctx context.Context, withdrawal *models.Wit
 
 	return w.withdrawalRepo.Withdraw(ctx, withdrawal)
 }
+
+// Refund returns the sum of a completed withdrawal to the balance; a withdrawal is refunded at most once.
+func (w *WithdrawalService) Refund(ctx context.Context, login, orderNumber string) (*models.Withdrawal, error) {
+	withdrawal, err := w.withdrawalRepo.Refund(ctx, login, orderNumber)
+	if err != nil {
+		return nil, err
+	}
+	logger.Log.Info("withdrawal refunded", zap.String("login", login), zap.String("order", orderNumber))
+	return withdrawal, nil
+}
+
+// Hold reserves points for an order; the shop captures the hold once the order is confirmed.
+func (w *WithdrawalService) Hold(ctx context.Context, login string, request *models.HoldRequest) (*models.Hold, error) {
+	if ok := common.CheckLuhnAlgorithm(request.OrderNumber); !ok {
+		return nil, common.ErrInvalidOrderNumber
+	}
+	if !request.Sum.IsPositive() {
+		return nil, common.ErrInvalidSum
+	}
+
+	id, err := crypto2.NewTokenID()
+	if err != nil {
+		return nil, err
+	}
+
+	hold := &models.Hold{
+		ID:          id,
+		Login:       login,
+		OrderNumber: request.OrderNumber,
+		Sum:         request.Sum,
+		ExpiresAt:   models.CustomTime{Time: time.Now().Add(w.holdTTL)},
+	}
+	if err = w.holdRepo.Create(ctx, hold); err != nil {
+		return nil, err
+	}
+	return hold, nil
+}
+
+func (w *WithdrawalService) GetHolds(ctx context.Context, login string) ([]*models.Hold, error) {
+	holds, err := w.holdRepo.GetAllByLogin(ctx, login)
+	if err != nil {
+		return nil, err
+	}
+	if len(holds) == 0 {
+		return nil, common.ErrNoContent
+	}
+	return holds, nil
+}
+
+func (w *WithdrawalService) CaptureHold(ctx context.Context, login, id string) (*models.Hold, error) {
+	return w.holdRepo.Capture(ctx, login, id)
+}
+
+func (w *WithdrawalService) ReleaseHold(ctx context.Context, login, id string) (*models.Hold, error) {
+	return w.holdRepo.Release(ctx, login, id)
+}
+
+func (w *WithdrawalService) ExpireHolds(ctx context.Context, limit uint) (uint, error) {
+	return w.holdRepo.ExpireHolds(ctx, limit)
+}
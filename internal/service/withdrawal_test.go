@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"github.com/Aleksei-D/go-loyalty-system/internal/domain/memory"
+	"github.com/Aleksei-D/go-loyalty-system/internal/models"
+	"github.com/Aleksei-D/go-loyalty-system/internal/utils/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestWithdrawalService_Refund(t *testing.T) {
+	ctx := context.Background()
+	storage := memory.NewStorage()
+	userRepo := memory.NewUserRepository(storage)
+	for _, login := range []string{"alice", "bob"} {
+		_, err := userRepo.Create(ctx, &models.User{Login: login, Password: "hash"})
+		require.NoError(t, err)
+	}
+	balanceService := NewBalanceService(memory.NewBalanceRepository(storage), memory.NewLedgerRepository(storage), 0)
+	withdrawalService := NewWithdrawalService(memory.NewWithdrawalRepository(storage), memory.NewHoldRepository(storage), time.Minute)
+
+	_, err := balanceService.Adjust(ctx, "root", "alice", &models.AdjustmentRequest{Amount: 10000, Reason: "opening"})
+	require.NoError(t, err)
+	require.NoError(t, withdrawalService.Withdraw(ctx, &models.Withdrawal{Login: "alice", OrderNumber: "2377225624", Sum: 3000}))
+	require.NoError(t, withdrawalService.Withdraw(ctx, &models.Withdrawal{Login: "alice", OrderNumber: "12345678903", Sum: 2000}))
+
+	_, err = withdrawalService.Refund(ctx, "bob", "2377225624")
+	assert.ErrorIs(t, err, common.ErrWithdrawalNotFound)
+	_, err = withdrawalService.Refund(ctx, "alice", "79927398713")
+	assert.ErrorIs(t, err, common.ErrWithdrawalNotFound)
+
+	refunded, err := withdrawalService.Refund(ctx, "alice", "2377225624")
+	require.NoError(t, err)
+	assert.Equal(t, models.WithdrawalStatusRefunded, refunded.Status)
+	assert.NotNil(t, refunded.RefundedAt)
+
+	_, err = withdrawalService.Refund(ctx, "alice", "2377225624")
+	assert.ErrorIs(t, err, common.ErrWithdrawalRefunded)
+
+	balance, err := balanceService.Get(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, models.Money(8000), balance.Current)
+	assert.Equal(t, models.Money(2000), balance.Withdrawn)
+
+	history, err := balanceService.GetHistory(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, models.PostingKindReversal, history[0].Kind)
+	assert.Equal(t, models.Money(3000), history[0].Amount)
+
+	tests := []struct {
+		status string
+		want   []string
+	}{
+		{status: "", want: []string{"12345678903", "2377225624"}},
+		{status: string(models.WithdrawalStatusCompleted), want: []string{"12345678903"}},
+		{status: string(models.WithdrawalStatusRefunded), want: []string{"2377225624"}},
+	}
+	for _, tt := range tests {
+		t.Run("status "+tt.status, func(t *testing.T) {
+			withdrawals, _, err := withdrawalService.GetAllByLogin(ctx, "alice", &models.ListFilter{Status: tt.status})
+			require.NoError(t, err)
+			numbers := make([]string, 0, len(withdrawals))
+			for _, withdrawal := range withdrawals {
+				numbers = append(numbers, withdrawal.OrderNumber)
+			}
+			assert.ElementsMatch(t, tt.want, numbers)
+		})
+	}
+
+	_, _, err = withdrawalService.GetAllByLogin(ctx, "alice", &models.ListFilter{Status: "PENDING"})
+	assert.ErrorIs(t, err, common.ErrInvalidListFilter)
+}
@@ -1,13 +1,62 @@
 package common
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"time"
+)
 
 var (
-	ErrInvalidOrderNumber     = errors.New("invalid order number")
-	ErrOrderAlreadyAdded      = errors.New("order already added by user")
-	ErrOrderBelongAnotherUser = errors.New("order belong another user")
-	ErrUserAlreadyExists      = errors.New("user already exists")
-	ErrInvalidCredentials     = errors.New("invalid credentials")
-	ErrPaymentInsufficient    = errors.New("payment insufficient")
-	ErrNoContent              = errors.New("no content")
+	ErrInvalidOrderNumber      = errors.New("invalid order number")
+	ErrOrderAlreadyAdded       = errors.New("order already added by user")
+	ErrOrderBelongAnotherUser  = errors.New("order belong another user")
+	ErrUserAlreadyExists       = errors.New("user already exists")
+	ErrInvalidCredentials      = errors.New("invalid credentials")
+	ErrPaymentInsufficient     = errors.New("payment insufficient")
+	ErrNoContent               = errors.New("no content")
+	ErrInvalidSum              = errors.New("invalid sum")
+	ErrOrderNotFound           = errors.New("order not found")
+	ErrIllegalStatusTransition = errors.New("illegal order status transition")
+	ErrUnknownAccrualStatus    = errors.New("unknown accrual status")
+	ErrInvalidListFilter       = errors.New("invalid list filter")
+	ErrInvalidRefreshToken     = errors.New("invalid refresh token")
+	ErrRefreshTokenReused      = errors.New("refresh token reused")
+	ErrTokenRevoked            = errors.New("token revoked")
+	ErrInvalidLogin            = errors.New("invalid login")
+	ErrWeakPassword            = errors.New("weak password")
+	ErrAccountLocked           = errors.New("account locked")
+	ErrInvalidResetToken       = errors.New("invalid password reset token")
+	ErrUserNotFound            = errors.New("user not found")
+	ErrUserBlocked             = errors.New("user blocked")
+	ErrSelfBlock               = errors.New("cannot block yourself")
+	ErrInvalidAdjustment       = errors.New("invalid balance adjustment")
+	ErrNegativeBalance         = errors.New("balance cannot become negative")
+	ErrWithdrawalNotFound      = errors.New("withdrawal not found")
+	ErrWithdrawalRefunded      = errors.New("withdrawal already refunded")
+	ErrHoldNotFound            = errors.New("hold not found")
+	ErrHoldNotActive           = errors.New("hold is not active")
+	ErrInvalidBonusRules       = errors.New("invalid bonus rules")
+	ErrBonusAwarded            = errors.New("bonus already awarded")
+	ErrInvalidReferralCode     = errors.New("invalid referral code")
+	ErrReferralNotFound        = errors.New("referral not found")
+	ErrReferralRewarded        = errors.New("referral already rewarded")
+	ErrInvalidWebhook          = errors.New("invalid webhook subscription")
+	ErrWebhookNotFound         = errors.New("webhook subscription not found")
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+	ErrInvalidSignature        = errors.New("invalid signature")
+	ErrCallbackDisabled        = errors.New("accrual callbacks are disabled")
+	ErrInvalidCallback         = errors.New("invalid accrual callback")
+	ErrOrderNotParked          = errors.New("order is not parked")
 )
+
+type AccountLockedError struct {
+	Until time.Time
+}
+
+func (e *AccountLockedError) Error() string {
+	return fmt.Sprintf("%s until %s", ErrAccountLocked, e.Until.Format(time.RFC3339))
+}
+
+func (e *AccountLockedError) Is(target error) bool {
+	return target == ErrAccountLocked
+}
@@ -0,0 +1,64 @@
+package credentials
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"github.com/Aleksei-D/go-loyalty-system/internal/utils/common"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// bcrypt ignores everything after the first 72 bytes.
+const passwordMaxLength = 72
+
+//go:embed common_passwords.txt
+var commonPasswordsList string
+
+var commonPasswords = loadCommonPasswords()
+
+type Policy struct {
+	loginPattern      *regexp.Regexp
+	passwordMinLength int
+}
+
+func NewPolicy(loginPattern string, passwordMinLength uint) (*Policy, error) {
+	pattern, err := regexp.Compile(loginPattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid login pattern: %w", err)
+	}
+	return &Policy{loginPattern: pattern, passwordMinLength: int(passwordMinLength)}, nil
+}
+
+func (p *Policy) ValidateLogin(login string) error {
+	if !p.loginPattern.MatchString(login) {
+		return fmt.Errorf("%w: must match %s", common.ErrInvalidLogin, p.loginPattern)
+	}
+	return nil
+}
+
+func (p *Policy) ValidatePassword(login, password string) error {
+	switch {
+	case utf8.RuneCountInString(password) < p.passwordMinLength:
+		return fmt.Errorf("%w: must be at least %d characters", common.ErrWeakPassword, p.passwordMinLength)
+	case len(password) > passwordMaxLength:
+		return fmt.Errorf("%w: must be at most %d bytes", common.ErrWeakPassword, passwordMaxLength)
+	case strings.EqualFold(password, login):
+		return fmt.Errorf("%w: must differ from login", common.ErrWeakPassword)
+	case commonPasswords[strings.ToLower(password)]:
+		return fmt.Errorf("%w: password is too common", common.ErrWeakPassword)
+	}
+	return nil
+}
+
+func loadCommonPasswords() map[string]bool {
+	passwords := make(map[string]bool)
+	scanner := bufio.NewScanner(strings.NewReader(commonPasswordsList))
+	for scanner.Scan() {
+		if password := strings.TrimSpace(scanner.Text()); password != "" {
+			passwords[strings.ToLower(password)] = true
+		}
+	}
+	return passwords
+}
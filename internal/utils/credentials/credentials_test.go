@@ -0,0 +1,56 @@
+package credentials
+
+import (
+	"github.com/Aleksei-D/go-loyalty-system/internal/utils/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+func TestPolicy_ValidateLogin(t *testing.T) {
+	policy, err := NewPolicy(`^[A-Za-z0-9._@-]{3,64}$`, 8)
+	require.NoError(t, err)
+
+	for _, login := range []string{"alice", "bob.smith", "user@example.com", "a_b-c"} {
+		assert.NoError(t, policy.ValidateLogin(login), login)
+	}
+	for _, login := range []string{"", "ab", "with space", "юзер", strings.Repeat("a", 65)} {
+		assert.ErrorIs(t, policy.ValidateLogin(login), common.ErrInvalidLogin, login)
+	}
+}
+
+func TestPolicy_ValidatePassword(t *testing.T) {
+	policy, err := NewPolicy(`^.+$`, 8)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		password string
+		wantErr  bool
+	}{
+		{name: "strong", password: "Correct-Horse-7"},
+		{name: "multibyte characters are counted as runes", password: "пароль-кот"},
+		{name: "empty", password: "", wantErr: true},
+		{name: "too short", password: "Sh0rt!", wantErr: true},
+		{name: "longer than bcrypt input", password: strings.Repeat("x", 73), wantErr: true},
+		{name: "equals login", password: "Alice-Wonder", wantErr: true},
+		{name: "common password", password: "password", wantErr: true},
+		{name: "common password in another case", password: "QWERTY123", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.ValidatePassword("alice-wonder", tt.password)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, common.ErrWeakPassword)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestNewPolicy_InvalidPattern(t *testing.T) {
+	_, err := NewPolicy(`^[a-z`, 8)
+	assert.Error(t, err)
+}
@@ -1,17 +1,112 @@
 package crypto
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
 	"github.com/golang-jwt/jwt/v5"
 	"time"
 )
 
-func CreateToken(login, secretKey string) (string, error) {
-	signingKey := []byte(secretKey)
+const (
+	tokenIDSize      = 16
+	refreshTokenSize = 32
+	referralCodeSize = 5
+)
+
+type Claims struct {
+	Login string   `json:"login"`
+	Roles []string `json:"roles,omitempty"`
+	jwt.RegisteredClaims
+}
+
+func (c *Claims) HasRole(role string) bool {
+	for _, claimRole := range c.Roles {
+		if claimRole == role {
+			return true
+		}
+	}
+	return false
+}
+
+type AccessToken struct {
+	Token     string
+	ID        string
+	ExpiresAt time.Time
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"login": login,
-		"exp":   time.Now().Add(time.Hour * 1).Unix(),
+func CreateToken(login, id string, roles []string, keySet *KeySet, ttl time.Duration) (*AccessToken, error) {
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+	tokenString, err := keySet.sign(&Claims{
+		Login: login,
+		Roles: roles,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        id,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
 	})
+	if err != nil {
+		return nil, err
+	}
+	return &AccessToken{Token: tokenString, ID: id, ExpiresAt: expiresAt}, nil
+}
+
+func ParseToken(tokenString string, keySet *KeySet) (*Claims, error) {
+	var claims Claims
+	token, err := jwt.ParseWithClaims(tokenString, &claims, keySet.keyFunc, jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, err
+	}
+	// The token id keys revocation, so a token without one could never be logged out.
+	if !token.Valid || claims.Login == "" || claims.ID == "" {
+		return nil, fmt.Errorf("invalid token claims")
+	}
+	return &claims, nil
+}
+
+func NewTokenID() (string, error) {
+	return randomString(tokenIDSize)
+}
+
+func NewRefreshToken() (string, error) {
+	return randomString(refreshTokenSize)
+}
+
+func HashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func NewPasswordResetToken() (string, error) {
+	return randomString(refreshTokenSize)
+}
+
+func HashPasswordResetToken(token string) string {
+	return HashRefreshToken(token)
+}
+
+func NewWebhookSecret() (string, error) {
+	return randomString(refreshTokenSize)
+}
+
+// NewReferralCode returns a short code that is easy to type; uniqueness is enforced by the storage.
+func NewReferralCode() (string, error) {
+	buf := make([]byte, referralCodeSize)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(buf), nil
+}
 
-	return token.SignedString(signingKey)
+func randomString(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
 }
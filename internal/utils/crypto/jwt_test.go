@@ -0,0 +1,83 @@
+package crypto
+
+import (
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestParseToken(t *testing.T) {
+	keySet := NewHMACKeySet("secretKey")
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		claims  Claims
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			claims: Claims{Login: "alice", RegisteredClaims: jwt.RegisteredClaims{
+				ID: "token-id", ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute)),
+			}},
+		},
+		{
+			name: "expired",
+			claims: Claims{Login: "alice", RegisteredClaims: jwt.RegisteredClaims{
+				ID: "token-id", ExpiresAt: jwt.NewNumericDate(now.Add(-time.Minute)),
+			}},
+			wantErr: true,
+		},
+		{
+			name:    "without expiration",
+			claims:  Claims{Login: "alice", RegisteredClaims: jwt.RegisteredClaims{ID: "token-id"}},
+			wantErr: true,
+		},
+		{
+			name: "without token id",
+			claims: Claims{Login: "alice", RegisteredClaims: jwt.RegisteredClaims{
+				ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute)),
+			}},
+			wantErr: true,
+		},
+		{
+			name: "without login",
+			claims: Claims{RegisteredClaims: jwt.RegisteredClaims{
+				ID: "token-id", ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute)),
+			}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokenString, err := keySet.sign(&tt.claims)
+			require.NoError(t, err)
+
+			claims, err := ParseToken(tokenString, keySet)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.claims.Login, claims.Login)
+			assert.Equal(t, tt.claims.ID, claims.ID)
+		})
+	}
+}
+
+func TestCreateToken(t *testing.T) {
+	keySet := NewHMACKeySet("secretKey")
+	accessToken, err := CreateToken("alice", "token-id", nil, keySet, time.Minute)
+	require.NoError(t, err)
+
+	claims, err := ParseToken(accessToken.Token, keySet)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", claims.Login)
+	assert.Equal(t, "token-id", claims.ID)
+	assert.WithinDuration(t, accessToken.ExpiresAt, claims.ExpiresAt.Time, time.Second)
+
+	_, err = ParseToken(accessToken.Token, NewHMACKeySet("anotherKey"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,164 @@
+package crypto
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"os"
+	"sort"
+)
+
+type verificationKey struct {
+	method    jwt.SigningMethod
+	publicKey crypto.PublicKey
+	jwk       *JWK
+}
+
+type KeySet struct {
+	signingMethod    jwt.SigningMethod
+	signingKey       interface{}
+	signingKID       string
+	hmacKey          []byte
+	verificationKeys map[string]*verificationKey
+}
+
+func NewHMACKeySet(secretKey string) *KeySet {
+	return &KeySet{
+		signingMethod:    jwt.SigningMethodHS256,
+		signingKey:       []byte(secretKey),
+		hmacKey:          []byte(secretKey),
+		verificationKeys: make(map[string]*verificationKey),
+	}
+}
+
+// AcceptHMAC keeps verifying HS256 tokens without kid, so tokens issued before
+// switching to an asymmetric signing key stay valid until they expire.
+func (k *KeySet) AcceptHMAC(secretKey string) {
+	k.hmacKey = []byte(secretKey)
+}
+
+// LoadKeySet signs with the private key from signingKeyFile and also accepts tokens signed by any of verificationKeyFiles.
+func LoadKeySet(signingKeyFile string, verificationKeyFiles []string) (*KeySet, error) {
+	privateKey, err := loadPEMKey(signingKeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	signer, ok := privateKey.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("%s: signing key must be a private key", signingKeyFile)
+	}
+
+	keySet := &KeySet{
+		signingKey:       privateKey,
+		verificationKeys: make(map[string]*verificationKey),
+	}
+	signingVerificationKey, err := keySet.addVerificationKey(signer.Public())
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", signingKeyFile, err)
+	}
+	keySet.signingMethod = signingVerificationKey.method
+	keySet.signingKID = signingVerificationKey.jwk.KeyID
+
+	for _, file := range verificationKeyFiles {
+		key, err := loadPEMKey(file)
+		if err != nil {
+			return nil, err
+		}
+		if signer, ok := key.(crypto.Signer); ok {
+			key = signer.Public()
+		}
+		if _, err = keySet.addVerificationKey(key); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+	}
+	return keySet, nil
+}
+
+func (k *KeySet) addVerificationKey(publicKey crypto.PublicKey) (*verificationKey, error) {
+	var method jwt.SigningMethod
+	switch publicKey.(type) {
+	case *rsa.PublicKey:
+		method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", publicKey)
+	}
+
+	jwk, err := newJWK(publicKey, method.Alg())
+	if err != nil {
+		return nil, err
+	}
+
+	key := &verificationKey{method: method, publicKey: publicKey, jwk: jwk}
+	k.verificationKeys[jwk.KeyID] = key
+	return key, nil
+}
+
+func (k *KeySet) sign(claims *Claims) (string, error) {
+	token := jwt.NewWithClaims(k.signingMethod, claims)
+	if k.signingKID != "" {
+		token.Header["kid"] = k.signingKID
+	}
+	return token.SignedString(k.signingKey)
+}
+
+func (k *KeySet) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		if token.Method != jwt.SigningMethodHS256 || k.hmacKey == nil {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return k.hmacKey, nil
+	}
+
+	key, ok := k.verificationKeys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id: %s", kid)
+	}
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	return key.publicKey, nil
+}
+
+func (k *KeySet) JWKS() *JWKS {
+	jwks := &JWKS{Keys: make([]*JWK, 0, len(k.verificationKeys))}
+	for _, key := range k.verificationKeys {
+		jwks.Keys = append(jwks.Keys, key.jwk)
+	}
+	sort.Slice(jwks.Keys, func(i, j int) bool {
+		return jwks.Keys[i].KeyID < jwks.Keys[j].KeyID
+	})
+	return jwks
+}
+
+func loadPEMKey(file string) (interface{}, error) {
+	buf, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(buf)
+	if block == nil {
+		return nil, fmt.Errorf("%s: no PEM data found", file)
+	}
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		return x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	case "PUBLIC KEY":
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("%s: unsupported PEM block %q", file, block.Type)
+	}
+}
@@ -0,0 +1,209 @@
+package crypto
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writePKCS8Key(t *testing.T, privateKey crypto.Signer) string {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	require.NoError(t, err)
+
+	file := filepath.Join(t.TempDir(), "key.pem")
+	require.NoError(t, os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600))
+	return file
+}
+
+func writePublicKey(t *testing.T, publicKey crypto.PublicKey) string {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	require.NoError(t, err)
+
+	file := filepath.Join(t.TempDir(), "key.pub.pem")
+	require.NoError(t, os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600))
+	return file
+}
+
+func newRSAKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	return key
+}
+
+func newEd25519Key(t *testing.T) ed25519.PrivateKey {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return key
+}
+
+func TestLoadKeySet_RoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     crypto.Signer
+		wantAlg string
+	}{
+		{name: "RS256", key: newRSAKey(t), wantAlg: "RS256"},
+		{name: "EdDSA", key: newEd25519Key(t), wantAlg: "EdDSA"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keySet, err := LoadKeySet(writePKCS8Key(t, tt.key), nil)
+			require.NoError(t, err)
+
+			accessToken, err := CreateToken("alice", "token-id", nil, keySet, time.Minute)
+			require.NoError(t, err)
+
+			claims, err := ParseToken(accessToken.Token, keySet)
+			require.NoError(t, err)
+			assert.Equal(t, "alice", claims.Login)
+
+			token, _, err := jwt.NewParser().ParseUnverified(accessToken.Token, &Claims{})
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantAlg, token.Header["alg"])
+			assert.Equal(t, keySet.JWKS().Keys[0].KeyID, token.Header["kid"])
+		})
+	}
+}
+
+func TestLoadKeySet_Rotation(t *testing.T) {
+	oldKey := newRSAKey(t)
+	oldKeySet, err := LoadKeySet(writePKCS8Key(t, oldKey), nil)
+	require.NoError(t, err)
+	oldToken, err := CreateToken("alice", "old-token", nil, oldKeySet, time.Minute)
+	require.NoError(t, err)
+
+	hmacToken, err := CreateToken("alice", "hmac-token", nil, NewHMACKeySet("secret"), time.Minute)
+	require.NoError(t, err)
+
+	newKeySet, err := LoadKeySet(writePKCS8Key(t, newEd25519Key(t)), []string{writePublicKey(t, oldKey.Public())})
+	require.NoError(t, err)
+	assert.Len(t, newKeySet.JWKS().Keys, 2)
+
+	_, err = ParseToken(oldToken.Token, newKeySet)
+	assert.NoError(t, err, "token signed by a verification key must be accepted")
+
+	_, err = ParseToken(hmacToken.Token, newKeySet)
+	assert.Error(t, err, "HMAC tokens are rejected unless explicitly accepted")
+
+	newKeySet.AcceptHMAC("secret")
+	_, err = ParseToken(hmacToken.Token, newKeySet)
+	assert.NoError(t, err)
+}
+
+func TestKeySet_KeyFuncRejects(t *testing.T) {
+	rsaKey := newRSAKey(t)
+	keySet, err := LoadKeySet(writePKCS8Key(t, rsaKey), nil)
+	require.NoError(t, err)
+	kid := keySet.JWKS().Keys[0].KeyID
+	claims := &Claims{Login: "alice", RegisteredClaims: jwt.RegisteredClaims{
+		ID: "token-id", ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+	}}
+
+	otherKeySet, err := LoadKeySet(writePKCS8Key(t, newRSAKey(t)), nil)
+	require.NoError(t, err)
+	unknownKID, err := CreateToken("alice", "token-id", nil, otherKeySet, time.Minute)
+	require.NoError(t, err)
+
+	edToken := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+	edToken.Header["kid"] = kid
+	algMismatch, err := edToken.SignedString(newEd25519Key(t))
+	require.NoError(t, err)
+
+	hsToken := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	hsToken.Header["kid"] = kid
+	publicKeyDER, err := x509.MarshalPKIXPublicKey(rsaKey.Public())
+	require.NoError(t, err)
+	hmacWithPublicKey, err := hsToken.SignedString(publicKeyDER)
+	require.NoError(t, err)
+
+	noneToken, err := jwt.NewWithClaims(jwt.SigningMethodNone, claims).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	require.NoError(t, err)
+
+	tests := map[string]string{
+		"unknown kid":              unknownKID.Token,
+		"alg does not match kid":   algMismatch,
+		"HMAC signed by RSA key":   hmacWithPublicKey,
+		"unsigned token":           noneToken,
+		"malformed token":          "not-a-token",
+		"HMAC without hmac secret": mustHMACToken(t),
+	}
+	for name, tokenString := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseToken(tokenString, keySet)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func mustHMACToken(t *testing.T) string {
+	t.Helper()
+	accessToken, err := CreateToken("alice", "token-id", nil, NewHMACKeySet(""), time.Minute)
+	require.NoError(t, err)
+	return accessToken.Token
+}
+
+func TestKeySet_JWKS(t *testing.T) {
+	rsaKey := newRSAKey(t)
+	edKey := newEd25519Key(t)
+	keySet, err := LoadKeySet(writePKCS8Key(t, rsaKey), []string{writePKCS8Key(t, edKey)})
+	require.NoError(t, err)
+
+	buf, err := json.Marshal(keySet.JWKS())
+	require.NoError(t, err)
+
+	var jwks struct {
+		Keys []map[string]string `json:"keys"`
+	}
+	require.NoError(t, json.Unmarshal(buf, &jwks))
+	require.Len(t, jwks.Keys, 2)
+
+	byType := make(map[string]map[string]string)
+	for _, key := range jwks.Keys {
+		byType[key["kty"]] = key
+		assert.Equal(t, "sig", key["use"])
+		assert.NotEmpty(t, key["kid"])
+		assert.NotContains(t, key, "d", "private key material must not be published")
+	}
+
+	rsaJWK := byType["RSA"]
+	require.NotNil(t, rsaJWK)
+	assert.Equal(t, "RS256", rsaJWK["alg"])
+	assert.Equal(t, "AQAB", rsaJWK["e"])
+	assert.NotEmpty(t, rsaJWK["n"])
+
+	edJWK := byType["OKP"]
+	require.NotNil(t, edJWK)
+	assert.Equal(t, "EdDSA", edJWK["alg"])
+	assert.Equal(t, "Ed25519", edJWK["crv"])
+	assert.NotEmpty(t, edJWK["x"])
+
+	expected, err := newJWK(edKey.Public(), "EdDSA")
+	require.NoError(t, err)
+	assert.Equal(t, expected.KeyID, edJWK["kid"])
+}
+
+func TestNewJWK_RFC7638Thumbprint(t *testing.T) {
+	n, err := base64.RawURLEncoding.DecodeString("0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw")
+	require.NoError(t, err)
+
+	jwk, err := newJWK(&rsa.PublicKey{N: new(big.Int).SetBytes(n), E: 65537}, "RS256")
+	require.NoError(t, err)
+	assert.Equal(t, "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs", jwk.KeyID)
+}
@@ -4,16 +4,11 @@ import (
 	"time"
 )
 
-func NewDelay() func() time.Duration {
-	attempt := 0
-	delay := 1 * time.Second
-	delayIncrease := 2 * time.Second
-	return func() time.Duration {
-		attempt++
-		if attempt == 1 {
-			return delay
-		}
-		delay += delayIncrease
-		return delay
+// Backoff doubles base for every failed attempt and never waits longer than limit.
+func Backoff(attempt uint, base, limit time.Duration) time.Duration {
+	wait := base
+	for i := uint(1); i < attempt && wait < limit; i++ {
+		wait *= 2
 	}
+	return min(wait, limit)
 }